@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/Azure/azure-storage-blob-go/2016-05-31/azblob"
 	"github.com/sirupsen/logrus"
 	"gitlab.scorum.com/blog/api/common"
+	"gitlab.scorum.com/blog/api/metrics"
 )
 
 const blobFormatString = `https://%s.blob.core.windows.net`
@@ -59,17 +61,62 @@ func (s *Service) UploadMedia(account string, ID string, content []byte, content
 	container := s.getContainerURL(s.config.Container)
 	blobUrl := container.NewBlockBlobURL(fmt.Sprintf("%s/%s", account, ID))
 
+	start := time.Now()
 	_, err := blobUrl.PutBlob(
 		context.Background(),
 		bytes.NewReader(content), azblob.BlobHTTPHeaders{
 			ContentType: string(contentType),
 		}, azblob.Metadata{}, azblob.BlobAccessConditions{})
 	if err != nil {
+		metrics.BlobUploadDuration.WithLabelValues("failed").Observe(metrics.Since(start))
 		return "", err
 	}
+	metrics.BlobUploadDuration.WithLabelValues("ok").Observe(metrics.Since(start))
 
+	return s.cdnURL(blobUrl), nil
+}
+
+// MediaURL returns url of the media content without uploading it
+func (s *Service) MediaURL(account string, ID string) string {
+	container := s.getContainerURL(s.config.Container)
+	return s.cdnURL(container.NewBlockBlobURL(fmt.Sprintf("%s/%s", account, ID)))
+}
+
+func (s *Service) cdnURL(blobUrl azblob.BlockBlobURL) string {
 	url := blobUrl.URL()
-	return strings.Replace((&url).String(), s.primaryUrl(), s.config.CDNDomain, 1), nil
+	return strings.Replace((&url).String(), s.primaryUrl(), s.config.CDNDomain, 1)
+}
+
+// ListMedia returns IDs of the account media contents including the generated ones, e.g. thumbnails
+func (s *Service) ListMedia(account string) ([]string, error) {
+	container := s.getContainerURL(s.config.Container)
+	prefix := account + "/"
+
+	var IDs []string
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		list, err := container.ListBlobs(context.Background(), marker, azblob.ListBlobsOptions{Prefix: prefix})
+		if err != nil {
+			return nil, err
+		}
+		marker = list.NextMarker
+
+		for _, blob := range list.Blobs.Blob {
+			IDs = append(IDs, strings.TrimPrefix(blob.Name, prefix))
+		}
+	}
+	return IDs, nil
+}
+
+// DeleteMedia deletes the media content, missing contents are ignored
+func (s *Service) DeleteMedia(account, ID string) error {
+	container := s.getContainerURL(s.config.Container)
+	blobUrl := container.NewBlockBlobURL(fmt.Sprintf("%s/%s", account, ID))
+
+	_, err := blobUrl.Delete(context.Background(), azblob.DeleteSnapshotsOptionInclude, azblob.BlobAccessConditions{})
+	if err != nil && !isBlobNotFound(err) {
+		return err
+	}
+	return nil
 }
 
 func (s *Service) DoesMediaExists(account, ID string) (bool, error) {
@@ -79,14 +126,19 @@ func (s *Service) DoesMediaExists(account, ID string) (bool, error) {
 	_, err := blobUrl.GetPropertiesAndMetadata(context.Background(), azblob.BlobAccessConditions{})
 
 	if err != nil {
-		if serr, ok := err.(azblob.StorageError); ok {
-			// there is no other way to identify BlobNotFound error
-			if strings.Contains(serr.Error(), "404 The specified blob does not exist.") {
-				return false, nil
-			}
+		if isBlobNotFound(err) {
+			return false, nil
 		}
 		return false, err
 	}
 
 	return true, nil
 }
+
+func isBlobNotFound(err error) bool {
+	if serr, ok := err.(azblob.StorageError); ok {
+		// there is no other way to identify BlobNotFound error
+		return strings.Contains(serr.Error(), "404 The specified blob does not exist.")
+	}
+	return false
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,10 +14,12 @@ import (
 	"github.com/pkg/errors"
 	"github.com/scorum/event-provider-go/event"
 	"github.com/scorum/event-provider-go/provider"
+	"github.com/scorum/scorum-go"
 	log "github.com/sirupsen/logrus"
 	"gitlab.scorum.com/blog/api/common"
 	"gitlab.scorum.com/blog/api/db"
 	"gitlab.scorum.com/blog/api/mailer"
+	"gitlab.scorum.com/blog/api/metrics"
 	"gitlab.scorum.com/blog/api/push"
 	"gitlab.scorum.com/blog/api/service"
 	. "gitlab.scorum.com/blog/core/domain"
@@ -31,16 +34,29 @@ var types = []event.Type{
 	event.DeleteCommentEventType,
 }
 
+// lagCheckInterval is an interval of the head block polling for the lag metrics
+const lagCheckInterval = 30 * time.Second
+
 type BlockchainMonitor struct {
-	DB                  *sqlx.DB
-	CommentsStorage     *db.CommentsStorage
-	Provider            *provider.Provider
-	Plagiarism          *service.AntiPlagiarism
-	PushNotifier        push.Notifier
-	NotificationStorage *db.NotificationStorage
-	DownvotesStorage    *db.DownvotesStorage
-	PlagiarismStorage   *db.PlagiarismStorage
-	MailerClient        *mailer.Client
+	DB                        *sqlx.DB
+	Blockchain                *scorumgo.Client
+	CommentsStorage           *db.CommentsStorage
+	Provider                  *provider.Provider
+	Plagiarism                *service.AntiPlagiarism
+	PushNotifier              push.Notifier
+	NotificationStorage       *db.NotificationStorage
+	DownvotesStorage          *db.DownvotesStorage
+	PlagiarismStorage         *db.PlagiarismStorage
+	ModerationStorage         *db.ModerationStorage
+	AccountListsStorage       *db.AccountListsStorage
+	TopicSubscriptionsStorage *db.TopicSubscriptionsStorage
+	MailerClient              *mailer.Client
+
+	// UniquenessThreshold opens a moderation case for the checked posts with the lower uniqueness, 0 disables the check
+	UniquenessThreshold float32
+
+	// block number of the last processed event, accessed atomically
+	processedBlockNum uint32
 }
 
 func (bm *BlockchainMonitor) Monitor(ctx context.Context) {
@@ -51,6 +67,11 @@ func (bm *BlockchainMonitor) Monitor(ctx context.Context) {
 
 	log.WithField("last_checked_block_num", headBlockNum).Info("getting events")
 
+	bm.setProcessedBlockNum(headBlockNum)
+	if bm.Blockchain != nil {
+		go bm.trackLag(ctx)
+	}
+
 	bm.Provider.Provide(ctx, headBlockNum, types, func(ev event.Event, err error) {
 		if err != nil {
 			log.WithError(err).Error("blockchain monitor: MONITOR STOPPED")
@@ -81,10 +102,39 @@ func (bm *BlockchainMonitor) Monitor(ctx context.Context) {
 				return
 			}
 			headBlockNum = ev.Common().BlockNum
+			bm.setProcessedBlockNum(headBlockNum)
 		}
 	})
 }
 
+func (bm *BlockchainMonitor) setProcessedBlockNum(blockNum uint32) {
+	atomic.StoreUint32(&bm.processedBlockNum, blockNum)
+	metrics.MonitorProcessedBlockNum.Set(float64(blockNum))
+}
+
+// trackLag periodically updates the head block number and the lag metrics
+func (bm *BlockchainMonitor) trackLag(ctx context.Context) {
+	ticker := time.NewTicker(lagCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			props, err := bm.Blockchain.Database.GetDynamicGlobalProperties()
+			if err != nil {
+				log.WithError(err).Warn("blockchain monitor: failed to get head block")
+				continue
+			}
+
+			processed := atomic.LoadUint32(&bm.processedBlockNum)
+			metrics.MonitorHeadBlockNum.Set(float64(props.HeadBlockNumber))
+			metrics.MonitorLag.Set(float64(props.HeadBlockNumber) - float64(processed))
+		}
+	}
+}
+
 func getEventLogger(ev event.Event) *log.Entry {
 	return log.WithField("block_num", ev.Common().BlockNum).
 		WithField("event", ev.Type())
@@ -236,7 +286,14 @@ func (bm *BlockchainMonitor) processPost(ev event.PostEvent, tx *sqlx.Tx) error
 		// TODO: if not valid domain mark post/comment as suspicious
 	}
 
-	_, err := tx.NamedExec(
+	var existed bool
+	err := tx.Get(&existed, `SELECT EXISTS(SELECT * FROM comments WHERE author = $1 AND permlink = $2)`,
+		comment.Author, comment.Permlink)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.NamedExec(
 		`INSERT INTO comments (permlink, author, body, title, json_metadata, parent_permlink, domain, updated_at, created_at)
 				VALUES(:permlink, :author, :body, :title, :json_metadata, :parent_permlink, :domain, :updated_at, :created_at)
 		        ON CONFLICT(author, permlink)
@@ -275,6 +332,12 @@ func (bm *BlockchainMonitor) processPost(ev event.PostEvent, tx *sqlx.Tx) error
 		}
 	}
 
+	if !existed {
+		if err := bm.notifyCategorySubscribers(comment, domain, tx); err != nil {
+			return err
+		}
+	}
+
 	initialPostPlagiarism := db.PostPlagiarism{ // all new posts should be in db with 100% uniqueness
 		Author:            comment.Author,
 		Permlink:          comment.Permlink,
@@ -295,6 +358,44 @@ func (bm *BlockchainMonitor) processPost(ev event.PostEvent, tx *sqlx.Tx) error
 	return nil
 }
 
+// notifyCategorySubscribers notifies the accounts subscribed to the categories of the new post
+func (bm *BlockchainMonitor) notifyCategorySubscribers(post db.Comment, domain Domain, tx *sqlx.Tx) error {
+	if len(post.JsonMetadata.Categories) == 0 {
+		return nil
+	}
+
+	subscribers, err := bm.TopicSubscriptionsStorage.InTx(tx).NotifiedSubscribers(
+		string(domain), post.JsonMetadata.Categories, post.Author)
+	if err != nil {
+		return err
+	}
+
+	meta := db.PostRelatedNotificationMeta{
+		Account:      post.Author,
+		Permlink:     post.Permlink,
+		PostAuthor:   post.Author,
+		PostCategory: post.JsonMetadata.Categories[0],
+		PostImage:    post.JsonMetadata.Image,
+		PostTitle:    post.Title,
+		Domains:      post.JsonMetadata.Domains,
+	}
+
+	for _, account := range subscribers {
+		notification := db.Notification{
+			ID:        uuid.New(),
+			Account:   account,
+			Timestamp: post.CreatedAt,
+			Type:      db.CategoryPostedNotificationType,
+			Meta:      meta.ToJson(),
+		}
+		if err := bm.NotificationStorage.InTx(tx).Insert(notification); err != nil {
+			return errors.Wrapf(err, "failed to insert category notification %s/%s", post.Author, post.Permlink)
+		}
+	}
+
+	return nil
+}
+
 func (bm *BlockchainMonitor) createNotificationFromComment(comment db.Comment, tx *sqlx.Tx) error {
 	parentPostInfo, err := bm.CommentsStorage.InTx(tx).GetParentPost(comment.Author, comment.Permlink)
 	if err != nil {
@@ -325,6 +426,13 @@ func (bm *BlockchainMonitor) createNotificationFromComment(comment db.Comment, t
 		return nil
 	}
 
+	// replies of the muted and blocked accounts are not notified
+	ignored, err := bm.AccountListsStorage.InTx(tx).Contains(parentCommentAuthor, comment.Author,
+		db.MutedList, db.BlockedList)
+	if err != nil || ignored {
+		return err
+	}
+
 	meta := db.PostRelatedNotificationMeta{
 		Account:      comment.Author,
 		Permlink:     parentPostInfo.Permlink,
@@ -383,6 +491,11 @@ func (bm *BlockchainMonitor) processFlag(flagEvent event.FlagEvent, tx *sqlx.Tx)
 		return nil
 	}
 
+	blocked, err := bm.AccountListsStorage.InTx(tx).Contains(comment.Author, flagEvent.Voter, db.BlockedList)
+	if err != nil || blocked {
+		return err
+	}
+
 	meta := db.PostRelatedNotificationMeta{
 		Account:      flagEvent.Voter,
 		Permlink:     parentPostInfo.Permlink,
@@ -468,6 +581,11 @@ func (bm *BlockchainMonitor) processVote(voteEvent event.VoteEvent, tx *sqlx.Tx)
 		}
 	}
 
+	blocked, err := bm.AccountListsStorage.InTx(tx).Contains(comment.Author, voteEvent.Voter, db.BlockedList)
+	if err != nil || blocked {
+		return err
+	}
+
 	meta := db.PostRelatedNotificationMeta{
 		Account:      voteEvent.Voter,
 		Permlink:     parentPostInfo.Permlink,
@@ -581,6 +699,20 @@ func (bm *BlockchainMonitor) checkPlagiarismAndNotify(c db.Comment, d Domain) {
 		return
 	}
 
+	if checkDetails.Unique < bm.UniquenessThreshold {
+		_, err = bm.ModerationStorage.Open(db.ModerationCase{
+			Author:   c.Author,
+			Permlink: c.Permlink,
+			Domain:   string(d),
+			Source:   db.ModerationSourcePlagiarism,
+			Reason:   string(db.DownvoteReasonPlagiarism),
+			OpenedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			logger.Warnf("can't open plagiarism moderation case err: %s", err)
+		}
+	}
+
 	err = bm.MailerClient.SendPlagiarismEmail(meta)
 	if err != nil {
 		logger.Warnf("can't send notification to mailer err: %s", err)
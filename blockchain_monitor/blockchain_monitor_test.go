@@ -48,6 +48,8 @@ func cleanUp(t *testing.T) {
 	require.NoError(t, err)
 	_, err = dbWrite.Exec("DELETE FROM posts_plagiarism")
 	require.NoError(t, err)
+	_, err = dbWrite.Exec("DELETE FROM topic_subscriptions")
+	require.NoError(t, err)
 	_, err = dbWrite.Exec("DELETE FROM profiles")
 	require.NoError(t, err)
 }
@@ -75,6 +77,15 @@ func TestProcessPost(t *testing.T) {
 		kassie, kassie)
 	require.NoError(t, err)
 
+	require.NoError(t, db.NewTopicSubscriptionsStorage(dbWrite).Subscribe(db.TopicSubscription{
+		Account:   kassie,
+		Domain:    string(DomainCom),
+		Kind:      db.CategoryTopic,
+		Topic:     "categories-pi",
+		Notify:    true,
+		CreatedAt: time.Now(),
+	}))
+
 	tx, err := dbWrite.Beginx()
 	require.NoError(t, err)
 
@@ -85,14 +96,16 @@ func TestProcessPost(t *testing.T) {
 	notifierMock.EXPECT().NotifyPostVoted(gomock.Any()).Times(1)
 
 	bm := &BlockchainMonitor{
-		DB:                  dbWrite,
-		Plagiarism:          createAntiPlagiarismService(),
-		PushNotifier:        notifierMock,
-		NotificationStorage: db.NewNotificationsStorage(dbWrite),
-		DownvotesStorage:    db.NewDownvotesStorage(dbWrite),
-		CommentsStorage:     db.NewCommentsStorage(dbWrite),
-		PlagiarismStorage:   db.NewPlagiarismStorage(dbWrite),
-		MailerClient:        &mailer.Client{},
+		DB:                        dbWrite,
+		Plagiarism:                createAntiPlagiarismService(),
+		PushNotifier:              notifierMock,
+		NotificationStorage:       db.NewNotificationsStorage(dbWrite),
+		AccountListsStorage:       db.NewAccountListsStorage(dbWrite),
+		TopicSubscriptionsStorage: db.NewTopicSubscriptionsStorage(dbWrite),
+		DownvotesStorage:          db.NewDownvotesStorage(dbWrite),
+		CommentsStorage:           db.NewCommentsStorage(dbWrite),
+		PlagiarismStorage:         db.NewPlagiarismStorage(dbWrite),
+		MailerClient:              &mailer.Client{},
 	}
 
 	metadata := common.JsonMetadata{
@@ -141,6 +154,12 @@ func TestProcessPost(t *testing.T) {
 	var domain string
 	require.NoError(t, dbWrite.Get(&domain, `SELECT domain FROM comments WHERE author = $1 AND permlink = $2`, leonarda, permlink))
 	require.Equal(t, string(DomainCom), domain)
+
+	// kassie is subscribed to the category of the post
+	notifications, err := db.NewNotificationsStorage(dbWrite).GetNotifications(kassie, 10)
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	require.Equal(t, db.CategoryPostedNotificationType, notifications[0].Type)
 }
 
 func TestProcessComment(t *testing.T) {
@@ -167,13 +186,15 @@ func TestProcessComment(t *testing.T) {
 	)
 
 	bm := &BlockchainMonitor{
-		DB:                  dbWrite,
-		Plagiarism:          createAntiPlagiarismService(),
-		PushNotifier:        notifierMock,
-		CommentsStorage:     db.NewCommentsStorage(dbWrite),
-		NotificationStorage: db.NewNotificationsStorage(dbWrite),
-		PlagiarismStorage:   db.NewPlagiarismStorage(dbWrite),
-		MailerClient:        &mailer.Client{},
+		DB:                        dbWrite,
+		Plagiarism:                createAntiPlagiarismService(),
+		PushNotifier:              notifierMock,
+		CommentsStorage:           db.NewCommentsStorage(dbWrite),
+		NotificationStorage:       db.NewNotificationsStorage(dbWrite),
+		AccountListsStorage:       db.NewAccountListsStorage(dbWrite),
+		TopicSubscriptionsStorage: db.NewTopicSubscriptionsStorage(dbWrite),
+		PlagiarismStorage:         db.NewPlagiarismStorage(dbWrite),
+		MailerClient:              &mailer.Client{},
 	}
 
 	metadata := common.JsonMetadata{
@@ -267,13 +288,15 @@ func TestSaveVote(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	bm := &BlockchainMonitor{
-		DB:                  dbWrite,
-		Plagiarism:          createAntiPlagiarismService(),
-		PushNotifier:        push.NewMockNotifier(mockCtrl),
-		NotificationStorage: db.NewNotificationsStorage(dbWrite),
-		DownvotesStorage:    db.NewDownvotesStorage(dbWrite),
-		PlagiarismStorage:   db.NewPlagiarismStorage(dbWrite),
-		MailerClient:        &mailer.Client{},
+		DB:                        dbWrite,
+		Plagiarism:                createAntiPlagiarismService(),
+		PushNotifier:              push.NewMockNotifier(mockCtrl),
+		NotificationStorage:       db.NewNotificationsStorage(dbWrite),
+		AccountListsStorage:       db.NewAccountListsStorage(dbWrite),
+		TopicSubscriptionsStorage: db.NewTopicSubscriptionsStorage(dbWrite),
+		DownvotesStorage:          db.NewDownvotesStorage(dbWrite),
+		PlagiarismStorage:         db.NewPlagiarismStorage(dbWrite),
+		MailerClient:              &mailer.Client{},
 	}
 
 	ve := event.VoteEvent{
@@ -310,12 +333,14 @@ func TestDeletedPost(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	bm := &BlockchainMonitor{
-		DB:                  dbWrite,
-		Plagiarism:          createAntiPlagiarismService(),
-		PushNotifier:        push.NewMockNotifier(mockCtrl),
-		NotificationStorage: db.NewNotificationsStorage(dbWrite),
-		PlagiarismStorage:   db.NewPlagiarismStorage(dbWrite),
-		MailerClient:        &mailer.Client{},
+		DB:                        dbWrite,
+		Plagiarism:                createAntiPlagiarismService(),
+		PushNotifier:              push.NewMockNotifier(mockCtrl),
+		NotificationStorage:       db.NewNotificationsStorage(dbWrite),
+		AccountListsStorage:       db.NewAccountListsStorage(dbWrite),
+		TopicSubscriptionsStorage: db.NewTopicSubscriptionsStorage(dbWrite),
+		PlagiarismStorage:         db.NewPlagiarismStorage(dbWrite),
+		MailerClient:              &mailer.Client{},
 	}
 
 	err = bm.processPost(event.PostEvent{
@@ -359,11 +384,13 @@ func TestCheckPlagiarismAndNotify(t *testing.T) {
 	require.NoError(t, err)
 
 	bm := &BlockchainMonitor{
-		DB:                  dbWrite,
-		Plagiarism:          createAntiPlagiarismService(),
-		NotificationStorage: db.NewNotificationsStorage(dbWrite),
-		PlagiarismStorage:   db.NewPlagiarismStorage(dbWrite),
-		MailerClient:        &mailer.Client{},
+		DB:                        dbWrite,
+		Plagiarism:                createAntiPlagiarismService(),
+		NotificationStorage:       db.NewNotificationsStorage(dbWrite),
+		AccountListsStorage:       db.NewAccountListsStorage(dbWrite),
+		TopicSubscriptionsStorage: db.NewTopicSubscriptionsStorage(dbWrite),
+		PlagiarismStorage:         db.NewPlagiarismStorage(dbWrite),
+		MailerClient:              &mailer.Client{},
 	}
 
 	c := db.Comment{
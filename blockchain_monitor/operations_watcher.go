@@ -0,0 +1,138 @@
+package blockchain_monitor
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/scorum/scorum-go"
+	"github.com/scorum/scorum-go/apis/blockchain_history"
+	scorumtype "github.com/scorum/scorum-go/types"
+	log "github.com/sirupsen/logrus"
+	"gitlab.scorum.com/blog/api/rpc"
+)
+
+// OperationHandler handles the blockchain operation of the given block
+type OperationHandler func(blockNum uint32, op scorumtype.Operation)
+
+// OperationsWatcher polls the new blocks of the blockchain and passes their operations to the handlers.
+// It starts from the head block and keeps no state, so unlike BlockchainMonitor it is meant
+// to run on every api instance to maintain the instance local state e.g. caches
+type OperationsWatcher struct {
+	Blockchain *scorumgo.Client
+	Interval   time.Duration
+
+	handlers map[scorumtype.OpType][]OperationHandler
+}
+
+// Handle registers handler of the given operation type
+func (w *OperationsWatcher) Handle(opType scorumtype.OpType, handler OperationHandler) {
+	if w.handlers == nil {
+		w.handlers = make(map[scorumtype.OpType][]OperationHandler)
+	}
+	w.handlers[opType] = append(w.handlers[opType], handler)
+}
+
+// Watch polls the blockchain until the context is done
+func (w *OperationsWatcher) Watch(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	var lastBlockNum uint32
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			props, err := w.Blockchain.Database.GetDynamicGlobalProperties()
+			if err != nil {
+				log.WithError(err).Warn("operations watcher: failed to get head block")
+				continue
+			}
+
+			headBlockNum := props.HeadBlockNumber
+			if lastBlockNum == 0 {
+				lastBlockNum = headBlockNum
+				continue
+			}
+
+			for blockNum := lastBlockNum + 1; blockNum <= headBlockNum; blockNum++ {
+				if err := w.processBlock(blockNum); err != nil {
+					log.WithError(err).WithField("block_num", blockNum).
+						Warn("operations watcher: failed to get block operations")
+					break
+				}
+				lastBlockNum = blockNum
+			}
+		}
+	}
+}
+
+func (w *OperationsWatcher) processBlock(blockNum uint32) error {
+	history, err := w.Blockchain.BlockchainHistory.GetOperationsInBlock(blockNum, blockchain_history.NotVirtualOp)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range history {
+		for _, op := range obj.Operations {
+			for _, handler := range w.handlers[op.Type()] {
+				handler(blockNum, op)
+			}
+		}
+	}
+	return nil
+}
+
+// AuthorityOpTypes are the operations creating the accounts or changing their authorities
+var AuthorityOpTypes = []scorumtype.OpType{
+	scorumtype.AccountCreateOpType,
+	scorumtype.AccountCreateWithDelegationOpType,
+	scorumtype.AccountCreateByCommitteeOpType,
+	scorumtype.AccountUpdateOpType,
+	scorumtype.RecoverAccount,
+}
+
+// InvalidateAuthorities returns the handler removing the accounts with updated authorities from the cache,
+// the created accounts are removed to drop their negative cache entries
+func InvalidateAuthorities(cache *rpc.KeyCache) OperationHandler {
+	return func(blockNum uint32, op scorumtype.Operation) {
+		account := authorityAccount(op)
+		if account == "" {
+			return
+		}
+
+		log.WithField("block_num", blockNum).
+			WithField("account", account).
+			WithField("op", op.Type()).
+			Debug("operations watcher: account authorities updated")
+		cache.Invalidate(account)
+	}
+}
+
+// authorityAccount returns the account created or with the authorities changed by the operation
+func authorityAccount(op scorumtype.Operation) string {
+	switch o := op.(type) {
+	case *scorumtype.AccountCreateOperation:
+		return o.NewAccountName
+	case *scorumtype.AccountCreateWithDelegationOperation:
+		return o.NewAccountName
+	case *scorumtype.AccountCreateByCommitteeOperation:
+		return o.NewAccountName
+	case *scorumtype.AccountUpdateOperation:
+		return o.Account
+	case *scorumtype.UnknownOperation:
+		if o.Type() != scorumtype.RecoverAccount {
+			return ""
+		}
+		var recovery struct {
+			AccountToRecover string `json:"account_to_recover"`
+		}
+		if err := json.Unmarshal(o.Data, &recovery); err != nil {
+			log.WithError(err).Warn("operations watcher: failed to unmarshal recover_account")
+			return ""
+		}
+		return recovery.AccountToRecover
+	}
+	return ""
+}
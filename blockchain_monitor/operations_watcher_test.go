@@ -0,0 +1,26 @@
+package blockchain_monitor
+
+import (
+	"encoding/json"
+	"testing"
+
+	scorumtype "github.com/scorum/scorum-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorityAccount(t *testing.T) {
+	var ops scorumtype.OperationsFlat
+	require.NoError(t, json.Unmarshal([]byte(`[
+		"account_create", {"creator": "scorum", "new_account_name": "leonarda"},
+		"account_create_by_committee", {"creator": "scorum", "new_account_name": "kristie"},
+		"account_update", {"account": "sheldon"},
+		"recover_account", {"account_to_recover": "kassie"},
+		"vote", {"voter": "leonarda", "author": "kristie", "permlink": "post", "weight": 100}
+	]`), &ops))
+
+	accounts := make([]string, len(ops))
+	for idx, op := range ops {
+		accounts[idx] = authorityAccount(op)
+	}
+	require.Equal(t, []string{"leonarda", "kristie", "sheldon", "kassie", ""}, accounts)
+}
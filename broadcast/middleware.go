@@ -0,0 +1,38 @@
+package broadcast
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/rpc"
+)
+
+// chain wraps the handler with the middlewares, the first middleware is the outermost
+func chain(handler TransactionHandler, middlewares []Middleware) TransactionHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Logging logs the processed operation with the elapsed time
+func Logging(next TransactionHandler) TransactionHandler {
+	return func(tx *Tx, op types.Operation) *rpc.Error {
+		start := time.Now()
+		err := next(tx, op)
+
+		entry := log.WithFields(log.Fields{
+			"elapsed": time.Since(start),
+			"op":      op.Type(),
+			"account": op.GetAccount(),
+		})
+		if err != nil {
+			entry.WithField("code", err.Code).Debugf("operation rejected: %s", err.Message)
+		} else {
+			entry.Debug("operation processed")
+		}
+
+		return err
+	}
+}
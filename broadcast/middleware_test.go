@@ -0,0 +1,38 @@
+package broadcast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/rpc"
+)
+
+func TestTransactionRouter_Middlewares(t *testing.T) {
+	router := NewTransactionRouter(nil, nil, nil)
+
+	var calls []string
+	middleware := func(name string) Middleware {
+		return func(next TransactionHandler) TransactionHandler {
+			return func(tx *Tx, op types.Operation) *rpc.Error {
+				calls = append(calls, name)
+				return next(tx, op)
+			}
+		}
+	}
+
+	router.Use(middleware("router"), Logging)
+	router.Register(types.FollowOpType, func(_ *Tx, op types.Operation) *rpc.Error {
+		calls = append(calls, "handler")
+		return &rpc.Error{Code: rpc.AccessDeniedCode, Message: "denied"}
+	}, middleware("op"))
+
+	err := chain(router.routes[types.FollowOpType], router.middlewares)(nil, &types.FollowOperation{
+		Account: "leonarda",
+		Follow:  "kristie",
+	})
+
+	require.Equal(t, []string{"router", "op", "handler"}, calls)
+	require.NotNil(t, err)
+	require.Equal(t, rpc.AccessDeniedCode, err.Code)
+}
@@ -1,25 +1,58 @@
 package broadcast
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"strconv"
+	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 	"github.com/scorum/scorum-go"
 	"github.com/scorum/scorum-go/apis/network_broadcast"
+	"github.com/scorum/scorum-go/sign"
 	log "github.com/sirupsen/logrus"
 	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/metrics"
 	"gitlab.scorum.com/blog/api/rpc"
 	"gopkg.in/go-playground/validator.v9"
 )
 
-type TransactionHandler func(op types.Operation) *rpc.Error
+// operation statuses of the broadcast metrics
+const (
+	statusAccepted = "accepted"
+	statusRejected = "rejected"
+)
+
+// DefaultMaxExpiration is the maximum time until the transaction expiration accepted by the blockchain
+const DefaultMaxExpiration = time.Hour
+
+// TransactionHandler applies the operation within the given database transaction
+type TransactionHandler func(tx *Tx, op types.Operation) *rpc.Error
+
+// Middleware wraps the transaction handler to run the code before and after it
+type Middleware func(next TransactionHandler) TransactionHandler
 
 // TransactionRouter routes transaction requests to the corresponding handler
 type TransactionRouter struct {
-	Blockchain *scorumgo.Client
-	Verifier   rpc.Verifier
-	routes     map[types.OpType]TransactionHandler
+	DB          *sqlx.DB
+	Blockchain  *scorumgo.Client
+	Verifier    rpc.Verifier
+	Authorities rpc.AuthorityProvider
+	// Transactions keeps accepted transactions to reject duplicates, disabled if nil
+	Transactions *db.TransactionStorage
+	// Audit records the operations of the verified transactions, disabled if nil
+	Audit *db.AuditStorage
+	// MaxExpiration is the maximum time until the transaction expiration
+	MaxExpiration time.Duration
+	// CheckRefBlock enables validation of the transaction reference block against the blockchain
+	CheckRefBlock bool
+
+	routes      map[types.OpType]TransactionHandler
+	middlewares []Middleware
+	now         func() time.Time
 }
 
 var validate *validator.Validate
@@ -29,21 +62,42 @@ func init() {
 }
 
 // NewTransactionRouter creates new TransactionRouter
-func NewTransactionRouter(blockchain *scorumgo.Client, verifier rpc.Verifier) *TransactionRouter {
+func NewTransactionRouter(db *sqlx.DB, blockchain *scorumgo.Client, verifier rpc.Verifier) *TransactionRouter {
 	return &TransactionRouter{
-		Blockchain: blockchain,
-		Verifier:   verifier,
-		routes:     make(map[types.OpType]TransactionHandler),
+		DB:            db,
+		Blockchain:    blockchain,
+		Verifier:      verifier,
+		Authorities:   rpc.NewBlockchainAuthorityProvider(blockchain),
+		MaxExpiration: DefaultMaxExpiration,
+		routes:        make(map[types.OpType]TransactionHandler),
+		now:           time.Now,
 	}
 }
 
-// Register handler of the given operation type
-func (router *TransactionRouter) Register(op types.OpType, trxHandler TransactionHandler) {
-	router.routes[op] = trxHandler
+// Use adds middlewares applied to every operation handler
+func (router *TransactionRouter) Use(middlewares ...Middleware) {
+	router.middlewares = append(router.middlewares, middlewares...)
 }
 
-// Route validates and routes the given transaction to the corresponding handler
+// Register handler of the given operation type,
+// the operation middlewares are applied after the router ones
+func (router *TransactionRouter) Register(op types.OpType, trxHandler TransactionHandler, middlewares ...Middleware) {
+	router.routes[op] = chain(trxHandler, middlewares)
+}
+
+// Route validates and routes the operations of the given transaction to the corresponding handlers,
+// the operations are applied atomically within one database transaction
 func (router *TransactionRouter) Route(ctx *rpc.Context) {
+	router.route(ctx, false)
+}
+
+// ValidateTransaction runs the Route pipeline including the handlers within the database transaction
+// which is always rolled back, it writes either the would-be result or the error without changing anything
+func (router *TransactionRouter) ValidateTransaction(ctx *rpc.Context) {
+	router.route(ctx, true)
+}
+
+func (router *TransactionRouter) route(ctx *rpc.Context, dryRun bool) {
 	var trx types.Transaction
 
 	if err := ctx.Param(0, &trx); err != nil {
@@ -57,25 +111,51 @@ func (router *TransactionRouter) Route(ctx *rpc.Context) {
 	}
 
 	ops := trx.Operations
-	if len(ops) != 1 {
-		ctx.WriteError(rpc.InvalidParameterCode, "only one operation per transaction supported")
+	if len(ops) == 0 {
+		ctx.WriteError(rpc.InvalidParameterCode, "transaction has no operations")
 		return
 	}
 
-	op := ops[0]
+	for i, op := range ops {
+		if _, ok := op.(*types.UnknownOperation); ok {
+			if !dryRun {
+				metrics.BroadcastOperations.WithLabelValues("unknown", statusRejected).Inc()
+			}
+			ctx.WriteError(rpc.InvalidParameterCode, operationError(i, op, "operation is unknown"))
+			return
+		}
+	}
+
+	status := statusRejected
+	defer func() {
+		if dryRun {
+			return
+		}
+		for _, op := range ops {
+			metrics.BroadcastOperations.WithLabelValues(string(op.Type()), status).Inc()
+		}
+	}()
 
-	if _, ok := op.(*types.UnknownOperation); ok {
-		ctx.WriteError(rpc.InvalidParameterCode, fmt.Sprintf("%s operation is unknown", op.Type()))
+	if err := router.checkExpiration(&trx); err != nil {
+		ctx.WriteError(err.Code, err.Message)
 		return
 	}
 
-	keys, err := rpc.GetSignPubKeys(router.Blockchain, op.GetAccount())
-	if err != nil {
-		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
-		return
+	if router.CheckRefBlock {
+		if err := router.checkRefBlock(&trx); err != nil {
+			ctx.WriteError(err.Code, err.Message)
+			return
+		}
 	}
 
-	valid, err := router.Verifier.VerifyTransaction(&trx, keys)
+	for _, op := range ops {
+		if _, err := router.Authorities.GetAuthorities(op.GetAccount()); err != nil {
+			ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+			return
+		}
+	}
+
+	valid, err := router.Verifier.VerifyTransaction(&trx, router.Authorities)
 	if err != nil {
 		ctx.WriteError(rpc.InvalidRequestCode, err.Error())
 		return
@@ -86,27 +166,213 @@ func (router *TransactionRouter) Route(ctx *rpc.Context) {
 		return
 	}
 
-	handler, ok := router.routes[op.Type()]
-	if !ok {
-		log.Fatalf("%s handler is not registered", op.Type())
+	for i, op := range ops {
+		if _, ok := router.routes[op.Type()]; !ok {
+			log.Fatalf("%s handler is not registered", op.Type())
+		}
+
+		if err := validate.Struct(op); err != nil {
+			router.auditRejected(&trx, rpc.InvalidParameterCode, dryRun)
+			ctx.WriteError(rpc.InvalidParameterCode, operationError(i, op, fmt.Sprintf("invalid request: %s", err)))
+			return
+		}
 	}
 
-	if err := validate.Struct(op); err != nil {
-		ctx.WriteError(rpc.InvalidParameterCode, fmt.Sprintf("invalid request: %s", err))
+	id, err := trx.ID()
+	if err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
 		return
 	}
 
-	// invoke operation handler
-	if err := handler(op); err != nil {
+	// invoke operation handlers
+	if err := router.apply(&trx, dryRun); err != nil {
+		router.auditRejected(&trx, err.Code, dryRun)
 		ctx.WriteError(err.Code, err.Message)
 		return
 	}
 
+	status = statusAccepted
+
 	// write broadcast ok
 	ctx.WriteResult(network_broadcast.BroadcastResponse{
-		ID:       strconv.FormatUint(ctx.ID, 10),
+		ID:       id,
 		Expired:  false,
 		BlockNum: 0,
 		TrxNum:   0,
 	})
 }
+
+// checkExpiration rejects expired transactions and the ones expiring too far in the future
+func (router *TransactionRouter) checkExpiration(trx *types.Transaction) *rpc.Error {
+	now := router.now()
+	expiration := *trx.Expiration.Time
+
+	if !expiration.After(now) {
+		return &rpc.Error{Code: rpc.ExpiredTransactionCode, Message: "transaction is expired"}
+	}
+
+	if expiration.After(now.Add(router.MaxExpiration)) {
+		return &rpc.Error{
+			Code:    rpc.InvalidParameterCode,
+			Message: fmt.Sprintf("transaction expiration is more than %s in the future", router.MaxExpiration),
+		}
+	}
+	return nil
+}
+
+// checkRefBlock verifies that the transaction references one of the last 0x10000 blocks of the blockchain
+func (router *TransactionRouter) checkRefBlock(trx *types.Transaction) *rpc.Error {
+	props, err := router.Blockchain.Database.GetDynamicGlobalProperties()
+	if err != nil {
+		return &rpc.Error{Code: rpc.InternalErrorCode, Message: err.Error()}
+	}
+
+	// ref_block_num keeps the lower 16 bits of the block number
+	blockNum := props.HeadBlockNumber&^0xFFFF | uint32(trx.RefBlockNum)
+	if blockNum > props.HeadBlockNumber {
+		if blockNum < 0x10000 {
+			return &rpc.Error{Code: rpc.InvalidParameterCode, Message: "reference block is invalid"}
+		}
+		blockNum -= 0x10000
+	}
+
+	block, err := router.Blockchain.BlockchainHistory.GetBlock(blockNum)
+	if err != nil {
+		return &rpc.Error{Code: rpc.InternalErrorCode, Message: err.Error()}
+	}
+
+	prefix, err := sign.RefBlockPrefix(block.BlockID)
+	if err != nil || prefix != trx.RefBlockPrefix {
+		return &rpc.Error{Code: rpc.InvalidParameterCode, Message: "reference block is invalid"}
+	}
+	return nil
+}
+
+// apply invokes handlers of the transaction operations within one database transaction,
+// the transaction is committed only if all the handlers succeed and it is not a dry run
+func (router *TransactionRouter) apply(trx *types.Transaction, dryRun bool) *rpc.Error {
+	dbTx, err := router.DB.Beginx()
+	if err != nil {
+		return &rpc.Error{Code: rpc.InternalErrorCode, Message: err.Error()}
+	}
+	tx := NewTx(dbTx, dryRun)
+
+	// a panicking handler must not leak the connection and the side effects of the transaction
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if router.Transactions != nil {
+		if err := router.saveTransaction(tx.Tx, trx); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	for i, op := range trx.Operations {
+		if err := chain(router.routes[op.Type()], router.middlewares)(tx, op); err != nil {
+			tx.Rollback()
+			return &rpc.Error{Code: err.Code, Message: operationError(i, op, err.Message)}
+		}
+	}
+
+	if router.Audit != nil {
+		if err := router.audit(router.Audit.InTx(tx.Tx), trx, 0); err != nil {
+			tx.Rollback()
+			return &rpc.Error{Code: rpc.InternalErrorCode, Message: err.Error()}
+		}
+	}
+
+	if dryRun {
+		tx.Rollback()
+		return nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return &rpc.Error{Code: rpc.InternalErrorCode, Message: err.Error()}
+	}
+	return nil
+}
+
+// saveTransaction saves digest of the transaction until it expires, the transaction is rejected if it has been already accepted
+func (router *TransactionRouter) saveTransaction(tx *sqlx.Tx, trx *types.Transaction) *rpc.Error {
+	digest, err := router.Verifier.TransactionDigest(trx)
+	if err != nil {
+		return &rpc.Error{Code: rpc.InternalErrorCode, Message: err.Error()}
+	}
+
+	ok, err := router.Transactions.InTx(tx).Add(digest, *trx.Expiration.Time)
+	if err != nil {
+		return &rpc.Error{Code: rpc.InternalErrorCode, Message: err.Error()}
+	}
+
+	if !ok {
+		return &rpc.Error{Code: rpc.DuplicateTransactionCode, Message: "transaction has been already accepted"}
+	}
+	return nil
+}
+
+// audit records the operations of the transaction with the result code
+func (router *TransactionRouter) audit(storage *db.AuditStorage, trx *types.Transaction, code int) error {
+	id, err := trx.ID()
+	if err != nil {
+		return err
+	}
+
+	digest, err := router.Verifier.TransactionDigest(trx)
+	if err != nil {
+		return err
+	}
+
+	now := router.now().UTC()
+	entries := make([]db.AuditEntry, len(trx.Operations))
+	for i, op := range trx.Operations {
+		payload, err := auditPayload(op)
+		if err != nil {
+			return err
+		}
+
+		entries[i] = db.AuditEntry{
+			Account:    op.GetAccount(),
+			OpType:     string(op.Type()),
+			Payload:    payload,
+			TrxID:      id,
+			Digest:     hex.EncodeToString(digest),
+			Signatures: trx.Signatures,
+			Code:       code,
+			CreatedAt:  now,
+		}
+	}
+
+	return storage.Add(entries...)
+}
+
+// auditPayload marshals the operation for the audit, the uploaded media content is replaced
+// by its hash and length to keep the audit rows small
+func auditPayload(op types.Operation) ([]byte, error) {
+	if in, ok := op.(*types.UploadMediaOperation); ok {
+		redacted := *in
+		redacted.Media = fmt.Sprintf("sha256:%x length:%d", sha256.Sum256([]byte(in.Media)), len(in.Media))
+		op = &redacted
+	}
+	return json.Marshal(op)
+}
+
+// auditRejected records the rejected transaction outside of the rolled back database transaction
+func (router *TransactionRouter) auditRejected(trx *types.Transaction, code int, dryRun bool) {
+	if router.Audit == nil || dryRun {
+		return
+	}
+
+	if err := router.audit(router.Audit, trx, code); err != nil {
+		log.WithError(err).Error("failed to audit rejected transaction")
+	}
+}
+
+// operationError prefixes the message with the index and the type of the failed operation
+func operationError(i int, op types.Operation, message string) string {
+	return fmt.Sprintf("operation %d (%s): %s", i, op.Type(), message)
+}
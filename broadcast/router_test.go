@@ -1,16 +1,30 @@
 package broadcast
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/jmoiron/sqlx"
 	"github.com/scorum/scorum-go"
+	"github.com/scorum/scorum-go/apis/network_broadcast"
 	"github.com/scorum/scorum-go/sign"
 	protocol "github.com/scorum/scorum-go/transport"
 	"github.com/scorum/scorum-go/transport/http"
+	scorumtype "github.com/scorum/scorum-go/types"
 	"github.com/stretchr/testify/require"
 	"gitlab.scorum.com/blog/api/broadcast/types"
 	"gitlab.scorum.com/blog/api/rpc"
@@ -22,7 +36,8 @@ func TestInvalidSignature(t *testing.T) {
 	transport := http.NewTransport(nodeHTTPS)
 	client := scorumgo.NewClient(transport)
 
-	router := NewTransactionRouter(client, rpc.NewVerifier(""))
+	router := NewTransactionRouter(nil, client, rpc.NewVerifier(""))
+	router.now = func() time.Time { return time.Date(2018, 4, 9, 9, 0, 0, 0, time.UTC) }
 
 	testdata, _ := os.Open("testdata/invalid_signature.json")
 	req := httptest.NewRequest("POST", "/", testdata)
@@ -33,7 +48,7 @@ func TestInvalidSignature(t *testing.T) {
 
 	// act
 	called := false
-	router.Register(types.FollowOpType, func(_ types.Operation) *rpc.Error {
+	router.Register(types.FollowOpType, func(_ *Tx, _ types.Operation) *rpc.Error {
 		called = true
 		return nil
 	})
@@ -59,7 +74,8 @@ func TestValidSignature(t *testing.T) {
 	transport := http.NewTransport(nodeHTTPS)
 	client := scorumgo.NewClient(transport)
 
-	router := NewTransactionRouter(client, rpc.NewVerifier(sign.TestChain.ID))
+	router := NewTransactionRouter(newTestDB(), client, rpc.NewVerifier(sign.TestChain.ID))
+	router.now = func() time.Time { return time.Date(2020, 1, 1, 0, 30, 0, 0, time.UTC) }
 
 	testdata, _ := os.Open("testdata/valid_signature.json")
 	req := httptest.NewRequest("POST", "/", testdata)
@@ -70,7 +86,7 @@ func TestValidSignature(t *testing.T) {
 
 	// act
 	called := false
-	router.Register(types.FollowOpType, func(_ types.Operation) *rpc.Error {
+	router.Register(types.FollowOpType, func(_ *Tx, _ types.Operation) *rpc.Error {
 		called = true
 		return nil
 	})
@@ -90,3 +106,218 @@ func TestValidSignature(t *testing.T) {
 	require.Nil(t, rpcResp.Error)
 	require.NotNil(t, rpcResp.Result)
 }
+
+func TestTransactionRouter_Atomic(t *testing.T) {
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(t, err)
+
+	verifier := rpc.NewVerifier(sign.TestChain.ID)
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	router := NewTransactionRouter(newTestDB(), nil, verifier)
+	router.Authorities = singleKeyAuthorities{key: key}
+	router.now = func() time.Time { return now }
+
+	var (
+		applied    []string
+		committed  []string
+		rolledBack []string
+		dryRun     bool
+	)
+	router.Register(types.FollowOpType, func(tx *Tx, op types.Operation) *rpc.Error {
+		in := op.(*types.FollowOperation)
+		if in.Follow == "unknown" {
+			return &rpc.Error{Code: rpc.ProfileNotFoundCode, Message: "unknown not found"}
+		}
+		if in.Follow == "panic" {
+			panic("handler failed")
+		}
+		applied = append(applied, in.Follow)
+		dryRun = tx.IsDryRun()
+		tx.AfterCommit(func() {
+			committed = append(committed, in.Follow)
+		})
+		tx.OnRollback(func() {
+			rolledBack = append(rolledBack, in.Follow)
+		})
+		return nil
+	})
+
+	var trx types.Transaction
+	handle := router.Route
+	route := func(expiration time.Time, follows ...string) protocol.RPCResponse {
+		trx = types.Transaction{Expiration: &scorumtype.Time{Time: &expiration}}
+
+		var ops []interface{}
+		for _, follow := range follows {
+			op := &types.FollowOperation{Account: "leonarda", Follow: follow}
+			trx.Operations = append(trx.Operations, op)
+			ops = append(ops, []interface{}{op.Type(), op})
+		}
+
+		digest, err := verifier.TransactionDigest(&trx)
+		require.NoError(t, err)
+
+		body, err := json.Marshal(map[string]interface{}{
+			"id":     1,
+			"method": "call",
+			"params": []interface{}{"network_broadcast_api", "broadcast_transaction_synchronous", []interface{}{
+				map[string]interface{}{
+					"ref_block_num":    trx.RefBlockNum,
+					"ref_block_prefix": trx.RefBlockPrefix,
+					"expiration":       trx.Expiration,
+					"operations":       ops,
+					"signatures":       []string{hex.EncodeToString(sign.SignBufferSha256(digest, key.ToECDSA()))},
+				},
+			}},
+		})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		ctx := rpc.NewContext(httptest.NewRequest("POST", "/", bytes.NewReader(body)), w)
+		require.True(t, ctx.Parse())
+		handle(ctx)
+
+		var resp protocol.RPCResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		applied, committed, rolledBack = nil, nil, nil
+		resp := route(now.Add(time.Minute), "kristie", "sheldon")
+		require.Nil(t, resp.Error)
+
+		var result network_broadcast.BroadcastResponse
+		require.NoError(t, json.Unmarshal(*resp.Result, &result))
+		id, err := trx.ID()
+		require.NoError(t, err)
+		require.Equal(t, id, result.ID)
+
+		require.Equal(t, []string{"kristie", "sheldon"}, applied)
+		require.Equal(t, []string{"kristie", "sheldon"}, committed)
+		require.Empty(t, rolledBack)
+		require.False(t, dryRun)
+	})
+
+	t.Run("dry_run", func(t *testing.T) {
+		handle = router.ValidateTransaction
+		defer func() { handle = router.Route }()
+
+		applied, committed, rolledBack = nil, nil, nil
+		resp := route(now.Add(time.Minute), "kristie", "sheldon")
+		require.Nil(t, resp.Error)
+
+		var result network_broadcast.BroadcastResponse
+		require.NoError(t, json.Unmarshal(*resp.Result, &result))
+		id, err := trx.ID()
+		require.NoError(t, err)
+		require.Equal(t, id, result.ID)
+
+		require.Equal(t, []string{"kristie", "sheldon"}, applied)
+		require.Empty(t, committed)
+		require.Equal(t, []string{"kristie", "sheldon"}, rolledBack)
+		require.True(t, dryRun)
+
+		resp = route(now.Add(time.Minute), "kristie", "unknown")
+		require.NotNil(t, resp.Error)
+		require.Equal(t, "operation 1 (follow): unknown not found", resp.Error.Message)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		applied, committed, rolledBack = nil, nil, nil
+		resp := route(now.Add(time.Minute), "kristie", "unknown", "sheldon")
+		require.NotNil(t, resp.Error)
+		require.Equal(t, rpc.ProfileNotFoundCode, resp.Error.Code)
+		require.Equal(t, "operation 1 (follow): unknown not found", resp.Error.Message)
+		require.Equal(t, []string{"kristie"}, applied)
+		require.Empty(t, committed)
+		require.Equal(t, []string{"kristie"}, rolledBack)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		applied, committed, rolledBack = nil, nil, nil
+		require.PanicsWithValue(t, "handler failed", func() {
+			route(now.Add(time.Minute), "kristie", "panic")
+		})
+		require.Equal(t, []string{"kristie"}, applied)
+		require.Empty(t, committed)
+		require.Equal(t, []string{"kristie"}, rolledBack)
+	})
+
+	t.Run("invalid_operation", func(t *testing.T) {
+		applied, committed, rolledBack = nil, nil, nil
+		resp := route(now.Add(time.Minute), "kristie", "leonarda")
+		require.NotNil(t, resp.Error)
+		require.Equal(t, rpc.InvalidParameterCode, resp.Error.Code)
+		require.Contains(t, resp.Error.Message, "operation 1 (follow)")
+		require.Empty(t, applied)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		applied, committed, rolledBack = nil, nil, nil
+		resp := route(now, "kristie")
+		require.NotNil(t, resp.Error)
+		require.Equal(t, rpc.ExpiredTransactionCode, resp.Error.Code)
+		require.Empty(t, applied)
+	})
+
+	t.Run("expiration_too_far", func(t *testing.T) {
+		applied, committed, rolledBack = nil, nil, nil
+		resp := route(now.Add(DefaultMaxExpiration+time.Second), "kristie")
+		require.NotNil(t, resp.Error)
+		require.Equal(t, rpc.InvalidParameterCode, resp.Error.Code)
+		require.Empty(t, applied)
+	})
+}
+
+func TestAuditPayload(t *testing.T) {
+	media := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 1024))
+	op := &types.UploadMediaOperation{Account: "leonarda", ID: "avatar", Media: media, ContentType: "image/png"}
+
+	payload, err := auditPayload(op)
+	require.NoError(t, err)
+
+	var audited types.UploadMediaOperation
+	require.NoError(t, json.Unmarshal(payload, &audited))
+	require.Equal(t, "avatar", audited.ID)
+	require.Equal(t, fmt.Sprintf("sha256:%x length:%d", sha256.Sum256([]byte(media)), len(media)), audited.Media)
+	// the operation itself is kept intact
+	require.Equal(t, media, op.Media)
+}
+
+// singleKeyAuthorities authorizes every account with the same key
+type singleKeyAuthorities struct {
+	key *btcec.PrivateKey
+}
+
+func (a singleKeyAuthorities) GetAuthorities(account string) (*rpc.Authorities, error) {
+	pubKey := "SCR" + base58.Encode(append(a.key.PubKey().SerializeCompressed(), 0, 0, 0, 0))
+	auth := scorumtype.Authority{WeightThreshold: 1, KeyAuths: scorumtype.StringInt64Map{pubKey: 1}}
+	return &rpc.Authorities{Owner: auth, Active: auth, Posting: auth}, nil
+}
+
+// testDriver is a database driver supporting only empty transactions
+type testDriver struct{}
+
+func (testDriver) Open(string) (driver.Conn, error) { return testConn{}, nil }
+
+type testConn struct{}
+
+func (testConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (testConn) Close() error                        { return nil }
+func (testConn) Begin() (driver.Tx, error)           { return testTx{}, nil }
+
+type testTx struct{}
+
+func (testTx) Commit() error   { return nil }
+func (testTx) Rollback() error { return nil }
+
+func init() {
+	sql.Register("broadcast_test", testDriver{})
+}
+
+func newTestDB() *sqlx.DB {
+	return sqlx.MustOpen("broadcast_test", "")
+}
@@ -0,0 +1,68 @@
+package broadcast
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// Tx is the database transaction the operation handlers are applied within,
+// it keeps the callbacks deferred until the transaction is committed or rolled back
+type Tx struct {
+	*sqlx.Tx
+
+	dryRun      bool
+	afterCommit []func()
+	onRollback  []func()
+}
+
+// NewTx wraps the database transaction, a dry run transaction is never committed by the TransactionRouter
+func NewTx(tx *sqlx.Tx, dryRun bool) *Tx {
+	return &Tx{Tx: tx, dryRun: dryRun}
+}
+
+// AfterCommit defers f, e.g. a push notification, until the transaction is committed,
+// f is dropped if the transaction is rolled back
+func (tx *Tx) AfterCommit(f func()) {
+	tx.afterCommit = append(tx.afterCommit, f)
+}
+
+// OnRollback defers f, e.g. a removal of the uploaded blob, until the transaction is rolled back,
+// f is dropped if the transaction is committed
+func (tx *Tx) OnRollback(f func()) {
+	tx.onRollback = append(tx.onRollback, f)
+}
+
+// IsDryRun reports whether the transaction is a dry run which is always rolled back,
+// handlers must skip side effects out of the database, e.g. blob uploads, for such transactions
+func (tx *Tx) IsDryRun() bool {
+	return tx.dryRun
+}
+
+// Commit commits the transaction and invokes the after commit callbacks
+func (tx *Tx) Commit() error {
+	if err := tx.Tx.Commit(); err != nil {
+		tx.rollbackCallbacks()
+		return err
+	}
+
+	callbacks := tx.afterCommit
+	tx.afterCommit, tx.onRollback = nil, nil
+	for _, f := range callbacks {
+		f()
+	}
+	return nil
+}
+
+// Rollback aborts the transaction and invokes the rollback callbacks
+func (tx *Tx) Rollback() error {
+	err := tx.Tx.Rollback()
+	tx.rollbackCallbacks()
+	return err
+}
+
+func (tx *Tx) rollbackCallbacks() {
+	callbacks := tx.onRollback
+	tx.afterCommit, tx.onRollback = nil, nil
+	for _, f := range callbacks {
+		f()
+	}
+}
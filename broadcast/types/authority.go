@@ -0,0 +1,66 @@
+package types
+
+// AuthorityLevel is the account authority required to sign the operation,
+// the higher authority satisfies the lower one
+type AuthorityLevel int
+
+const (
+	PostingAuthority AuthorityLevel = iota
+	ActiveAuthority
+	OwnerAuthority
+)
+
+func (level AuthorityLevel) String() string {
+	switch level {
+	case PostingAuthority:
+		return "posting"
+	case ActiveAuthority:
+		return "active"
+	case OwnerAuthority:
+		return "owner"
+	}
+	return "unknown"
+}
+
+// RequiredAuthority returns the authority required to sign the operation of the given type,
+// operations missing in the requiredAuthorities require the owner authority
+func (kind OpType) RequiredAuthority() AuthorityLevel {
+	if level, ok := requiredAuthorities[kind]; ok {
+		return level
+	}
+	return OwnerAuthority
+}
+
+var requiredAuthorities = map[OpType]AuthorityLevel{
+	RegisterOpType:                   ActiveAuthority,
+	UpdateProfileOpType:              ActiveAuthority,
+	UpdateProfileSettingsOpType:      ActiveAuthority,
+	FollowOpType:                     PostingAuthority,
+	UnfollowOpType:                   PostingAuthority,
+	UploadMediaOpType:                PostingAuthority,
+	UpsertDraftOpType:                PostingAuthority,
+	RemoveDraftOpType:                PostingAuthority,
+	MarkNotificationReadOpType:       PostingAuthority,
+	MarkAllNotificationsReadOpType:   PostingAuthority,
+	MarkAllNotificationsSeenOpType:   PostingAuthority,
+	RegisterPushTokenOpType:          PostingAuthority,
+	DownvoteOpType:                   PostingAuthority,
+	RemoveDownvoteOpType:             PostingAuthority,
+	AddToBlacklistAdminOpType:        OwnerAuthority,
+	RemoveFromBlacklistAdminOpType:   OwnerAuthority,
+	AddCategoryAdminOpType:           OwnerAuthority,
+	RemoveCategoryAdminOpType:        OwnerAuthority,
+	UpdateCategoryAdminOpType:        OwnerAuthority,
+	SetAccountTrustedAdminOpType:     OwnerAuthority,
+	GrantRoleOpType:                  OwnerAuthority,
+	RevokeRoleOpType:                 OwnerAuthority,
+	SuspendAccountAdminOpType:        OwnerAuthority,
+	LiftSuspensionAdminOpType:        OwnerAuthority,
+	ResolveModerationCaseAdminOpType: OwnerAuthority,
+	MuteOpType:                       PostingAuthority,
+	UnmuteOpType:                     PostingAuthority,
+	BlockOpType:                      PostingAuthority,
+	UnblockOpType:                    PostingAuthority,
+	SubscribeOpType:                  PostingAuthority,
+	UnsubscribeOpType:                PostingAuthority,
+}
@@ -0,0 +1,22 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredAuthority(t *testing.T) {
+	for _, opType := range opTypes {
+		if opType == "" {
+			continue
+		}
+		_, ok := requiredAuthorities[opType]
+		require.True(t, ok, "%s has no required authority", opType)
+	}
+
+	require.Equal(t, PostingAuthority, FollowOpType.RequiredAuthority())
+	require.Equal(t, ActiveAuthority, UpdateProfileOpType.RequiredAuthority())
+	require.Equal(t, OwnerAuthority, AddCategoryAdminOpType.RequiredAuthority())
+	require.Equal(t, OwnerAuthority, OpType("dummy").RequiredAuthority())
+}
@@ -74,26 +74,37 @@ func (ops *Operations) UnmarshalJSON(data []byte) (err error) {
 }
 
 var templates = map[OpType]reflect.Type{
-	RegisterOpType:                 reflect.TypeOf(RegisterOperation{}),
-	RegisterPushTokenOpType:        reflect.TypeOf(RegisterPushTokenOperation{}),
-	UpdateProfileOpType:            reflect.TypeOf(UpdateProfileOperation{}),
-	FollowOpType:                   reflect.TypeOf(FollowOperation{}),
-	UnfollowOpType:                 reflect.TypeOf(UnfollowOperation{}),
-	UploadMediaOpType:              reflect.TypeOf(UploadMediaOperation{}),
-	AddToBlacklistAdminOpType:      reflect.TypeOf(AddToBlacklistAdminOperation{}),
-	RemoveFromBlacklistAdminOpType: reflect.TypeOf(RemoveFromBlacklistAdminOperation{}),
-	AddCategoryAdminOpType:         reflect.TypeOf(AddCategoryAdminOperation{}),
-	RemoveCategoryAdminOpType:      reflect.TypeOf(RemoveCategoryAdminOperation{}),
-	UpdateCategoryAdminOpType:      reflect.TypeOf(UpdateCategoryAdminOperation{}),
-	SetAccountTrustedAdminOpType:   reflect.TypeOf(SetAccountTrustedAdminOperation{}),
-	UpsertDraftOpType:              reflect.TypeOf(UpsertDraftOperation{}),
-	RemoveDraftOpType:              reflect.TypeOf(RemoveDraftOperation{}),
-	MarkNotificationReadOpType:     reflect.TypeOf(MarkNotificationReadOperation{}),
-	MarkAllNotificationsReadOpType: reflect.TypeOf(MarkAllNotificationsReadOperation{}),
-	MarkAllNotificationsSeenOpType: reflect.TypeOf(MarkAllNotificationsSeenOperation{}),
-	UpdateProfileSettingsOpType:    reflect.TypeOf(UpdateProfileSettingsOperation{}),
-	DownvoteOpType:                 reflect.TypeOf(DownvoteOperation{}),
-	RemoveDownvoteOpType:           reflect.TypeOf(RemoveDownvoteOperation{}),
+	RegisterOpType:                   reflect.TypeOf(RegisterOperation{}),
+	RegisterPushTokenOpType:          reflect.TypeOf(RegisterPushTokenOperation{}),
+	UpdateProfileOpType:              reflect.TypeOf(UpdateProfileOperation{}),
+	FollowOpType:                     reflect.TypeOf(FollowOperation{}),
+	UnfollowOpType:                   reflect.TypeOf(UnfollowOperation{}),
+	UploadMediaOpType:                reflect.TypeOf(UploadMediaOperation{}),
+	AddToBlacklistAdminOpType:        reflect.TypeOf(AddToBlacklistAdminOperation{}),
+	RemoveFromBlacklistAdminOpType:   reflect.TypeOf(RemoveFromBlacklistAdminOperation{}),
+	AddCategoryAdminOpType:           reflect.TypeOf(AddCategoryAdminOperation{}),
+	RemoveCategoryAdminOpType:        reflect.TypeOf(RemoveCategoryAdminOperation{}),
+	UpdateCategoryAdminOpType:        reflect.TypeOf(UpdateCategoryAdminOperation{}),
+	SetAccountTrustedAdminOpType:     reflect.TypeOf(SetAccountTrustedAdminOperation{}),
+	UpsertDraftOpType:                reflect.TypeOf(UpsertDraftOperation{}),
+	RemoveDraftOpType:                reflect.TypeOf(RemoveDraftOperation{}),
+	MarkNotificationReadOpType:       reflect.TypeOf(MarkNotificationReadOperation{}),
+	MarkAllNotificationsReadOpType:   reflect.TypeOf(MarkAllNotificationsReadOperation{}),
+	MarkAllNotificationsSeenOpType:   reflect.TypeOf(MarkAllNotificationsSeenOperation{}),
+	UpdateProfileSettingsOpType:      reflect.TypeOf(UpdateProfileSettingsOperation{}),
+	DownvoteOpType:                   reflect.TypeOf(DownvoteOperation{}),
+	RemoveDownvoteOpType:             reflect.TypeOf(RemoveDownvoteOperation{}),
+	GrantRoleOpType:                  reflect.TypeOf(GrantRoleOperation{}),
+	RevokeRoleOpType:                 reflect.TypeOf(RevokeRoleOperation{}),
+	SuspendAccountAdminOpType:        reflect.TypeOf(SuspendAccountAdminOperation{}),
+	LiftSuspensionAdminOpType:        reflect.TypeOf(LiftSuspensionAdminOperation{}),
+	ResolveModerationCaseAdminOpType: reflect.TypeOf(ResolveModerationCaseAdminOperation{}),
+	MuteOpType:                       reflect.TypeOf(MuteOperation{}),
+	UnmuteOpType:                     reflect.TypeOf(UnmuteOperation{}),
+	BlockOpType:                      reflect.TypeOf(BlockOperation{}),
+	UnblockOpType:                    reflect.TypeOf(UnblockOperation{}),
+	SubscribeOpType:                  reflect.TypeOf(SubscribeOperation{}),
+	UnsubscribeOpType:                reflect.TypeOf(UnsubscribeOperation{}),
 }
 
 // UnknownOperation
@@ -191,6 +202,22 @@ type UpdateProfileOperation struct {
 	Bio         string `json:"bio" validate:"omitempty,max=160"`
 	AvatarUrl   string `json:"avatar_url" validate:"omitempty,uri"`
 	CoverUrl    string `json:"cover_url" validate:"omitempty,uri"`
+	// Extra keeps the extra fields unchanged if omitted
+	Extra *ProfileExtra `json:"extra,omitempty"`
+}
+
+// ProfileExtra is the versioned set of the extra profile fields,
+// the social links are full urls of the profiles in the corresponding networks
+type ProfileExtra struct {
+	Version    uint8  `json:"version" validate:"eq=1"`
+	Website    string `json:"website" validate:"omitempty,url,max=100"`
+	Twitter    string `json:"twitter" validate:"omitempty,url,max=100"`
+	Telegram   string `json:"telegram" validate:"omitempty,url,max=100"`
+	YouTube    string `json:"youtube" validate:"omitempty,url,max=100"`
+	Facebook   string `json:"facebook" validate:"omitempty,url,max=100"`
+	Instagram  string `json:"instagram" validate:"omitempty,url,max=100"`
+	Language   string `json:"language" validate:"omitempty,max=5"`
+	PinnedPost string `json:"pinned_post" validate:"omitempty,max=256"`
 }
 
 func (op *UpdateProfileOperation) MarshalTransaction(encoder *transaction.Encoder) error {
@@ -202,6 +229,18 @@ func (op *UpdateProfileOperation) MarshalTransaction(encoder *transaction.Encode
 	enc.Encode(op.Bio)
 	enc.Encode(op.AvatarUrl)
 	enc.Encode(op.CoverUrl)
+	// the extra fields are encoded only if set to keep the signatures of the older clients valid
+	if op.Extra != nil {
+		enc.Encode(op.Extra.Version)
+		enc.Encode(op.Extra.Website)
+		enc.Encode(op.Extra.Twitter)
+		enc.Encode(op.Extra.Telegram)
+		enc.Encode(op.Extra.YouTube)
+		enc.Encode(op.Extra.Facebook)
+		enc.Encode(op.Extra.Instagram)
+		enc.Encode(op.Extra.Language)
+		enc.Encode(op.Extra.PinnedPost)
+	}
 	return enc.Err()
 }
 
@@ -238,11 +277,13 @@ func (op *UploadMediaOperation) MarshalTransaction(encoder *transaction.Encoder)
 	return enc.Err()
 }
 
-// AddToBlacklistAdminOperation
+// AddToBlacklistAdminOperation blacklists the post, empty permlink blacklists every post of the blog account
 type AddToBlacklistAdminOperation struct {
 	Account     string `json:"account" validate:"required"`
 	BlogAccount string `json:"blog_account" validate:"required"`
-	Permlink    string `json:"permlink" validate:"required"`
+	Permlink    string `json:"permlink"`
+	Reason      string `json:"reason"`
+	Note        string `json:"note" validate:"max=500"`
 }
 
 func (op *AddToBlacklistAdminOperation) MarshalTransaction(encoder *transaction.Encoder) error {
@@ -251,6 +292,11 @@ func (op *AddToBlacklistAdminOperation) MarshalTransaction(encoder *transaction.
 	enc.Encode(op.Account)
 	enc.Encode(op.BlogAccount)
 	enc.Encode(op.Permlink)
+	// reason and note are encoded only if set to keep signatures of the clients unaware of them valid
+	if op.Reason != "" || op.Note != "" {
+		enc.Encode(op.Reason)
+		enc.Encode(op.Note)
+	}
 	return enc.Err()
 }
 
@@ -264,7 +310,7 @@ func (op *AddToBlacklistAdminOperation) GetAccount() string { return op.Account
 type RemoveFromBlacklistAdminOperation struct {
 	Account     string `json:"account" validate:"required"`
 	BlogAccount string `json:"blog_account" validate:"required"`
-	Permlink    string `json:"permlink" validate:"required"`
+	Permlink    string `json:"permlink"`
 }
 
 func (op *RemoveFromBlacklistAdminOperation) MarshalTransaction(encoder *transaction.Encoder) error {
@@ -487,6 +533,27 @@ func (op *MarkAllNotificationsSeenOperation) MarshalTransaction(encoder *transac
 type UpdateProfileSettingsOperation struct {
 	Account                        string `json:"account" validate:"required"`
 	EnableEmailUnseenNotifications bool   `json:"enable_email_unseen_notifications"`
+	// NotificationPreferences are upserted, the omitted ones are kept unchanged,
+	// the service limits them to one per notification type and channel
+	NotificationPreferences []NotificationPreference `json:"notification_preferences,omitempty" validate:"dive"`
+	// QuietHours are kept unchanged if omitted, the empty start and end remove them
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+}
+
+// NotificationPreference enables or disables the notifications of the type delivered via the channel,
+// the channel is one of in_app, push or email
+type NotificationPreference struct {
+	Type    string `json:"type" validate:"required"`
+	Channel string `json:"channel" validate:"required"`
+	Enabled bool   `json:"enabled"`
+}
+
+// QuietHours is the daily period the push notifications are suppressed, they are not delivered later,
+// start and end are HH:MM in the time zone, e.g. Europe/Kiev
+type QuietHours struct {
+	Start    string `json:"start" validate:"omitempty,len=5"`
+	End      string `json:"end" validate:"omitempty,len=5"`
+	TimeZone string `json:"time_zone" validate:"max=64"`
 }
 
 func (op *UpdateProfileSettingsOperation) Type() OpType {
@@ -500,6 +567,20 @@ func (op *UpdateProfileSettingsOperation) MarshalTransaction(encoder *transactio
 	enc.EncodeUVarint(uint64(op.Type().Code()))
 	enc.Encode(op.Account)
 	enc.EncodeBool(op.EnableEmailUnseenNotifications)
+	if op.NotificationPreferences != nil || op.QuietHours != nil {
+		enc.EncodeUVarint(uint64(len(op.NotificationPreferences)))
+		for _, p := range op.NotificationPreferences {
+			enc.Encode(p.Type)
+			enc.Encode(p.Channel)
+			enc.EncodeBool(p.Enabled)
+		}
+		enc.EncodeBool(op.QuietHours != nil)
+		if op.QuietHours != nil {
+			enc.Encode(op.QuietHours.Start)
+			enc.Encode(op.QuietHours.End)
+			enc.Encode(op.QuietHours.TimeZone)
+		}
+	}
 	return enc.Err()
 }
 
@@ -548,3 +629,254 @@ func (op *RemoveDownvoteOperation) MarshalTransaction(encoder *transaction.Encod
 	enc.Encode(op.Permlink)
 	return enc.Err()
 }
+
+// GrantRoleOperation grants the role to the blog account, the domain scopes the category_editor role
+type GrantRoleOperation struct {
+	Account     string `json:"account" validate:"required"`
+	BlogAccount string `json:"blog_account" validate:"required"`
+	Role        string `json:"role" validate:"required"`
+	Domain      string `json:"domain"`
+}
+
+func (op *GrantRoleOperation) Type() OpType {
+	return GrantRoleOpType
+}
+
+func (op *GrantRoleOperation) GetAccount() string { return op.Account }
+
+func (op *GrantRoleOperation) MarshalTransaction(encoder *transaction.Encoder) error {
+	enc := transaction.NewRollingEncoder(encoder)
+	enc.EncodeUVarint(uint64(op.Type().Code()))
+	enc.Encode(op.Account)
+	enc.Encode(op.BlogAccount)
+	enc.Encode(op.Role)
+	enc.Encode(op.Domain)
+	return enc.Err()
+}
+
+// RevokeRoleOperation revokes the role granted to the blog account
+type RevokeRoleOperation struct {
+	Account     string `json:"account" validate:"required"`
+	BlogAccount string `json:"blog_account" validate:"required"`
+	Role        string `json:"role" validate:"required"`
+	Domain      string `json:"domain"`
+}
+
+func (op *RevokeRoleOperation) Type() OpType {
+	return RevokeRoleOpType
+}
+
+func (op *RevokeRoleOperation) GetAccount() string { return op.Account }
+
+func (op *RevokeRoleOperation) MarshalTransaction(encoder *transaction.Encoder) error {
+	enc := transaction.NewRollingEncoder(encoder)
+	enc.EncodeUVarint(uint64(op.Type().Code()))
+	enc.Encode(op.Account)
+	enc.Encode(op.BlogAccount)
+	enc.Encode(op.Role)
+	enc.Encode(op.Domain)
+	return enc.Err()
+}
+
+// SuspendAccountAdminOperation suspends the blog account for the duration in seconds,
+// zero duration suspends the account until the suspension is lifted
+type SuspendAccountAdminOperation struct {
+	Account     string `json:"account" validate:"required"`
+	BlogAccount string `json:"blog_account" validate:"required"`
+	Reason      string `json:"reason" validate:"required,max=500"`
+	Duration    uint32 `json:"duration"`
+}
+
+func (op *SuspendAccountAdminOperation) Type() OpType {
+	return SuspendAccountAdminOpType
+}
+
+func (op *SuspendAccountAdminOperation) GetAccount() string { return op.Account }
+
+func (op *SuspendAccountAdminOperation) MarshalTransaction(encoder *transaction.Encoder) error {
+	enc := transaction.NewRollingEncoder(encoder)
+	enc.EncodeUVarint(uint64(op.Type().Code()))
+	enc.Encode(op.Account)
+	enc.Encode(op.BlogAccount)
+	enc.Encode(op.Reason)
+	enc.Encode(op.Duration)
+	return enc.Err()
+}
+
+// LiftSuspensionAdminOperation lifts the suspension of the blog account
+type LiftSuspensionAdminOperation struct {
+	Account     string `json:"account" validate:"required"`
+	BlogAccount string `json:"blog_account" validate:"required"`
+}
+
+func (op *LiftSuspensionAdminOperation) Type() OpType {
+	return LiftSuspensionAdminOpType
+}
+
+func (op *LiftSuspensionAdminOperation) GetAccount() string { return op.Account }
+
+func (op *LiftSuspensionAdminOperation) MarshalTransaction(encoder *transaction.Encoder) error {
+	enc := transaction.NewRollingEncoder(encoder)
+	enc.EncodeUVarint(uint64(op.Type().Code()))
+	enc.Encode(op.Account)
+	enc.Encode(op.BlogAccount)
+	return enc.Err()
+}
+
+// ResolveModerationCaseAdminOperation resolves the open moderation case with the decision
+// blacklist, dismiss or suspend_author, the duration in seconds applies to suspend_author only
+type ResolveModerationCaseAdminOperation struct {
+	Account  string `json:"account" validate:"required"`
+	CaseID   int64  `json:"case_id" validate:"required"`
+	Decision string `json:"decision" validate:"required"`
+	Note     string `json:"note" validate:"max=500"`
+	Duration uint32 `json:"duration"`
+}
+
+func (op *ResolveModerationCaseAdminOperation) Type() OpType {
+	return ResolveModerationCaseAdminOpType
+}
+
+func (op *ResolveModerationCaseAdminOperation) GetAccount() string { return op.Account }
+
+func (op *ResolveModerationCaseAdminOperation) MarshalTransaction(encoder *transaction.Encoder) error {
+	enc := transaction.NewRollingEncoder(encoder)
+	enc.EncodeUVarint(uint64(op.Type().Code()))
+	enc.Encode(op.Account)
+	enc.Encode(op.CaseID)
+	enc.Encode(op.Decision)
+	enc.Encode(op.Note)
+	enc.Encode(op.Duration)
+	return enc.Err()
+}
+
+// MuteOperation
+type MuteOperation struct {
+	Account string `validate:"required"`
+	Mute    string `validate:"required,nefield=Account"`
+}
+
+func (op *MuteOperation) MarshalTransaction(encoder *transaction.Encoder) error {
+	enc := transaction.NewRollingEncoder(encoder)
+	enc.EncodeUVarint(uint64(op.Type().Code()))
+	enc.Encode(op.Account)
+	enc.Encode(op.Mute)
+	return enc.Err()
+}
+
+func (op *MuteOperation) Type() OpType {
+	return MuteOpType
+}
+
+func (op *MuteOperation) GetAccount() string { return op.Account }
+
+// UnmuteOperation
+type UnmuteOperation struct {
+	Account string `validate:"required"`
+	Unmute  string `validate:"required,nefield=Account"`
+}
+
+func (op *UnmuteOperation) MarshalTransaction(encoder *transaction.Encoder) error {
+	enc := transaction.NewRollingEncoder(encoder)
+	enc.EncodeUVarint(uint64(op.Type().Code()))
+	enc.Encode(op.Account)
+	enc.Encode(op.Unmute)
+	return enc.Err()
+}
+
+func (op *UnmuteOperation) Type() OpType {
+	return UnmuteOpType
+}
+
+func (op *UnmuteOperation) GetAccount() string { return op.Account }
+
+// BlockOperation
+type BlockOperation struct {
+	Account string `validate:"required"`
+	Block   string `validate:"required,nefield=Account"`
+}
+
+func (op *BlockOperation) MarshalTransaction(encoder *transaction.Encoder) error {
+	enc := transaction.NewRollingEncoder(encoder)
+	enc.EncodeUVarint(uint64(op.Type().Code()))
+	enc.Encode(op.Account)
+	enc.Encode(op.Block)
+	return enc.Err()
+}
+
+func (op *BlockOperation) Type() OpType {
+	return BlockOpType
+}
+
+func (op *BlockOperation) GetAccount() string { return op.Account }
+
+// UnblockOperation
+type UnblockOperation struct {
+	Account string `validate:"required"`
+	Unblock string `validate:"required,nefield=Account"`
+}
+
+func (op *UnblockOperation) MarshalTransaction(encoder *transaction.Encoder) error {
+	enc := transaction.NewRollingEncoder(encoder)
+	enc.EncodeUVarint(uint64(op.Type().Code()))
+	enc.Encode(op.Account)
+	enc.Encode(op.Unblock)
+	return enc.Err()
+}
+
+func (op *UnblockOperation) Type() OpType {
+	return UnblockOpType
+}
+
+func (op *UnblockOperation) GetAccount() string { return op.Account }
+
+// SubscribeOperation subscribes the account to the category or the tag posts of the domain,
+// Notify enables the notifications about the new posts of the category
+type SubscribeOperation struct {
+	Account string `json:"account" validate:"required"`
+	Domain  string `json:"domain" validate:"required"`
+	Kind    string `json:"kind" validate:"required"`
+	Topic   string `json:"topic" validate:"required,max=50"`
+	Notify  bool   `json:"notify"`
+}
+
+func (op *SubscribeOperation) MarshalTransaction(encoder *transaction.Encoder) error {
+	enc := transaction.NewRollingEncoder(encoder)
+	enc.EncodeUVarint(uint64(op.Type().Code()))
+	enc.Encode(op.Account)
+	enc.Encode(op.Domain)
+	enc.Encode(op.Kind)
+	enc.Encode(op.Topic)
+	enc.EncodeBool(op.Notify)
+	return enc.Err()
+}
+
+func (op *SubscribeOperation) Type() OpType {
+	return SubscribeOpType
+}
+
+func (op *SubscribeOperation) GetAccount() string { return op.Account }
+
+// UnsubscribeOperation
+type UnsubscribeOperation struct {
+	Account string `json:"account" validate:"required"`
+	Domain  string `json:"domain" validate:"required"`
+	Kind    string `json:"kind" validate:"required"`
+	Topic   string `json:"topic" validate:"required,max=50"`
+}
+
+func (op *UnsubscribeOperation) MarshalTransaction(encoder *transaction.Encoder) error {
+	enc := transaction.NewRollingEncoder(encoder)
+	enc.EncodeUVarint(uint64(op.Type().Code()))
+	enc.Encode(op.Account)
+	enc.Encode(op.Domain)
+	enc.Encode(op.Kind)
+	enc.Encode(op.Topic)
+	return enc.Err()
+}
+
+func (op *UnsubscribeOperation) Type() OpType {
+	return UnsubscribeOpType
+}
+
+func (op *UnsubscribeOperation) GetAccount() string { return op.Account }
@@ -39,27 +39,49 @@ var opTypes = []OpType{
 	RegisterPushTokenOpType,
 	DownvoteOpType,
 	RemoveDownvoteOpType,
+	GrantRoleOpType,
+	RevokeRoleOpType,
+	SuspendAccountAdminOpType,
+	LiftSuspensionAdminOpType,
+	ResolveModerationCaseAdminOpType,
+	MuteOpType,
+	UnmuteOpType,
+	BlockOpType,
+	UnblockOpType,
+	SubscribeOpType,
+	UnsubscribeOpType,
 }
 
 const (
-	RegisterOpType                 OpType = "register"
-	FollowOpType                   OpType = "follow"
-	UnfollowOpType                 OpType = "unfollow"
-	UpdateProfileOpType            OpType = "update_profile"
-	UploadMediaOpType              OpType = "upload_media"
-	AddToBlacklistAdminOpType      OpType = "add_to_blacklist_admin"
-	RemoveFromBlacklistAdminOpType OpType = "remove_from_blacklist_admin"
-	AddCategoryAdminOpType         OpType = "add_category_admin"
-	RemoveCategoryAdminOpType      OpType = "remove_category_admin"
-	UpdateCategoryAdminOpType      OpType = "update_category_admin"
-	SetAccountTrustedAdminOpType   OpType = "set_account_trusted_admin"
-	UpsertDraftOpType              OpType = "upsert_draft"
-	RemoveDraftOpType              OpType = "remove_draft"
-	MarkNotificationReadOpType     OpType = "mark_notification_read"
-	MarkAllNotificationsReadOpType OpType = "mark_all_notifications_read"
-	MarkAllNotificationsSeenOpType OpType = "mark_all_notifications_seen"
-	UpdateProfileSettingsOpType    OpType = "update_profile_settings"
-	RegisterPushTokenOpType        OpType = "register_push_token"
-	DownvoteOpType                 OpType = "downvote"
-	RemoveDownvoteOpType           OpType = "remove_downvote"
+	RegisterOpType                   OpType = "register"
+	FollowOpType                     OpType = "follow"
+	UnfollowOpType                   OpType = "unfollow"
+	UpdateProfileOpType              OpType = "update_profile"
+	UploadMediaOpType                OpType = "upload_media"
+	AddToBlacklistAdminOpType        OpType = "add_to_blacklist_admin"
+	RemoveFromBlacklistAdminOpType   OpType = "remove_from_blacklist_admin"
+	AddCategoryAdminOpType           OpType = "add_category_admin"
+	RemoveCategoryAdminOpType        OpType = "remove_category_admin"
+	UpdateCategoryAdminOpType        OpType = "update_category_admin"
+	SetAccountTrustedAdminOpType     OpType = "set_account_trusted_admin"
+	UpsertDraftOpType                OpType = "upsert_draft"
+	RemoveDraftOpType                OpType = "remove_draft"
+	MarkNotificationReadOpType       OpType = "mark_notification_read"
+	MarkAllNotificationsReadOpType   OpType = "mark_all_notifications_read"
+	MarkAllNotificationsSeenOpType   OpType = "mark_all_notifications_seen"
+	UpdateProfileSettingsOpType      OpType = "update_profile_settings"
+	RegisterPushTokenOpType          OpType = "register_push_token"
+	DownvoteOpType                   OpType = "downvote"
+	RemoveDownvoteOpType             OpType = "remove_downvote"
+	GrantRoleOpType                  OpType = "grant_role"
+	RevokeRoleOpType                 OpType = "revoke_role"
+	SuspendAccountAdminOpType        OpType = "suspend_account_admin"
+	LiftSuspensionAdminOpType        OpType = "lift_suspension_admin"
+	ResolveModerationCaseAdminOpType OpType = "resolve_moderation_case_admin"
+	MuteOpType                       OpType = "mute"
+	UnmuteOpType                     OpType = "unmute"
+	BlockOpType                      OpType = "block"
+	UnblockOpType                    OpType = "unblock"
+	SubscribeOpType                  OpType = "subscribe"
+	UnsubscribeOpType                OpType = "unsubscribe"
 )
@@ -2,6 +2,8 @@ package types
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 
 	"github.com/pkg/errors"
 	"github.com/scorum/scorum-go/encoding/transaction"
@@ -12,7 +14,7 @@ type Transaction struct {
 	RefBlockNum    uint16      `json:"ref_block_num"`
 	RefBlockPrefix uint32      `json:"ref_block_prefix"`
 	Expiration     *types.Time `json:"expiration" validate:"required"`
-	Operations     Operations  `json:"operations" validate:"required,eq=1"`
+	Operations     Operations  `json:"operations" validate:"required,gt=0,lte=50"`
 	Signatures     []string    `json:"signatures" validate:"required,gt=0,dive,required"`
 }
 
@@ -45,3 +47,14 @@ func (tx *Transaction) Serialize() ([]byte, error) {
 
 	return b.Bytes(), nil
 }
+
+// ID returns the transaction id calculated the same way the blockchain does
+func (tx *Transaction) ID() (string, error) {
+	raw, err := tx.Serialize()
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256(raw)
+	return hex.EncodeToString(digest[:20]), nil
+}
@@ -87,6 +87,11 @@ func TestTransaction_ValidationTest(t *testing.T) {
 		ctrx := *trx
 		op := FollowOperation{}
 		ctrx.Operations = Operations{&op, &op}
+		require.NoError(t, validate.Struct(ctrx))
+
+		for len(ctrx.Operations) <= 50 {
+			ctrx.Operations = append(ctrx.Operations, &op)
+		}
 		require.Error(t, validate.Struct(ctrx))
 	})
 	t.Run("no_one_signatures", func(t *testing.T) {
@@ -105,3 +110,66 @@ func TestTransaction_ValidationTest(t *testing.T) {
 		require.Error(t, validate.Struct(ctrx))
 	})
 }
+
+func TestTransaction_SerializeAddToBlacklist(t *testing.T) {
+	serialize := func(op Operation) []byte {
+		tx := *trx
+		tx.Operations = Operations{op}
+		b, err := tx.Serialize()
+		require.NoError(t, err)
+		return b
+	}
+
+	legacy := serialize(&AddToBlacklistAdminOperation{Account: "acc1", BlogAccount: "acc2", Permlink: "post"})
+	extended := serialize(&AddToBlacklistAdminOperation{Account: "acc1", BlogAccount: "acc2", Permlink: "post", Reason: "spam"})
+
+	// extensions byte is the last one
+	require.Equal(t, legacy[:len(legacy)-1], extended[:len(legacy)-1])
+	require.Len(t, extended, len(legacy)+len("spam")+2)
+}
+
+func TestTransaction_SerializeUpdateProfile(t *testing.T) {
+	serialize := func(op Operation) []byte {
+		tx := *trx
+		tx.Operations = Operations{op}
+		b, err := tx.Serialize()
+		require.NoError(t, err)
+		return b
+	}
+
+	legacy := serialize(&UpdateProfileOperation{Account: "acc1", Bio: "bio"})
+	extended := serialize(&UpdateProfileOperation{Account: "acc1", Bio: "bio", Extra: &ProfileExtra{Version: 1}})
+
+	// extensions byte is the last one
+	require.Equal(t, legacy[:len(legacy)-1], extended[:len(legacy)-1])
+	// version and eight empty strings
+	require.Len(t, extended, len(legacy)+1+8)
+}
+
+func TestTransaction_SerializeUpdateProfileSettings(t *testing.T) {
+	serialize := func(op Operation) []byte {
+		tx := *trx
+		tx.Operations = Operations{op}
+		b, err := tx.Serialize()
+		require.NoError(t, err)
+		return b
+	}
+
+	legacy := serialize(&UpdateProfileSettingsOperation{Account: "acc1", EnableEmailUnseenNotifications: true})
+	preferences := serialize(&UpdateProfileSettingsOperation{
+		Account:                        "acc1",
+		EnableEmailUnseenNotifications: true,
+		NotificationPreferences:        []NotificationPreference{{Type: "post_voted", Channel: "push"}},
+	})
+	quietHours := serialize(&UpdateProfileSettingsOperation{
+		Account:                        "acc1",
+		EnableEmailUnseenNotifications: true,
+		QuietHours:                     &QuietHours{},
+	})
+
+	require.Equal(t, legacy[:len(legacy)-1], preferences[:len(legacy)-1])
+	// count, type, channel, enabled and the quiet hours flag
+	require.Len(t, preferences, len(legacy)+1+len("post_voted")+1+len("push")+1+1+1)
+	// empty count, the quiet hours flag and three empty strings
+	require.Len(t, quietHours, len(legacy)+1+1+3)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/jinzhu/configor"
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+	"gitlab.scorum.com/blog/api/blob"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/service"
+)
+
+const configPath = "config.yml"
+
+var (
+	configPathFlag = flag.String("config", configPath, "path to the app config")
+	accountFlag    = flag.String("account", "", "account to export or erase the data of")
+	outFlag        = flag.String("out", "", "path to the export file, stdout if empty")
+	formatFlag     = flag.String("format", "json", "export format, json or zip")
+	eraseFlag      = flag.Bool("erase", false, "erase the account data instead of exporting it")
+
+	// version is set via `go build -ldflags "-x main.version=version"`
+	version     string
+	versionFlag = flag.Bool("version", false, "app version")
+)
+
+type Config struct {
+	DB   string      `yaml:"db"`
+	Blob blob.Config `yaml:"blob"`
+}
+
+// account_data exports everything kept off-chain for the account or erases it printing the report
+func main() {
+	flag.Parse()
+	if *versionFlag {
+		log.Info(version)
+		return
+	}
+
+	if *accountFlag == "" {
+		log.Fatal("account is required")
+	}
+	if *formatFlag != "json" && *formatFlag != "zip" {
+		log.Fatalf("unknown format %s", *formatFlag)
+	}
+
+	var config Config
+	if err := configor.Load(&config, *configPathFlag); err != nil {
+		log.Fatal(err)
+	}
+
+	dbConn, err := sqlx.Open("postgres", config.DB)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer dbConn.Close()
+
+	blog := &service.Blog{
+		DB:                 service.Database{Write: dbConn, Read: dbConn},
+		Blob:               blob.NewService(config.Blob),
+		AccountDataStorage: db.NewAccountDataStorage(dbConn),
+	}
+
+	if *eraseFlag {
+		erase(blog, *accountFlag)
+		return
+	}
+
+	data, rpcErr := blog.ExportAccountData(*accountFlag)
+	if rpcErr != nil {
+		log.Fatal(rpcErr.Message)
+	}
+
+	out := os.Stdout
+	if *outFlag != "" {
+		if out, err = os.Create(*outFlag); err != nil {
+			log.Fatal(err)
+		}
+		defer out.Close()
+	}
+
+	if *formatFlag == "zip" {
+		err = writeZip(out, data)
+	} else {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(data)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Infof("%s data exported", *accountFlag)
+}
+
+// writeZip writes each section of the data into its own file, the rest goes to account.json
+func writeZip(w io.Writer, data *service.AccountData) error {
+	archive := zip.NewWriter(w)
+
+	sections := make([]string, 0, len(data.Data))
+	for name := range data.Data {
+		sections = append(sections, name)
+	}
+	sort.Strings(sections)
+
+	for _, name := range sections {
+		f, err := archive.Create(name + ".json")
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data.Data[name]); err != nil {
+			return err
+		}
+	}
+
+	f, err := archive.Create("account.json")
+	if err != nil {
+		return err
+	}
+	summary := *data
+	summary.Data = nil
+	if err := json.NewEncoder(f).Encode(summary); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func erase(blog *service.Blog, account string) {
+	report, rpcErr := blog.EraseAccountData(account)
+	if report != nil {
+		printReport(report)
+	}
+	if rpcErr != nil {
+		log.Fatal(rpcErr.Message)
+	}
+}
+
+func printReport(report *service.ErasureReport) {
+	fmt.Printf("account: %s\n", report.Account)
+
+	sections := make([]string, 0, len(report.Rows))
+	for name := range report.Rows {
+		sections = append(sections, name)
+	}
+	sort.Strings(sections)
+
+	fmt.Println("rows:")
+	for _, name := range sections {
+		fmt.Printf("  %-20s %d\n", name, report.Rows[name])
+	}
+
+	fmt.Printf("blobs removed: %d\n", len(report.BlobsRemoved))
+	for _, url := range report.BlobsRemoved {
+		fmt.Printf("  %s\n", url)
+	}
+
+	if len(report.BlobErrors) > 0 {
+		fmt.Printf("blobs failed: %d\n", len(report.BlobErrors))
+		for url, err := range report.BlobErrors {
+			fmt.Printf("  %s: %s\n", url, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/jinzhu/configor"
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+	"gitlab.scorum.com/blog/api/db"
+)
+
+const (
+	configPath = "config.yml"
+	timeLayout = `2006-01-02T15:04:05`
+)
+
+var (
+	configPathFlag = flag.String("config", configPath, "path to the app config")
+	outFlag        = flag.String("out", "", "path to the output file, stdout if empty")
+	accountFlag    = flag.String("account", "", "export operations of the account only")
+	opTypeFlag     = flag.String("op", "", "export operations of the type only")
+	fromFlag       = flag.String("from", "", "export operations since the time, e.g. 2018-09-01T00:00:00")
+	toFlag         = flag.String("to", "", "export operations until the time, e.g. 2018-10-01T00:00:00")
+
+	// version is set via `go build -ldflags "-x main.version=version"`
+	version     string
+	versionFlag = flag.Bool("version", false, "app version")
+)
+
+type Config struct {
+	DB string `yaml:"db"`
+}
+
+// audit_export writes the audited operations to NDJSON, one operation per line
+func main() {
+	flag.Parse()
+	if *versionFlag {
+		log.Info(version)
+		return
+	}
+
+	var config Config
+	if err := configor.Load(&config, *configPathFlag); err != nil {
+		log.Fatal(err)
+	}
+
+	filter := db.AuditFilter{
+		Account: *accountFlag,
+		OpType:  *opTypeFlag,
+		From:    parseTime(*fromFlag),
+		To:      parseTime(*toFlag),
+	}
+
+	dbConn, err := sqlx.Open("postgres", config.DB)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer dbConn.Close()
+
+	out := os.Stdout
+	if *outFlag != "" {
+		if out, err = os.Create(*outFlag); err != nil {
+			log.Fatal(err)
+		}
+		defer out.Close()
+	}
+
+	w := bufio.NewWriter(out)
+	encoder := json.NewEncoder(w)
+
+	var count int
+	err = db.NewAuditStorage(dbConn).Export(filter, func(entry *db.AuditEntry) error {
+		count++
+		return encoder.Encode(entry)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Infof("%d operations exported", count)
+}
+
+func parseTime(value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(timeLayout, value)
+	if err != nil {
+		log.Fatalf("invalid time %s: %s", value, err)
+	}
+	return t
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/btcsuite/btcutil"
 	"github.com/scorum/scorum-go/sign"
@@ -15,7 +17,6 @@ const (
 	chain   = "d3c1f19a4947c296446583f988c43fd1a83818fabaf3454a0020198cb361ebd2"
 	account = "roselle"
 	wif     = ""
-	salt    = "1111"
 )
 
 func main() {
@@ -28,6 +29,12 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// salt is "<unix timestamp>:<random>", it is accepted once within the time window
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		log.Fatal(err)
+	}
+	salt := fmt.Sprintf("%d:%s", time.Now().Unix(), hex.EncodeToString(random))
 	digest, _ := verifier.SignedRequestDigest(account, salt, params)
 
 	w, err := btcutil.DecodeWIF(wif)
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// accountDataSection is the off-chain table rows referencing the account
+type accountDataSection struct {
+	Name   string
+	Table  string
+	Column string
+	// Kept sections are exported only, the erase leaves the moderation records and the audit trail in place
+	Kept bool
+}
+
+// accountDataSections are the off-chain tables holding the account personal data
+var accountDataSections = []accountDataSection{
+	{Name: "profile", Table: "profiles", Column: "account"},
+	{Name: "profile_settings", Table: "profile_settings", Column: "account"},
+	{Name: "notification_preferences", Table: "notification_preferences", Column: "account"},
+	{Name: "profile_history", Table: "profile_history", Column: "account"},
+	{Name: "drafts", Table: "drafts", Column: "account"},
+	{Name: "media", Table: "media", Column: "account"},
+	{Name: "notifications", Table: "notifications", Column: "account"},
+	{Name: "following", Table: "followers", Column: "account"},
+	{Name: "followers", Table: "followers", Column: "follow_account"},
+	{Name: "unfollowed", Table: "unfollows", Column: "account"},
+	{Name: "unfollowers", Table: "unfollows", Column: "follow_account"},
+	{Name: "account_lists", Table: "account_lists", Column: "account"},
+	{Name: "follow_suggestions", Table: "follow_suggestions", Column: "account"},
+	{Name: "topic_subscriptions", Table: "topic_subscriptions", Column: "account"},
+	{Name: "push_tokens", Table: "push_tokens", Column: "account"},
+	{Name: "downvotes", Table: "downvotes", Column: "account"},
+	{Name: "operations_audit", Table: "operations_audit", Column: "account", Kept: true},
+	{Name: "suspensions", Table: "suspensions", Column: "account", Kept: true},
+	{Name: "moderation_cases", Table: "moderation_cases", Column: "author", Kept: true},
+	{Name: "moderation_decisions", Table: "moderation_decisions", Column: "moderator", Kept: true},
+	{Name: "roles", Table: "roles", Column: "account", Kept: true},
+}
+
+// AccountDataStorage exports and erases the off-chain data of the accounts
+type AccountDataStorage struct {
+	db sqlx.Ext
+}
+
+func NewAccountDataStorage(db *sqlx.DB) *AccountDataStorage {
+	return &AccountDataStorage{db: db}
+}
+
+func (as *AccountDataStorage) InTx(tx *sqlx.Tx) *AccountDataStorage {
+	return &AccountDataStorage{db: tx}
+}
+
+// Export returns the rows of the account as JSON arrays keyed by the section name
+func (as *AccountDataStorage) Export(account string) (map[string]json.RawMessage, error) {
+	data := make(map[string]json.RawMessage, len(accountDataSections))
+	for _, section := range accountDataSections {
+		var rows []byte
+		err := sqlx.Get(as.db, &rows, fmt.Sprintf(
+			`SELECT COALESCE(json_agg(t), '[]') FROM %s t WHERE %s = $1`, section.Table, section.Column),
+			account)
+		if err != nil {
+			return nil, err
+		}
+		data[section.Name] = rows
+	}
+	return data, nil
+}
+
+// Erase deletes the rows of the account and the notifications about its actions, the profile is anonymized
+// since it is referenced by the posts and the comments, the payloads of the audited operations are cleared.
+// Returns the number of the rows erased in each section
+func (as *AccountDataStorage) Erase(account string) (map[string]int64, error) {
+	report := make(map[string]int64, len(accountDataSections)+1)
+
+	exec := func(name, query string) error {
+		res, err := as.db.Exec(query, account)
+		if err != nil {
+			return err
+		}
+		if report[name], err = res.RowsAffected(); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	for _, section := range accountDataSections {
+		if section.Table == "profiles" || section.Kept {
+			continue
+		}
+		if err := exec(section.Name, fmt.Sprintf(`DELETE FROM %s WHERE %s = $1`, section.Table, section.Column)); err != nil {
+			return nil, err
+		}
+	}
+
+	err := exec("notifications_about", `DELETE FROM notifications WHERE meta->>'account' = $1`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = exec("operations_audit", `UPDATE operations_audit SET payload = '{}' WHERE account = $1`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = exec("profile", `
+		UPDATE profiles
+		SET display_name = account, location = '', bio = '', avatar_url = '', cover_url = '',
+		    website = '', social_links = '{}', language = '', pinned_post = ''
+		WHERE account = $1`)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
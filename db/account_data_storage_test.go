@@ -0,0 +1,101 @@
+package db
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountDataStorage(t *testing.T) {
+	defer func() {
+		_, err := dbWrite.Exec("DELETE FROM downvotes")
+		require.NoError(t, err)
+		_, err = dbWrite.Exec("DELETE FROM operations_audit")
+		require.NoError(t, err)
+		_, err = dbWrite.Exec("DELETE FROM suspensions")
+		require.NoError(t, err)
+		_, err = dbWrite.Exec("DELETE FROM followers")
+		require.NoError(t, err)
+		_, err = dbWrite.Exec("DELETE FROM drafts")
+		require.NoError(t, err)
+		cleanUp(t)
+	}()
+
+	registerAccount(t, leonarda)
+	registerAccount(t, sheldon)
+
+	for _, query := range []string{
+		`UPDATE profiles SET bio = 'bio', website = 'https://scorum.com' WHERE account = 'leonarda'`,
+		`INSERT INTO followers (account, follow_account) VALUES ('leonarda', 'scheldon'), ('scheldon', 'leonarda')`,
+		`INSERT INTO drafts (account, id, title, body, json_metadata) VALUES ('leonarda', 'draft', 'title', 'body', '{}')`,
+		`INSERT INTO push_tokens (account, token) VALUES ('leonarda', 'token')`,
+		`INSERT INTO downvotes (account, author, permlink, reason) VALUES ('scheldon', 'leonarda', 'post', 'spam')`,
+		`INSERT INTO suspensions (account, reason, suspended_by, created_at) VALUES ('leonarda', 'spam', 'sheldon', NOW())`,
+		`INSERT INTO operations_audit (account, op_type, payload, trx_id, digest, signatures, code, created_at)
+			VALUES ('leonarda', 'update_profile', '{"bio": "bio"}', 'trx', 'digest', '{}', 0, NOW())`,
+	} {
+		_, err := dbWrite.Exec(query)
+		require.NoError(t, err)
+	}
+
+	storage := NewAccountDataStorage(dbWrite)
+
+	count := func(data map[string]json.RawMessage, section string) int {
+		var rows []map[string]interface{}
+		require.NoError(t, json.Unmarshal(data[section], &rows))
+		return len(rows)
+	}
+
+	t.Run("export", func(t *testing.T) {
+		data, err := storage.Export(leonarda)
+		require.NoError(t, err)
+
+		require.Equal(t, 1, count(data, "profile"))
+		require.Equal(t, 1, count(data, "following"))
+		require.Equal(t, 1, count(data, "followers"))
+		require.Equal(t, 1, count(data, "drafts"))
+		require.Equal(t, 1, count(data, "push_tokens"))
+		// downvotes of the others are not the account data
+		require.Equal(t, 0, count(data, "downvotes"))
+		require.Equal(t, 1, count(data, "suspensions"))
+		require.Equal(t, 1, count(data, "operations_audit"))
+	})
+
+	t.Run("erase", func(t *testing.T) {
+		tx, err := dbWrite.Beginx()
+		require.NoError(t, err)
+
+		report, err := storage.InTx(tx).Erase(leonarda)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+
+		require.EqualValues(t, 1, report["profile"])
+		require.EqualValues(t, 1, report["following"])
+		require.EqualValues(t, 1, report["followers"])
+		require.EqualValues(t, 1, report["drafts"])
+		require.EqualValues(t, 1, report["push_tokens"])
+		require.EqualValues(t, 1, report["operations_audit"])
+
+		data, err := storage.Export(leonarda)
+		require.NoError(t, err)
+		require.Equal(t, 0, count(data, "drafts"))
+		require.Equal(t, 0, count(data, "followers"))
+		// the moderation records and the audit trail are kept
+		require.Equal(t, 1, count(data, "suspensions"))
+		require.Equal(t, 1, count(data, "operations_audit"))
+
+		var audit []map[string]interface{}
+		require.NoError(t, json.Unmarshal(data["operations_audit"], &audit))
+		require.Empty(t, audit[0]["payload"])
+
+		var rows []map[string]interface{}
+		require.NoError(t, json.Unmarshal(data["profile"], &rows))
+		require.Len(t, rows, 1)
+
+		profile := rows[0]
+		require.Equal(t, leonarda, profile["display_name"])
+		require.Equal(t, "", profile["bio"])
+		require.Equal(t, "", profile["website"])
+	})
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	// MutedList hides the posts of the account from the network feed and its replies from the notifications
+	MutedList AccountList = "muted"
+	// BlockedList forbids the account to follow and to notify
+	BlockedList AccountList = "blocked"
+)
+
+// AccountList is the personal list of the accounts kept by the user
+type AccountList string
+
+type AccountListEntry struct {
+	Account   string      `db:"account"`
+	Target    string      `db:"target"`
+	List      AccountList `db:"list"`
+	CreatedAt time.Time   `db:"created_at"`
+}
+
+// AccountListsStorage keeps the accounts muted and blocked by the users
+type AccountListsStorage struct {
+	db sqlx.Ext
+}
+
+func NewAccountListsStorage(db *sqlx.DB) *AccountListsStorage {
+	return &AccountListsStorage{db: db}
+}
+
+func (as *AccountListsStorage) InTx(tx *sqlx.Tx) *AccountListsStorage {
+	return &AccountListsStorage{db: tx}
+}
+
+// Add adds the target to the list of the account, adding the listed account does nothing
+func (as *AccountListsStorage) Add(entry AccountListEntry) error {
+	_, err := sqlx.NamedExec(as.db, `
+		INSERT INTO account_lists (account, target, list, created_at)
+		VALUES (:account, :target, :list, :created_at)
+		ON CONFLICT DO NOTHING`,
+		entry)
+	return err
+}
+
+// Remove removes the target from the list of the account
+func (as *AccountListsStorage) Remove(account, target string, list AccountList) error {
+	_, err := as.db.Exec(`DELETE FROM account_lists WHERE account = $1 AND target = $2 AND list = $3`,
+		account, target, list)
+	return err
+}
+
+// Contains checks whether the account has put the target into any of the lists
+func (as *AccountListsStorage) Contains(account, target string, lists ...AccountList) (bool, error) {
+	names := make([]string, len(lists))
+	for idx, l := range lists {
+		names[idx] = string(l)
+	}
+
+	var listed bool
+	err := sqlx.Get(as.db, &listed, `
+		SELECT EXISTS(SELECT * FROM account_lists WHERE account = $1 AND target = $2 AND list = ANY($3))`,
+		account, target, pq.Array(names))
+	return listed, err
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
+)
+
+// AuditEntry is the operation of the transaction processed by the TransactionRouter,
+// Code is the result code of the transaction, 0 if it has been accepted
+type AuditEntry struct {
+	ID         int64          `db:"id" json:"id"`
+	Account    string         `db:"account" json:"account"`
+	OpType     string         `db:"op_type" json:"op_type"`
+	Payload    types.JSONText `db:"payload" json:"payload"`
+	TrxID      string         `db:"trx_id" json:"trx_id"`
+	Digest     string         `db:"digest" json:"digest"`
+	Signatures pq.StringArray `db:"signatures" json:"signatures"`
+	Code       int            `db:"code" json:"code"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+}
+
+// AuditFilter filters the audit entries, zero fields are ignored
+type AuditFilter struct {
+	Account string
+	OpType  string
+	From    time.Time
+	To      time.Time
+	// Before is the keyset pagination cursor, only entries with the lower id are returned
+	Before int64
+	Limit  int
+}
+
+func (f AuditFilter) where() (string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if f.Account != "" {
+		add("account = $%d", f.Account)
+	}
+	if f.OpType != "" {
+		add("op_type = $%d", f.OpType)
+	}
+	if !f.From.IsZero() {
+		add("created_at >= $%d", f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		add("created_at < $%d", f.To.UTC())
+	}
+	if f.Before > 0 {
+		add("id < $%d", f.Before)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// AuditStorage keeps the operations processed by the TransactionRouter
+type AuditStorage struct {
+	db sqlx.Ext
+}
+
+func NewAuditStorage(db *sqlx.DB) *AuditStorage {
+	return &AuditStorage{db: db}
+}
+
+func (as *AuditStorage) InTx(tx *sqlx.Tx) *AuditStorage {
+	return &AuditStorage{db: tx}
+}
+
+// Add saves the audit entries, their ids are ignored
+func (as *AuditStorage) Add(entries ...AuditEntry) error {
+	for _, entry := range entries {
+		_, err := sqlx.NamedExec(as.db, `
+			INSERT INTO operations_audit (account, op_type, payload, trx_id, digest, signatures, code, created_at)
+			VALUES (:account, :op_type, :payload, :trx_id, :digest, :signatures, :code, :created_at)`,
+			entry)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find returns the filtered entries starting from the latest one
+func (as *AuditStorage) Find(filter AuditFilter) ([]*AuditEntry, error) {
+	where, args := filter.where()
+	args = append(args, filter.Limit)
+
+	var entries []*AuditEntry
+	err := sqlx.Select(as.db, &entries, fmt.Sprintf(`
+		SELECT * FROM operations_audit %s
+		ORDER BY id DESC
+		LIMIT $%d`, where, len(args)), args...)
+	return entries, err
+}
+
+// Export passes the filtered entries to f starting from the earliest one, the limit and the cursor are ignored
+func (as *AuditStorage) Export(filter AuditFilter, f func(entry *AuditEntry) error) error {
+	filter.Before = 0
+	where, args := filter.where()
+
+	rows, err := as.db.Queryx(fmt.Sprintf(`SELECT * FROM operations_audit %s ORDER BY id`, where), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry AuditEntry
+		if err := rows.StructScan(&entry); err != nil {
+			return err
+		}
+		if err := f(&entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// DeleteBefore removes the entries created before the given time
+func (as *AuditStorage) DeleteBefore(t time.Time) error {
+	_, err := as.db.Exec(`DELETE FROM operations_audit WHERE created_at < $1`, t.UTC())
+	return err
+}
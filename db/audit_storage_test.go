@@ -0,0 +1,80 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditStorage(t *testing.T) {
+	defer func() {
+		_, err := dbWrite.Exec("DELETE FROM operations_audit")
+		require.NoError(t, err)
+	}()
+
+	storage := NewAuditStorage(dbWrite)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	entry := func(account, opType string, createdAt time.Time) AuditEntry {
+		return AuditEntry{
+			Account:    account,
+			OpType:     opType,
+			Payload:    []byte(`{"account":"` + account + `"}`),
+			TrxID:      "trx",
+			Digest:     "digest",
+			Signatures: []string{"signature"},
+			CreatedAt:  createdAt,
+		}
+	}
+
+	require.NoError(t, storage.Add(
+		entry(leonarda, "follow", now.Add(-2*time.Hour)),
+		entry(sheldon, "follow", now.Add(-time.Hour)),
+		entry(leonarda, "unfollow", now),
+	))
+
+	t.Run("filter", func(t *testing.T) {
+		entries, err := storage.Find(AuditFilter{Account: leonarda, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		require.Equal(t, "unfollow", entries[0].OpType)
+		require.Equal(t, "follow", entries[1].OpType)
+		require.Equal(t, []string{"signature"}, []string(entries[0].Signatures))
+
+		entries, err = storage.Find(AuditFilter{OpType: "follow", From: now.Add(-90 * time.Minute), Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, sheldon, entries[0].Account)
+	})
+
+	t.Run("keyset", func(t *testing.T) {
+		page, err := storage.Find(AuditFilter{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+
+		next, err := storage.Find(AuditFilter{Before: page[1].ID, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, next, 1)
+		require.Equal(t, now.Add(-2*time.Hour), next[0].CreatedAt)
+	})
+
+	t.Run("export", func(t *testing.T) {
+		var exported []string
+		err := storage.Export(AuditFilter{To: now}, func(entry *AuditEntry) error {
+			exported = append(exported, entry.Account)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{leonarda, sheldon}, exported)
+	})
+
+	t.Run("retention", func(t *testing.T) {
+		require.NoError(t, storage.DeleteBefore(now.Add(-30*time.Minute)))
+
+		entries, err := storage.Find(AuditFilter{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, "unfollow", entries[0].OpType)
+	})
+}
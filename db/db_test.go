@@ -26,6 +26,9 @@ func cleanUp(t *testing.T) {
 	_, err = dbWrite.Exec("DELETE FROM profile_settings")
 	require.NoError(t, err)
 
+	_, err = dbWrite.Exec("DELETE FROM notification_preferences")
+	require.NoError(t, err)
+
 	_, err = dbWrite.Exec("DELETE FROM notifications")
 	require.NoError(t, err)
 
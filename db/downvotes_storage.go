@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 const (
@@ -51,6 +52,10 @@ func NewDownvotesStorage(db *sqlx.DB) *DownvotesStorage {
 	return &DownvotesStorage{db: db}
 }
 
+func (ds *DownvotesStorage) InTx(tx *sqlx.Tx) *DownvotesStorage {
+	return &DownvotesStorage{db: tx}
+}
+
 func (ds *DownvotesStorage) GetDownvotesForPost(permlink, author string) (map[string]*Downvote, error) {
 	var downvotes []*Downvote
 	err := sqlx.Select(ds.db, &downvotes, `
@@ -106,3 +111,33 @@ func (ds *DownvotesStorage) Delete(dw Downvote) error {
 
 	return nil
 }
+
+// CountByReasons returns the number of the post downvotes for each of the given reasons,
+// the reasons without downvotes are omitted
+func (ds *DownvotesStorage) CountByReasons(author, permlink string, reasons []DownvoteReason) (map[DownvoteReason]int, error) {
+	in := make(pq.StringArray, len(reasons))
+	for idx, r := range reasons {
+		in[idx] = string(r)
+	}
+
+	var counts []struct {
+		Reason DownvoteReason `db:"reason"`
+		Count  int            `db:"count"`
+	}
+	err := sqlx.Select(ds.db, &counts, `
+		SELECT reason, COUNT(*) AS count
+		FROM downvotes
+		WHERE author = $1 AND permlink = $2 AND reason::TEXT = ANY($3)
+		GROUP BY reason
+	`, author, permlink, in)
+	if err != nil {
+		return nil, err
+	}
+
+	countsMap := make(map[DownvoteReason]int, len(counts))
+	for _, c := range counts {
+		countsMap[c.Reason] = c.Count
+	}
+
+	return countsMap, nil
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"gitlab.scorum.com/blog/api/common"
@@ -26,11 +27,47 @@ type Profile struct {
 
 type ExtendedProfile struct {
 	Profile
+	ProfileExtra
 
 	FollowersCount int64 `db:"followers_count"`
 	FollowingCount int64 `db:"following_count"`
 }
 
+// ProfileExtra is the optional part of the profile
+type ProfileExtra struct {
+	Website    string      `db:"website"`
+	Links      SocialLinks `db:"social_links"`
+	Language   string      `db:"language"`
+	PinnedPost string      `db:"pinned_post"`
+}
+
+// ProfileHistoryEntry is the replaced version of the profile
+type ProfileHistoryEntry struct {
+	ID int64 `db:"id"`
+	Profile
+	ProfileExtra
+	ChangedAt time.Time `db:"changed_at"`
+}
+
+// SocialLinks are the profile links keyed by the social network, e.g. twitter
+type SocialLinks map[string]string
+
+func (sl SocialLinks) Value() (driver.Value, error) {
+	if sl == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(sl)
+}
+
+func (sl *SocialLinks) Scan(src interface{}) error {
+	source, ok := src.([]byte)
+	if !ok {
+		return errors.New("type assertion .([]byte) failed.")
+	}
+
+	return json.Unmarshal(source, sl)
+}
+
 type Media struct {
 	Account     string             `db:"account"`
 	ID          string             `db:"id"`
@@ -82,6 +119,47 @@ type PostID struct {
 	Permlink string `db:"permlink"`
 }
 
+const (
+	BlacklistReasonSpam           BlacklistReason = "spam"
+	BlacklistReasonPlagiarism     BlacklistReason = "plagiarism"
+	BlacklistReasonHateOrTrolling BlacklistReason = "hate_or_trolling"
+	BlacklistReasonIllegalContent BlacklistReason = "illegal_content"
+	BlacklistReasonCopyright      BlacklistReason = "copyright"
+	BlacklistReasonOther          BlacklistReason = "other"
+)
+
+var validBlacklistReasons = []BlacklistReason{
+	BlacklistReasonSpam,
+	BlacklistReasonPlagiarism,
+	BlacklistReasonHateOrTrolling,
+	BlacklistReasonIllegalContent,
+	BlacklistReasonCopyright,
+	BlacklistReasonOther,
+}
+
+type BlacklistReason string
+
+func (br BlacklistReason) IsValid() bool {
+	for _, r := range validBlacklistReasons {
+		if br == r {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BlacklistEntry blacklists the post or every post of the account if the permlink is empty
+type BlacklistEntry struct {
+	ID        int64           `db:"id"`
+	Account   string          `db:"account"`
+	Permlink  string          `db:"permlink"`
+	Reason    BlacklistReason `db:"reason"`
+	Note      string          `db:"note"`
+	AddedBy   sql.NullString  `db:"added_by"`
+	CreatedAt pq.NullTime     `db:"created_at"`
+}
+
 type Category struct {
 	Domain          string `db:"domain"`
 	Label           string `db:"label"`
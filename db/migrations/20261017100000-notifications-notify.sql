@@ -0,0 +1,18 @@
+-- +migrate Up
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION notifications_notify()
+  RETURNS TRIGGER AS $$
+BEGIN
+  PERFORM pg_notify('notifications', json_build_object('id', NEW.id, 'account', NEW.account)::TEXT);
+  RETURN NEW;
+END;
+$$ LANGUAGE 'plpgsql';
+-- +migrate StatementEnd
+
+CREATE TRIGGER notifications_inserted
+  AFTER INSERT ON notifications
+  FOR EACH ROW EXECUTE PROCEDURE notifications_notify();
+
+-- +migrate Down
+DROP TRIGGER notifications_inserted ON notifications;
+DROP FUNCTION notifications_notify();
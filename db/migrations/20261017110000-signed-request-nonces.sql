@@ -0,0 +1,12 @@
+-- +migrate Up
+CREATE TABLE signed_request_nonces (
+  account    ACCOUNT   NOT NULL,
+  salt       TEXT      NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (account, salt)
+);
+
+CREATE INDEX signed_request_nonces_expires_at_idx ON signed_request_nonces (expires_at);
+
+-- +migrate Down
+DROP TABLE signed_request_nonces;
@@ -0,0 +1,10 @@
+-- +migrate Up
+CREATE TABLE broadcasted_transactions (
+  digest     BYTEA     NOT NULL PRIMARY KEY,
+  expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX broadcasted_transactions_expires_at_idx ON broadcasted_transactions (expires_at);
+
+-- +migrate Down
+DROP TABLE broadcasted_transactions;
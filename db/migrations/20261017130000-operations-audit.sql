@@ -0,0 +1,19 @@
+-- +migrate Up
+CREATE TABLE operations_audit (
+  id         BIGSERIAL NOT NULL PRIMARY KEY,
+  account    TEXT      NOT NULL,
+  op_type    TEXT      NOT NULL,
+  payload    JSONB     NOT NULL,
+  trx_id     TEXT      NOT NULL,
+  digest     TEXT      NOT NULL,
+  signatures TEXT[]    NOT NULL,
+  code       INTEGER   NOT NULL,
+  created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX operations_audit_account_idx ON operations_audit (account, id);
+CREATE INDEX operations_audit_op_type_idx ON operations_audit (op_type, id);
+CREATE INDEX operations_audit_created_at_idx ON operations_audit (created_at);
+
+-- +migrate Down
+DROP TABLE operations_audit;
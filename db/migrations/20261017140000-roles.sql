@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE roles (
+  account    account REFERENCES profiles (account) ON DELETE CASCADE,
+  role       TEXT      NOT NULL,
+  -- domain scopes the category_editor role, it is empty for the rest of the roles
+  domain     TEXT      NOT NULL DEFAULT '' CHECK (domain = '' OR CAST(domain AS "domain") IS NOT NULL),
+  granted_by account,
+  created_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (account, role, domain)
+);
+
+-- +migrate Down
+DROP TABLE roles;
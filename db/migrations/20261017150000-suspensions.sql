@@ -0,0 +1,19 @@
+-- +migrate Up
+CREATE TABLE suspensions (
+  account      account REFERENCES profiles (account) ON DELETE CASCADE,
+  reason       TEXT      NOT NULL,
+  -- expires_at is null for the suspensions lasting until they are lifted
+  expires_at   TIMESTAMP NULL,
+  suspended_by account,
+  created_at   TIMESTAMP NOT NULL,
+  PRIMARY KEY (account)
+);
+
+-- expired suspensions are left in the table but ignored by the view
+CREATE VIEW active_suspensions AS
+  SELECT * FROM suspensions
+  WHERE expires_at IS NULL OR expires_at > now() AT TIME ZONE 'utc';
+
+-- +migrate Down
+DROP VIEW active_suspensions;
+DROP TABLE suspensions;
@@ -0,0 +1,21 @@
+-- +migrate Up
+-- empty permlink blacklists every post of the account,
+-- added_by and created_at are unknown for the entries added before
+ALTER TABLE blacklist
+  ADD COLUMN id         BIGSERIAL NOT NULL UNIQUE,
+  ADD COLUMN reason     TEXT      NOT NULL DEFAULT 'other',
+  ADD COLUMN note       TEXT      NOT NULL DEFAULT '',
+  ADD COLUMN added_by   TEXT      NULL,
+  ADD COLUMN created_at TIMESTAMP NULL;
+
+CREATE INDEX blacklist_reason_idx ON blacklist (reason, id);
+
+-- +migrate Down
+DROP INDEX blacklist_reason_idx;
+
+ALTER TABLE blacklist
+  DROP COLUMN id,
+  DROP COLUMN reason,
+  DROP COLUMN note,
+  DROP COLUMN added_by,
+  DROP COLUMN created_at;
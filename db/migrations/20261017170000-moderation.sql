@@ -0,0 +1,31 @@
+-- +migrate Up
+CREATE TABLE moderation_cases (
+  id         BIGSERIAL NOT NULL PRIMARY KEY,
+  author     TEXT      NOT NULL,
+  permlink   TEXT      NOT NULL,
+  -- domain is empty for the posts and comments created outside of the blog
+  domain     TEXT      NOT NULL DEFAULT '',
+  -- source is either 'downvotes' or 'plagiarism'
+  source     TEXT      NOT NULL,
+  reason     TEXT      NOT NULL,
+  -- status is 'open' until the case is resolved by the decision
+  status     TEXT      NOT NULL DEFAULT 'open',
+  opened_at  TIMESTAMP NOT NULL,
+  -- a post gets only one case per source, resolved cases are not reopened
+  UNIQUE (author, permlink, source)
+);
+
+CREATE INDEX moderation_cases_status_idx ON moderation_cases (status, domain, id);
+
+CREATE TABLE moderation_decisions (
+  case_id    BIGINT    NOT NULL REFERENCES moderation_cases (id) ON DELETE CASCADE,
+  decision   TEXT      NOT NULL,
+  moderator  TEXT      NOT NULL,
+  note       TEXT      NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (case_id)
+);
+
+-- +migrate Down
+DROP TABLE moderation_decisions;
+DROP TABLE moderation_cases;
@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX profiles_account_trgm_idx ON profiles USING GIN (CAST(account AS TEXT) gin_trgm_ops);
+CREATE INDEX profiles_display_name_trgm_idx ON profiles USING GIN (display_name gin_trgm_ops);
+CREATE INDEX profiles_about_fts_idx ON profiles USING GIN (to_tsvector('simple', bio || ' ' || location));
+
+-- followers count of the search results
+CREATE INDEX followers_follow_account_idx ON followers (follow_account);
+
+-- +migrate Down
+DROP INDEX followers_follow_account_idx;
+DROP INDEX profiles_about_fts_idx;
+DROP INDEX profiles_display_name_trgm_idx;
+DROP INDEX profiles_account_trgm_idx;
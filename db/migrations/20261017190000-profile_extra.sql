@@ -0,0 +1,34 @@
+-- +migrate Up
+ALTER TABLE profiles
+  ADD COLUMN website TEXT NOT NULL DEFAULT '',
+  ADD COLUMN social_links JSONB NOT NULL DEFAULT '{}',
+  ADD COLUMN language TEXT NOT NULL DEFAULT '',
+  ADD COLUMN pinned_post TEXT NOT NULL DEFAULT '';
+
+-- profile_history keeps the replaced versions of the profiles
+CREATE TABLE profile_history (
+  id           BIGSERIAL NOT NULL PRIMARY KEY,
+  account      ACCOUNT   NOT NULL REFERENCES profiles (account),
+  display_name TEXT      NOT NULL,
+  location     TEXT      NOT NULL,
+  bio          TEXT      NOT NULL,
+  avatar_url   TEXT      NOT NULL,
+  cover_url    TEXT      NOT NULL,
+  website      TEXT      NOT NULL,
+  social_links JSONB     NOT NULL,
+  language     TEXT      NOT NULL,
+  pinned_post  TEXT      NOT NULL,
+  -- changed_at is the time the version has been replaced
+  changed_at   TIMESTAMP NOT NULL
+);
+
+CREATE INDEX profile_history_account_idx ON profile_history (account, id);
+
+-- +migrate Down
+DROP TABLE profile_history;
+
+ALTER TABLE profiles
+  DROP COLUMN website,
+  DROP COLUMN social_links,
+  DROP COLUMN language,
+  DROP COLUMN pinned_post;
@@ -0,0 +1,25 @@
+-- +migrate Up
+-- notification_preferences holds the notification types the accounts have configured per channel,
+-- the missing rows mean the notifications are enabled
+CREATE TABLE notification_preferences (
+  account ACCOUNT           NOT NULL REFERENCES profiles (account),
+  type    NOTIFICATION_TYPE NOT NULL,
+  channel TEXT              NOT NULL CHECK (channel IN ('in_app', 'push', 'email')),
+  enabled BOOLEAN           NOT NULL,
+
+  PRIMARY KEY (account, type, channel)
+);
+
+-- quiet hours are the minutes of the day in the account time zone the push notifications are suppressed
+ALTER TABLE profile_settings
+  ADD COLUMN quiet_start SMALLINT CHECK (quiet_start BETWEEN 0 AND 1439),
+  ADD COLUMN quiet_end   SMALLINT CHECK (quiet_end BETWEEN 0 AND 1439),
+  ADD COLUMN time_zone   TEXT NOT NULL DEFAULT 'UTC';
+
+-- +migrate Down
+ALTER TABLE profile_settings
+  DROP COLUMN quiet_start,
+  DROP COLUMN quiet_end,
+  DROP COLUMN time_zone;
+
+DROP TABLE notification_preferences;
@@ -0,0 +1,15 @@
+-- +migrate Up
+-- account_lists keeps the accounts muted and blocked by the users
+CREATE TABLE account_lists (
+  account    ACCOUNT   NOT NULL REFERENCES profiles (account),
+  target     ACCOUNT   NOT NULL REFERENCES profiles (account),
+  list       TEXT      NOT NULL CHECK (list IN ('muted', 'blocked')),
+  created_at TIMESTAMP NOT NULL,
+
+  PRIMARY KEY (account, list, target)
+);
+
+CREATE INDEX account_lists_target_idx ON account_lists (target, account);
+
+-- +migrate Down
+DROP TABLE account_lists;
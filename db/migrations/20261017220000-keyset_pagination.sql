@@ -0,0 +1,28 @@
+-- +migrate Up
+-- the cursor paginated queries compare (created_at, key) rows, created_at can not be null there
+UPDATE followers SET created_at = 'epoch' WHERE created_at IS NULL;
+ALTER TABLE followers ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE deleted_posts SET created_at = 'epoch' WHERE created_at IS NULL;
+ALTER TABLE deleted_posts ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE profiles SET created_at = 'epoch' WHERE created_at IS NULL;
+ALTER TABLE profiles ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX followers_follow_account_created_at_idx ON followers (follow_account, created_at, account);
+CREATE INDEX followers_account_created_at_idx ON followers (account, created_at, follow_account);
+CREATE INDEX profiles_trusted_created_at_idx ON profiles (created_at, account) WHERE is_trusted;
+CREATE INDEX deleted_posts_created_at_idx ON deleted_posts (created_at, account, permlink);
+-- the legacy blacklist entries have no created_at, they go last
+CREATE INDEX blacklist_created_at_idx ON blacklist ((COALESCE(created_at, 'epoch')), id);
+
+-- +migrate Down
+DROP INDEX blacklist_created_at_idx;
+DROP INDEX deleted_posts_created_at_idx;
+DROP INDEX profiles_trusted_created_at_idx;
+DROP INDEX followers_account_created_at_idx;
+DROP INDEX followers_follow_account_created_at_idx;
+
+ALTER TABLE profiles ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE deleted_posts ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE followers ALTER COLUMN created_at DROP NOT NULL;
@@ -0,0 +1,18 @@
+-- +migrate Up
+-- follow_suggestions are the accounts suggested to follow per domain, recomputed by the background job
+CREATE TABLE follow_suggestions (
+  account     ACCOUNT   NOT NULL REFERENCES profiles (account),
+  domain      TEXT      NOT NULL,
+  suggestion  ACCOUNT   NOT NULL REFERENCES profiles (account),
+  score       REAL      NOT NULL,
+  -- reasons are friends, categories and trusted
+  reasons     TEXT []   NOT NULL,
+  computed_at TIMESTAMP NOT NULL,
+
+  PRIMARY KEY (account, domain, suggestion)
+);
+
+CREATE INDEX follow_suggestions_score_idx ON follow_suggestions (account, domain, score DESC);
+
+-- +migrate Down
+DROP TABLE follow_suggestions;
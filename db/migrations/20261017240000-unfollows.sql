@@ -0,0 +1,14 @@
+-- +migrate Up
+-- unfollows keep the removed follows so the follower history shows the net growth
+CREATE TABLE unfollows (
+  account        ACCOUNT   NOT NULL REFERENCES profiles (account),
+  follow_account ACCOUNT   NOT NULL REFERENCES profiles (account),
+  followed_at    TIMESTAMP NOT NULL,
+  unfollowed_at  TIMESTAMP NOT NULL
+);
+
+CREATE INDEX unfollows_follow_account_idx ON unfollows (follow_account, unfollowed_at);
+CREATE INDEX unfollows_followed_at_idx ON unfollows (follow_account, followed_at);
+
+-- +migrate Down
+DROP TABLE unfollows;
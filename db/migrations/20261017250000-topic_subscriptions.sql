@@ -0,0 +1,26 @@
+-- +migrate Up notransaction
+ALTER TYPE "notification_type" ADD VALUE IF NOT EXISTS 'category_posted';
+
+-- topic_subscriptions are the categories and the tags followed by the users,
+-- the categories are the localization keys of the categories table, the tags are lowercase
+CREATE TABLE topic_subscriptions (
+  account    ACCOUNT   NOT NULL REFERENCES profiles (account),
+  domain     "domain"  NOT NULL,
+  kind       TEXT      NOT NULL CHECK (kind IN ('category', 'tag')),
+  topic      TEXT      NOT NULL,
+  notify     BOOLEAN   NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP NOT NULL,
+
+  PRIMARY KEY (account, domain, kind, topic)
+);
+
+CREATE INDEX topic_subscriptions_topic_idx ON topic_subscriptions (domain, kind, topic);
+
+-- the network feed looks up the posts of the subscribed categories and the lowercased tags
+CREATE INDEX comments_categories_idx ON comments USING GIN ((json_metadata->'categories') jsonb_path_ops);
+CREATE INDEX comments_tags_idx ON comments USING GIN ((lower((json_metadata->'tags')::TEXT)::JSONB) jsonb_path_ops);
+
+-- +migrate Down
+DROP INDEX comments_tags_idx;
+DROP INDEX comments_categories_idx;
+DROP TABLE topic_subscriptions;
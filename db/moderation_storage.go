@@ -0,0 +1,190 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	ModerationSourceDownvotes  ModerationSource = "downvotes"
+	ModerationSourcePlagiarism ModerationSource = "plagiarism"
+)
+
+// ModerationSource is what opened the moderation case
+type ModerationSource string
+
+const (
+	ModerationStatusOpen            ModerationStatus = "open"
+	ModerationStatusBlacklisted     ModerationStatus = "blacklisted"
+	ModerationStatusDismissed       ModerationStatus = "dismissed"
+	ModerationStatusAuthorSuspended ModerationStatus = "author_suspended"
+)
+
+var validModerationStatuses = []ModerationStatus{
+	ModerationStatusOpen,
+	ModerationStatusBlacklisted,
+	ModerationStatusDismissed,
+	ModerationStatusAuthorSuspended,
+}
+
+type ModerationStatus string
+
+func (ms ModerationStatus) IsValid() bool {
+	for _, s := range validModerationStatuses {
+		if ms == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ModerationCase is the post or comment waiting for the moderator decision,
+// the decision fields are set once the case is resolved
+type ModerationCase struct {
+	ID        int64            `db:"id"`
+	Author    string           `db:"author"`
+	Permlink  string           `db:"permlink"`
+	Domain    string           `db:"domain"`
+	Source    ModerationSource `db:"source"`
+	Reason    string           `db:"reason"`
+	Status    ModerationStatus `db:"status"`
+	OpenedAt  time.Time        `db:"opened_at"`
+	Moderator sql.NullString   `db:"moderator"`
+	Note      sql.NullString   `db:"note"`
+	DecidedAt pq.NullTime      `db:"decided_at"`
+}
+
+// ModerationDecision is the resolution of the moderation case
+type ModerationDecision struct {
+	CaseID    int64            `db:"case_id"`
+	Decision  ModerationStatus `db:"decision"`
+	Moderator string           `db:"moderator"`
+	Note      string           `db:"note"`
+	CreatedAt time.Time        `db:"created_at"`
+}
+
+// ModerationFilter filters the moderation cases, zero fields are ignored
+type ModerationFilter struct {
+	Domain string
+	Status ModerationStatus
+	// Before is the keyset pagination cursor, only cases with the lower id are returned
+	Before int64
+	Limit  int
+}
+
+func (f ModerationFilter) where() (string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if f.Domain != "" {
+		add("c.domain = $%d", f.Domain)
+	}
+	if f.Status != "" {
+		add("c.status = $%d", f.Status)
+	}
+	if f.Before > 0 {
+		add("c.id < $%d", f.Before)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+const selectModerationCases = `
+	SELECT c.id, c.author, c.permlink, c.domain, c.source, c.reason, c.status, c.opened_at,
+	       d.moderator, d.note, d.created_at AS decided_at
+	FROM moderation_cases c
+	LEFT JOIN moderation_decisions d ON d.case_id = c.id`
+
+// ModerationStorage keeps the moderation queue
+type ModerationStorage struct {
+	db sqlx.Ext
+}
+
+func NewModerationStorage(db *sqlx.DB) *ModerationStorage {
+	return &ModerationStorage{db: db}
+}
+
+func (ms *ModerationStorage) InTx(tx *sqlx.Tx) *ModerationStorage {
+	return &ModerationStorage{db: tx}
+}
+
+// Open opens the case, returns false if the post already has a case from the same source
+func (ms *ModerationStorage) Open(c ModerationCase) (bool, error) {
+	rows, err := sqlx.NamedExec(ms.db, `
+		INSERT INTO moderation_cases (author, permlink, domain, source, reason, status, opened_at)
+		VALUES (:author, :permlink, :domain, :source, :reason, 'open', :opened_at)
+		ON CONFLICT (author, permlink, source) DO NOTHING`,
+		c)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := rows.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Get returns the case by id or nil if it does not exist
+func (ms *ModerationStorage) Get(id int64) (*ModerationCase, error) {
+	var c ModerationCase
+	err := sqlx.Get(ms.db, &c, selectModerationCases+` WHERE c.id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Find returns the filtered cases starting from the latest one
+func (ms *ModerationStorage) Find(filter ModerationFilter) ([]*ModerationCase, error) {
+	where, args := filter.where()
+	args = append(args, filter.Limit)
+
+	var cases []*ModerationCase
+	err := sqlx.Select(ms.db, &cases, fmt.Sprintf(`%s %s
+		ORDER BY c.id DESC
+		LIMIT $%d`, selectModerationCases, where, len(args)), args...)
+	return cases, err
+}
+
+// Resolve records the decision and closes the case, returns false if the case is not open
+func (ms *ModerationStorage) Resolve(decision ModerationDecision) (bool, error) {
+	rows, err := ms.db.Exec(`UPDATE moderation_cases SET status = $2 WHERE id = $1 AND status = 'open'`,
+		decision.CaseID, decision.Decision)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := rows.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		return false, nil
+	}
+
+	_, err = sqlx.NamedExec(ms.db, `
+		INSERT INTO moderation_decisions (case_id, decision, moderator, note, created_at)
+		VALUES (:case_id, :decision, :moderator, :note, :created_at)`,
+		decision)
+	return err == nil, err
+}
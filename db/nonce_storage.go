@@ -0,0 +1,42 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// NonceStorage keeps used salts of the signed requests, shared by all API instances
+type NonceStorage struct {
+	db sqlx.Ext
+}
+
+func NewNonceStorage(db *sqlx.DB) *NonceStorage {
+	return &NonceStorage{db: db}
+}
+
+// Use marks the salt of the account as used, returns false if it has been already used
+func (ns *NonceStorage) Use(account, salt string, expiresAt time.Time) (bool, error) {
+	// expired salt could be left by the cleanup, so it is replaced
+	res, err := ns.db.Exec(`
+		INSERT INTO signed_request_nonces(account, salt, expires_at) VALUES($1, $2, $3)
+		ON CONFLICT (account, salt) DO UPDATE SET expires_at = EXCLUDED.expires_at
+		WHERE signed_request_nonces.expires_at < now() AT TIME ZONE 'utc'`,
+		account, salt, expiresAt.UTC())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// DeleteExpired removes salts which can not be accepted anymore
+func (ns *NonceStorage) DeleteExpired() error {
+	_, err := ns.db.Exec(`DELETE FROM signed_request_nonces WHERE expires_at < now() AT TIME ZONE 'utc'`)
+	return err
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNonceStorage(t *testing.T) {
+	defer func() {
+		_, err := dbWrite.Exec("DELETE FROM signed_request_nonces")
+		require.NoError(t, err)
+	}()
+
+	storage := NewNonceStorage(dbWrite)
+
+	ok, err := storage.Use(leonarda, "1:a", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = storage.Use(leonarda, "1:a", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = storage.Use(sheldon, "1:a", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	t.Run("expired", func(t *testing.T) {
+		ok, err := storage.Use(leonarda, "2:b", time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// expired nonce could be used again
+		ok, err = storage.Use(leonarda, "2:b", time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.NoError(t, storage.DeleteExpired())
+
+		var count int
+		require.NoError(t, dbWrite.Get(&count, "SELECT COUNT(*) FROM signed_request_nonces"))
+		require.Equal(t, 2, count)
+	})
+}
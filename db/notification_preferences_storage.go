@@ -0,0 +1,197 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	InAppNotificationChannel NotificationChannel = "in_app"
+	PushNotificationChannel  NotificationChannel = "push"
+	EmailNotificationChannel NotificationChannel = "email"
+)
+
+var (
+	// NotificationChannels and NotificationTypes are the axes of the notification preferences
+	NotificationChannels = []NotificationChannel{
+		InAppNotificationChannel,
+		PushNotificationChannel,
+		EmailNotificationChannel,
+	}
+
+	NotificationTypes = []NotificationType{
+		StartedFollowNotificationType,
+		PostVotedNotificationType,
+		CommentVotedNotificationType,
+		PostFlaggedNotificationType,
+		CommentFlaggedNotificationType,
+		PostRepliedNotificationType,
+		CommentRepliedNotificationType,
+		PostUniquenessCheckedNotificationType,
+		CategoryPostedNotificationType,
+	}
+)
+
+// NotificationChannel is the way the notifications are delivered to the account
+type NotificationChannel string
+
+func (nc NotificationChannel) IsValid() bool {
+	for _, c := range NotificationChannels {
+		if nc == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (nt NotificationType) IsValid() bool {
+	for _, t := range NotificationTypes {
+		if nt == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+type NotificationPreference struct {
+	Account string              `db:"account"`
+	Type    NotificationType    `db:"type"`
+	Channel NotificationChannel `db:"channel"`
+	Enabled bool                `db:"enabled"`
+}
+
+// QuietHours are the minutes of the day in the time zone the push notifications are not sent,
+// the end is exclusive and may precede the start if the quiet hours span midnight
+type QuietHours struct {
+	Start    int16  `db:"quiet_start"`
+	End      int16  `db:"quiet_end"`
+	TimeZone string `db:"time_zone"`
+}
+
+// Contains checks whether the time falls into the quiet hours, the unknown time zone is treated as UTC
+func (qh QuietHours) Contains(t time.Time) bool {
+	loc, err := time.LoadLocation(qh.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	t = t.In(loc)
+	minute := int16(t.Hour()*60 + t.Minute())
+
+	if qh.Start <= qh.End {
+		return minute >= qh.Start && minute < qh.End
+	}
+	return minute >= qh.Start || minute < qh.End
+}
+
+// NotificationPreferencesStorage keeps the notification types the accounts have enabled or disabled per channel.
+// Everything not configured is enabled
+type NotificationPreferencesStorage struct {
+	db sqlx.Ext
+}
+
+func NewNotificationPreferencesStorage(db *sqlx.DB) *NotificationPreferencesStorage {
+	return &NotificationPreferencesStorage{db: db}
+}
+
+func (ns *NotificationPreferencesStorage) InTx(tx *sqlx.Tx) *NotificationPreferencesStorage {
+	return &NotificationPreferencesStorage{db: tx}
+}
+
+// Get returns the configured preferences of the account
+func (ns *NotificationPreferencesStorage) Get(account string) ([]NotificationPreference, error) {
+	var preferences []NotificationPreference
+	err := sqlx.Select(ns.db, &preferences, `
+		SELECT account, type, channel, enabled
+		FROM notification_preferences
+		WHERE account = $1
+		ORDER BY type, channel`, account)
+	return preferences, err
+}
+
+// Set upserts the preferences
+func (ns *NotificationPreferencesStorage) Set(preferences []NotificationPreference) error {
+	for _, preference := range preferences {
+		_, err := sqlx.NamedExec(ns.db, `
+			INSERT INTO notification_preferences (account, type, channel, enabled)
+			VALUES (:account, :type, :channel, :enabled)
+			ON CONFLICT (account, type, channel) DO UPDATE
+			SET enabled = :enabled`,
+			preference)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetQuietHours returns the quiet hours of the account or nil if it has none
+func (ns *NotificationPreferencesStorage) GetQuietHours(account string) (*QuietHours, error) {
+	var quietHours QuietHours
+	err := sqlx.Get(ns.db, &quietHours, `
+		SELECT quiet_start, quiet_end, time_zone
+		FROM profile_settings
+		WHERE account = $1 AND quiet_start IS NOT NULL AND quiet_end IS NOT NULL`, account)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &quietHours, nil
+}
+
+// SetQuietHours sets the quiet hours of the account, nil removes them
+func (ns *NotificationPreferencesStorage) SetQuietHours(account string, quietHours *QuietHours) error {
+	if quietHours == nil {
+		_, err := ns.db.Exec(`
+			UPDATE profile_settings SET quiet_start = NULL, quiet_end = NULL WHERE account = $1`, account)
+		return err
+	}
+
+	_, err := ns.db.Exec(`
+		INSERT INTO profile_settings (account, quiet_start, quiet_end, time_zone)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (account) DO UPDATE
+		SET quiet_start = $2, quiet_end = $3, time_zone = $4`,
+		account, quietHours.Start, quietHours.End, quietHours.TimeZone)
+	return err
+}
+
+// Allows checks whether the notification of the type may be delivered to the account via the channel at the time.
+// The email notifications also require the legacy enable_email_unseen_notifications setting,
+// the push notifications are suppressed during the quiet hours, they are dropped rather than delivered later
+func (ns *NotificationPreferencesStorage) Allows(account string, t NotificationType, channel NotificationChannel,
+	now time.Time) (bool, error) {
+	var enabled bool
+	err := sqlx.Get(ns.db, &enabled, `
+		SELECT COALESCE(
+			(SELECT enabled FROM notification_preferences WHERE account = $1 AND type = $2 AND channel = $3),
+			TRUE)`,
+		account, t, channel)
+	if err != nil || !enabled {
+		return false, err
+	}
+
+	switch channel {
+	case EmailNotificationChannel:
+		err = sqlx.Get(ns.db, &enabled, `
+			SELECT COALESCE(
+				(SELECT enable_email_unseen_notifications FROM profile_settings WHERE account = $1),
+				TRUE)`,
+			account)
+		return enabled, err
+	case PushNotificationChannel:
+		quietHours, err := ns.GetQuietHours(account)
+		if err != nil {
+			return false, err
+		}
+		return quietHours == nil || !quietHours.Contains(now), nil
+	}
+
+	return true, nil
+}
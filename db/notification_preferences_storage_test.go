@@ -0,0 +1,92 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuietHoursContains(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2018, 10, 17, hour, minute, 0, 0, time.UTC)
+	}
+
+	day := QuietHours{Start: 13 * 60, End: 14 * 60, TimeZone: "UTC"}
+	require.False(t, day.Contains(at(12, 59)))
+	require.True(t, day.Contains(at(13, 0)))
+	require.True(t, day.Contains(at(13, 59)))
+	require.False(t, day.Contains(at(14, 0)))
+
+	night := QuietHours{Start: 22 * 60, End: 7 * 60, TimeZone: "UTC"}
+	require.True(t, night.Contains(at(23, 30)))
+	require.True(t, night.Contains(at(3, 0)))
+	require.False(t, night.Contains(at(12, 0)))
+
+	// 21:00 UTC is 00:00 in Moscow
+	moscow := QuietHours{Start: 0, End: 60, TimeZone: "Europe/Moscow"}
+	require.True(t, moscow.Contains(at(21, 30)))
+	require.False(t, moscow.Contains(at(0, 30)))
+}
+
+func TestNotificationPreferencesStorageAllows(t *testing.T) {
+	defer cleanUp(t)
+
+	registerAccount(t, leonarda)
+
+	ps := NewNotificationPreferencesStorage(dbWrite)
+	now := time.Date(2018, 10, 17, 23, 0, 0, 0, time.UTC)
+
+	allows := func(nt NotificationType, channel NotificationChannel) bool {
+		allowed, err := ps.Allows(leonarda, nt, channel, now)
+		require.NoError(t, err)
+		return allowed
+	}
+
+	for _, channel := range NotificationChannels {
+		require.True(t, allows(PostVotedNotificationType, channel))
+	}
+
+	require.NoError(t, ps.Set([]NotificationPreference{
+		{Account: leonarda, Type: PostVotedNotificationType, Channel: PushNotificationChannel, Enabled: false},
+		{Account: leonarda, Type: PostFlaggedNotificationType, Channel: InAppNotificationChannel, Enabled: false},
+	}))
+
+	require.False(t, allows(PostVotedNotificationType, PushNotificationChannel))
+	require.True(t, allows(PostVotedNotificationType, InAppNotificationChannel))
+	require.True(t, allows(PostRepliedNotificationType, PushNotificationChannel))
+	require.False(t, allows(PostFlaggedNotificationType, InAppNotificationChannel))
+
+	t.Run("quiet_hours", func(t *testing.T) {
+		require.NoError(t, ps.SetQuietHours(leonarda, &QuietHours{Start: 22 * 60, End: 7 * 60, TimeZone: "UTC"}))
+		require.False(t, allows(PostRepliedNotificationType, PushNotificationChannel))
+		require.True(t, allows(PostRepliedNotificationType, InAppNotificationChannel))
+
+		require.NoError(t, ps.SetQuietHours(leonarda, nil))
+		require.True(t, allows(PostRepliedNotificationType, PushNotificationChannel))
+	})
+
+	t.Run("legacy_email", func(t *testing.T) {
+		_, err := dbWrite.Exec(
+			`UPDATE profile_settings SET enable_email_unseen_notifications = FALSE WHERE account = $1`, leonarda)
+		require.NoError(t, err)
+		require.False(t, allows(PostUniquenessCheckedNotificationType, EmailNotificationChannel))
+	})
+
+	t.Run("in_app_insert", func(t *testing.T) {
+		ns := NewNotificationsStorage(dbWrite)
+		for _, nt := range []NotificationType{PostFlaggedNotificationType, PostRepliedNotificationType} {
+			require.NoError(t, ns.Insert(Notification{
+				Account:   leonarda,
+				Timestamp: now,
+				Type:      nt,
+				Meta:      PostRelatedNotificationMeta{Account: sheldon, Permlink: "permlink"}.ToJson(),
+			}))
+		}
+
+		notifications, err := ns.GetNotifications(leonarda, 100)
+		require.NoError(t, err)
+		require.Len(t, notifications, 1)
+		require.Equal(t, PostRepliedNotificationType, notifications[0].Type)
+	})
+}
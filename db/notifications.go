@@ -22,8 +22,12 @@ const (
 	PostRepliedNotificationType           NotificationType = "post_replied"
 	CommentRepliedNotificationType        NotificationType = "comment_replied"
 	PostUniquenessCheckedNotificationType NotificationType = "post_uniqueness_checked"
+	CategoryPostedNotificationType        NotificationType = "category_posted"
 )
 
+// NotificationsChannel is a postgres channel notified about every inserted notification
+const NotificationsChannel = "notifications"
+
 type NotificationType string
 
 type Notification struct {
@@ -168,6 +172,18 @@ func (ns *NotificationStorage) GetNotifications(account string, limit int) ([]*N
 	return notifications, err
 }
 
+func (ns *NotificationStorage) Get(id uuid.UUID) (*Notification, error) {
+	var notification Notification
+	err := sqlx.Get(ns.ext, &notification, `
+			SELECT id, account, timestamp, is_read, is_seen, type, meta
+			FROM notifications
+			WHERE id = $1
+		`,
+		id)
+
+	return &notification, err
+}
+
 func (ns *NotificationStorage) MarkAllRead(account string) error {
 	_, err := ns.ext.Exec(`UPDATE notifications SET is_read = true WHERE account = $1`, account)
 	return err
@@ -184,12 +200,17 @@ func (ns *NotificationStorage) MarkRead(account string, id uuid.UUID) error {
 	return err
 }
 
+// Insert inserts the notification unless the account has disabled the in-app notifications of its type
 func (ns *NotificationStorage) Insert(notification Notification) error {
 	notification.ID = uuid.New()
 	_, err := sqlx.NamedExec(ns.ext,
 		`
 		INSERT INTO notifications(id, account, timestamp, type, meta)
-		VALUES(:id, :account, :timestamp, :type, :meta);
+		SELECT CAST(:id AS UUID), CAST(:account AS ACCOUNT), CAST(:timestamp AS TIMESTAMP),
+		       CAST(:type AS NOTIFICATION_TYPE), CAST(:meta AS JSONB)
+		WHERE NOT EXISTS(
+			SELECT * FROM notification_preferences
+			WHERE account = :account AND type = :type AND channel = 'in_app' AND NOT enabled);
 		`, notification)
 	return err
 }
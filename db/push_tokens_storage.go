@@ -3,7 +3,7 @@ package db
 import "github.com/jmoiron/sqlx"
 
 type PushTokensStorage struct {
-	db *sqlx.DB
+	db sqlx.Ext
 }
 
 func NewPushTokensStorage(db *sqlx.DB) *PushTokensStorage {
@@ -12,6 +12,10 @@ func NewPushTokensStorage(db *sqlx.DB) *PushTokensStorage {
 	}
 }
 
+func (pr *PushTokensStorage) InTx(tx *sqlx.Tx) *PushTokensStorage {
+	return &PushTokensStorage{db: tx}
+}
+
 func (pr *PushTokensStorage) Add(acc string, token string) error {
 	_, err := pr.db.Exec(
 		`INSERT INTO push_tokens (account, token)
@@ -28,7 +32,7 @@ func (pr *PushTokensStorage) Delete(acc string, token string) error {
 }
 
 func (pr *PushTokensStorage) GetTokensByAccount(acc string) (tokens []string, err error) {
-	err = pr.db.Select(&tokens,
+	err = sqlx.Select(pr.db, &tokens,
 		`SELECT token FROM push_tokens WHERE account = $1`, acc)
 	return
 }
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// RoleSuperadmin is granted every permission including granting and revoking roles
+	RoleSuperadmin Role = "superadmin"
+	// RoleModerator manages the blacklist
+	RoleModerator Role = "moderator"
+	// RoleCategoryEditor manages categories of the domain
+	RoleCategoryEditor Role = "category_editor"
+	// RoleTrustManager manages trusted accounts
+	RoleTrustManager Role = "trust_manager"
+)
+
+var validRoles = []Role{
+	RoleSuperadmin,
+	RoleModerator,
+	RoleCategoryEditor,
+	RoleTrustManager,
+}
+
+type Role string
+
+func (r Role) IsValid() bool {
+	for _, role := range validRoles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsScoped reports whether the role is granted for a particular domain
+func (r Role) IsScoped() bool {
+	return r == RoleCategoryEditor
+}
+
+type RoleGrant struct {
+	Account   string    `db:"account"`
+	Role      Role      `db:"role"`
+	Domain    string    `db:"domain"`
+	GrantedBy string    `db:"granted_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type RolesStorage struct {
+	db sqlx.Ext
+}
+
+func NewRolesStorage(db *sqlx.DB) *RolesStorage {
+	return &RolesStorage{db: db}
+}
+
+func (rs *RolesStorage) InTx(tx *sqlx.Tx) *RolesStorage {
+	return &RolesStorage{db: tx}
+}
+
+// Grant grants the role, granting the existing role does nothing
+func (rs *RolesStorage) Grant(grant RoleGrant) error {
+	_, err := sqlx.NamedExec(rs.db, `
+		INSERT INTO roles (account, role, domain, granted_by, created_at)
+		VALUES (:account, :role, :domain, :granted_by, :created_at)
+		ON CONFLICT DO NOTHING`,
+		grant)
+	return err
+}
+
+// Revoke revokes the role, returns false if the role has not been granted
+func (rs *RolesStorage) Revoke(account string, role Role, domain string) (bool, error) {
+	res, err := rs.db.Exec(`DELETE FROM roles WHERE account = $1 AND role = $2 AND domain = $3`,
+		account, role, domain)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+// HasRole reports whether the account is granted the role for the domain or the superadmin role
+func (rs *RolesStorage) HasRole(account string, role Role, domain string) (bool, error) {
+	var exists bool
+	err := sqlx.Get(rs.db, &exists, `
+		SELECT EXISTS(SELECT * FROM roles
+			WHERE account = $1 AND (role = $2 AND domain = $3 OR role = $4))`,
+		account, role, domain, RoleSuperadmin)
+	return exists, err
+}
+
+// GetRoles returns roles of the account, roles of all the accounts if the account is empty
+func (rs *RolesStorage) GetRoles(account string) ([]*RoleGrant, error) {
+	var grants []*RoleGrant
+	err := sqlx.Select(rs.db, &grants, `
+		SELECT account, role, domain, granted_by, created_at FROM roles
+		WHERE $1 = '' OR account = $1
+		ORDER BY account, role, domain`, account)
+	return grants, err
+}
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type FollowSuggestion struct {
+	Profile
+	Score float32 `db:"score"`
+	// Reasons are friends, categories and trusted
+	Reasons pq.StringArray `db:"reasons"`
+}
+
+// SuggestionsStorage keeps the precomputed follow suggestions
+type SuggestionsStorage struct {
+	db sqlx.Ext
+}
+
+func NewSuggestionsStorage(db *sqlx.DB) *SuggestionsStorage {
+	return &SuggestionsStorage{db: db}
+}
+
+func (ss *SuggestionsStorage) InTx(tx *sqlx.Tx) *SuggestionsStorage {
+	return &SuggestionsStorage{db: tx}
+}
+
+// Refresh replaces the suggestions of the account with at most limit best candidates in every domain.
+// The candidates are the friends of friends, the authors who posted lately to the categories of the posts
+// the account wrote or voted for and the trusted accounts. The followed, muted, suspended and blacklisted
+// accounts and the accounts blocking the account are left out. The candidates are suggested in the domains
+// they posted to
+func (ss *SuggestionsStorage) Refresh(account string, limit int, now time.Time) error {
+	if _, err := ss.db.Exec(`DELETE FROM follow_suggestions WHERE account = $1`, account); err != nil {
+		return err
+	}
+
+	_, err := ss.db.Exec(`
+		WITH following AS (
+			SELECT follow_account AS account FROM followers WHERE account = $1
+		), excluded AS (
+			SELECT account FROM following
+			UNION SELECT account FROM active_suspensions
+			UNION SELECT account FROM blacklist WHERE permlink = ''
+			UNION SELECT target FROM account_lists WHERE account = $1
+			UNION SELECT account FROM account_lists WHERE target = $1 AND list = 'blocked'
+		), friends AS (
+			SELECT f.follow_account AS candidate, COUNT(*) AS friends
+			FROM followers f INNER JOIN following ON f.account = following.account
+			GROUP BY f.follow_account
+		), interests AS (
+			SELECT DISTINCT c.domain, jsonb_array_elements_text(c.json_metadata->'categories') AS category
+			FROM comments c
+			WHERE c.parent_author IS NULL AND jsonb_typeof(c.json_metadata->'categories') = 'array' AND (c.author = $1 OR EXISTS(
+				SELECT * FROM posts_votes v WHERE v.account = $1 AND v.author = c.author AND v.permlink = c.permlink))
+		), authors AS (
+			SELECT c.domain, c.author AS candidate, COUNT(*) AS posts
+			FROM comments c INNER JOIN interests i
+				ON i.domain = c.domain AND c.json_metadata->'categories' @> jsonb_build_array(i.category)
+			WHERE c.parent_author IS NULL AND c.created_at > $3::TIMESTAMP - INTERVAL '90 days'
+			GROUP BY c.domain, c.author
+		), candidates AS (
+			SELECT candidate FROM friends
+			UNION SELECT candidate FROM authors
+			UNION SELECT account FROM profiles WHERE is_trusted
+		), scored AS (
+			SELECT CAST(d.domain AS TEXT) AS domain, c.candidate,
+				COALESCE(fr.friends, 0) + LN(1 + COALESCE(a.posts, 0)) + CASE WHEN p.is_trusted THEN 1 ELSE 0 END AS score,
+				array_remove(ARRAY[
+					CASE WHEN fr.friends > 0 THEN 'friends' END,
+					CASE WHEN a.posts > 0 THEN 'categories' END,
+					CASE WHEN p.is_trusted THEN 'trusted' END], NULL) AS reasons
+			FROM candidates c
+			INNER JOIN profiles p ON p.account = c.candidate
+			INNER JOIN LATERAL (
+				SELECT DISTINCT domain FROM comments
+				WHERE author = c.candidate AND parent_author IS NULL AND domain IS NOT NULL) d ON TRUE
+			LEFT JOIN friends fr ON fr.candidate = c.candidate
+			LEFT JOIN authors a ON a.candidate = c.candidate AND a.domain = d.domain
+			WHERE c.candidate <> $1 AND c.candidate NOT IN (SELECT account FROM excluded)
+		), ranked AS (
+			SELECT s.*, ROW_NUMBER() OVER (PARTITION BY domain ORDER BY score DESC, candidate) AS position
+			FROM scored s
+		)
+		INSERT INTO follow_suggestions (account, domain, suggestion, score, reasons, computed_at)
+		SELECT $1, domain, candidate, score, reasons, $3
+		FROM ranked
+		WHERE position <= $2`,
+		account, limit, now)
+	return err
+}
+
+// Get returns the best suggestions of the account in the domain skipping the accounts followed since the refresh
+func (ss *SuggestionsStorage) Get(account, domain string, limit uint32) ([]*FollowSuggestion, error) {
+	var suggestions []*FollowSuggestion
+	err := sqlx.Select(ss.db, &suggestions, `
+		SELECT p.account, p.display_name, p.location, p.bio, p.avatar_url, p.cover_url, p.created_at,
+			s.score, s.reasons
+		FROM follow_suggestions s INNER JOIN profiles p ON p.account = s.suggestion
+		WHERE s.account = $1 AND s.domain = $2
+			AND NOT EXISTS(SELECT * FROM followers f WHERE f.account = $1 AND f.follow_account = s.suggestion)
+		ORDER BY s.score DESC, s.suggestion
+		LIMIT $3`,
+		account, domain, limit)
+	return suggestions, err
+}
+
+// Computed checks whether the suggestions of the account have been computed at least once
+func (ss *SuggestionsStorage) Computed(account string) (bool, error) {
+	var computed bool
+	err := sqlx.Get(ss.db, &computed, `SELECT EXISTS(SELECT * FROM follow_suggestions WHERE account = $1)`, account)
+	return computed, err
+}
+
+// GetTrusted returns the trusted accounts who posted to the domain, the fallback for the accounts
+// registered after the last refresh, the accounts are left out as in Refresh
+func (ss *SuggestionsStorage) GetTrusted(account, domain string, limit uint32) ([]*FollowSuggestion, error) {
+	var suggestions []*FollowSuggestion
+	err := sqlx.Select(ss.db, &suggestions, `
+		SELECT p.account, p.display_name, p.location, p.bio, p.avatar_url, p.cover_url, p.created_at,
+			1 AS score, ARRAY['trusted'] AS reasons
+		FROM profiles p
+		WHERE p.is_trusted AND p.account <> $1
+			AND EXISTS(SELECT * FROM comments c WHERE c.author = p.account AND c.parent_author IS NULL AND CAST(c.domain AS TEXT) = $2)
+			AND NOT EXISTS(SELECT * FROM active_suspensions s WHERE s.account = p.account)
+			AND NOT EXISTS(SELECT * FROM blacklist b WHERE b.account = p.account AND b.permlink = '')
+			AND NOT EXISTS(SELECT * FROM followers f WHERE f.account = $1 AND f.follow_account = p.account)
+			AND NOT EXISTS(SELECT * FROM account_lists l WHERE l.account = $1 AND l.target = p.account)
+			AND NOT EXISTS(SELECT * FROM account_lists l WHERE l.account = p.account AND l.target = $1 AND l.list = 'blocked')
+		ORDER BY p.created_at DESC, p.account
+		LIMIT $3`,
+		account, domain, limit)
+	return suggestions, err
+}
+
+// Accounts returns the page of the accounts ordered by the name, used to iterate the accounts to refresh
+func (ss *SuggestionsStorage) Accounts(after string, limit int) ([]string, error) {
+	var accounts []string
+	err := sqlx.Select(ss.db, &accounts, `SELECT account FROM profiles WHERE account > $1 ORDER BY account LIMIT $2`,
+		after, limit)
+	return accounts, err
+}
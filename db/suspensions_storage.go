@@ -0,0 +1,66 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type Suspension struct {
+	Account     string      `db:"account"`
+	Reason      string      `db:"reason"`
+	ExpiresAt   pq.NullTime `db:"expires_at"`
+	SuspendedBy string      `db:"suspended_by"`
+	CreatedAt   time.Time   `db:"created_at"`
+}
+
+// SuspensionsStorage keeps account suspensions, the expired suspensions are ignored
+type SuspensionsStorage struct {
+	db sqlx.Ext
+}
+
+func NewSuspensionsStorage(db *sqlx.DB) *SuspensionsStorage {
+	return &SuspensionsStorage{db: db}
+}
+
+func (ss *SuspensionsStorage) InTx(tx *sqlx.Tx) *SuspensionsStorage {
+	return &SuspensionsStorage{db: tx}
+}
+
+// Suspend suspends the account replacing its previous suspension
+func (ss *SuspensionsStorage) Suspend(suspension Suspension) error {
+	_, err := sqlx.NamedExec(ss.db, `
+		INSERT INTO suspensions (account, reason, expires_at, suspended_by, created_at)
+		VALUES (:account, :reason, :expires_at, :suspended_by, :created_at)
+		ON CONFLICT (account) DO UPDATE
+		SET reason = :reason, expires_at = :expires_at, suspended_by = :suspended_by, created_at = :created_at`,
+		suspension)
+	return err
+}
+
+// Lift removes the suspension of the account, returns false if the account is not suspended
+func (ss *SuspensionsStorage) Lift(account string) (bool, error) {
+	var lifted bool
+	err := sqlx.Get(ss.db, &lifted, `
+		WITH deleted AS (DELETE FROM suspensions WHERE account = $1 RETURNING expires_at)
+		SELECT EXISTS(SELECT * FROM deleted WHERE expires_at IS NULL OR expires_at > now() AT TIME ZONE 'utc')`,
+		account)
+	return lifted, err
+}
+
+// Get returns the active suspension of the account or nil if the account is not suspended
+func (ss *SuspensionsStorage) Get(account string) (*Suspension, error) {
+	var suspension Suspension
+	err := sqlx.Get(ss.db, &suspension, `
+		SELECT account, reason, expires_at, suspended_by, created_at
+		FROM active_suspensions WHERE account = $1`, account)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &suspension, nil
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	// CategoryTopic is the category of the posts, the topic is the localization key of the category
+	CategoryTopic TopicKind = "category"
+	// TagTopic is the tag of the posts json metadata
+	TagTopic TopicKind = "tag"
+)
+
+// TopicKind is the kind of the topic the account subscribes to
+type TopicKind string
+
+type TopicSubscription struct {
+	Account   string    `db:"account"`
+	Domain    string    `db:"domain"`
+	Kind      TopicKind `db:"kind"`
+	Topic     string    `db:"topic"`
+	Notify    bool      `db:"notify"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// TopicSubscriptionsStorage keeps the categories and the tags followed by the users
+type TopicSubscriptionsStorage struct {
+	db sqlx.Ext
+}
+
+func NewTopicSubscriptionsStorage(db *sqlx.DB) *TopicSubscriptionsStorage {
+	return &TopicSubscriptionsStorage{db: db}
+}
+
+func (ts *TopicSubscriptionsStorage) InTx(tx *sqlx.Tx) *TopicSubscriptionsStorage {
+	return &TopicSubscriptionsStorage{db: tx}
+}
+
+// Subscribe adds the subscription, subscribing again updates the notify flag only
+func (ts *TopicSubscriptionsStorage) Subscribe(subscription TopicSubscription) error {
+	_, err := sqlx.NamedExec(ts.db, `
+		INSERT INTO topic_subscriptions (account, domain, kind, topic, notify, created_at)
+		VALUES (:account, :domain, :kind, :topic, :notify, :created_at)
+		ON CONFLICT (account, domain, kind, topic) DO UPDATE SET notify = :notify`,
+		subscription)
+	return err
+}
+
+func (ts *TopicSubscriptionsStorage) Unsubscribe(account, domain string, kind TopicKind, topic string) error {
+	_, err := ts.db.Exec(`DELETE FROM topic_subscriptions WHERE account = $1 AND domain = $2 AND kind = $3 AND topic = $4`,
+		account, domain, kind, topic)
+	return err
+}
+
+// Get returns the subscriptions of the account starting from the recent ones
+func (ts *TopicSubscriptionsStorage) Get(account string) ([]*TopicSubscription, error) {
+	var subscriptions []*TopicSubscription
+	err := sqlx.Select(ts.db, &subscriptions, `
+		SELECT account, domain, kind, topic, notify, created_at
+		FROM topic_subscriptions
+		WHERE account = $1
+		ORDER BY created_at DESC, domain, kind, topic`,
+		account)
+	return subscriptions, err
+}
+
+// NotifiedSubscribers returns the accounts to notify about the new post of the author in any of the categories,
+// the author and the accounts muted or blocked the author are skipped
+func (ts *TopicSubscriptionsStorage) NotifiedSubscribers(domain string, categories []string, author string) ([]string, error) {
+	var accounts []string
+	err := sqlx.Select(ts.db, &accounts, `
+		SELECT DISTINCT s.account
+		FROM topic_subscriptions s
+		WHERE s.domain = $1 AND s.kind = $2 AND s.topic = ANY($3) AND s.notify AND s.account <> $4
+			AND NOT EXISTS(
+				SELECT * FROM account_lists l
+				WHERE l.account = s.account AND l.target = $4 AND l.list IN ('muted', 'blocked'))
+		ORDER BY s.account`,
+		domain, CategoryTopic, pq.Array(categories), author)
+	return accounts, err
+}
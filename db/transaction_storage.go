@@ -0,0 +1,46 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TransactionStorage keeps digests of the accepted transactions until they expire
+type TransactionStorage struct {
+	db sqlx.Ext
+}
+
+func NewTransactionStorage(db *sqlx.DB) *TransactionStorage {
+	return &TransactionStorage{db: db}
+}
+
+func (ts *TransactionStorage) InTx(tx *sqlx.Tx) *TransactionStorage {
+	return &TransactionStorage{db: tx}
+}
+
+// Add saves the transaction digest, returns false if the transaction has been already accepted
+func (ts *TransactionStorage) Add(digest []byte, expiresAt time.Time) (bool, error) {
+	// expired digest could be left by the cleanup, so it is replaced
+	res, err := ts.db.Exec(`
+		INSERT INTO broadcasted_transactions(digest, expires_at) VALUES($1, $2)
+		ON CONFLICT (digest) DO UPDATE SET expires_at = EXCLUDED.expires_at
+		WHERE broadcasted_transactions.expires_at < now() AT TIME ZONE 'utc'`,
+		digest, expiresAt.UTC())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// DeleteExpired removes digests of the transactions which can not be accepted anymore
+func (ts *TransactionStorage) DeleteExpired() error {
+	_, err := ts.db.Exec(`DELETE FROM broadcasted_transactions WHERE expires_at < now() AT TIME ZONE 'utc'`)
+	return err
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionStorage(t *testing.T) {
+	defer func() {
+		_, err := dbWrite.Exec("DELETE FROM broadcasted_transactions")
+		require.NoError(t, err)
+	}()
+
+	storage := NewTransactionStorage(dbWrite)
+
+	ok, err := storage.Add([]byte("digest1"), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = storage.Add([]byte("digest1"), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	t.Run("rolled_back", func(t *testing.T) {
+		tx, err := dbWrite.Beginx()
+		require.NoError(t, err)
+
+		ok, err := storage.InTx(tx).Add([]byte("digest2"), time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.NoError(t, tx.Rollback())
+
+		ok, err = storage.Add([]byte("digest2"), time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		ok, err := storage.Add([]byte("digest3"), time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// expired transaction digest could be added again
+		ok, err = storage.Add([]byte("digest3"), time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.NoError(t, storage.DeleteExpired())
+
+		var count int
+		require.NoError(t, dbWrite.Get(&count, `SELECT COUNT(*) FROM broadcasted_transactions`))
+		require.Equal(t, 2, count)
+	})
+}
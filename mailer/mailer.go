@@ -1,6 +1,8 @@
 package mailer
 
 import (
+	"time"
+
 	"github.com/nsqio/go-nsq"
 	"gitlab.scorum.com/blog/api/db"
 )
@@ -10,21 +12,30 @@ const (
 )
 
 type Client struct {
-	producer *nsq.Producer
+	producer    *nsq.Producer
+	preferences *db.NotificationPreferencesStorage
 }
 
-func NewClient(nsqdAddress string) (*Client, error) {
+func NewClient(nsqdAddress string, preferences *db.NotificationPreferencesStorage) (*Client, error) {
 	producer, err := nsq.NewProducer(nsqdAddress, nsq.NewConfig())
 	if err != nil {
 		return nil, err
 	}
 
-	return &Client{producer: producer}, producer.Ping()
+	return &Client{producer: producer, preferences: preferences}, producer.Ping()
 }
 
+// SendPlagiarismEmail queues the email unless the author has disabled the emails about the uniqueness checks
 func (s *Client) SendPlagiarismEmail(meta db.PlagiarismRelatedNotificationMeta) error {
-	if s.producer != nil {
-		return s.producer.Publish(PlagiarismTopicName, meta.ToJson())
+	if s.producer == nil {
+		return nil
+	}
+
+	allowed, err := s.preferences.Allows(meta.Account, db.PostUniquenessCheckedNotificationType,
+		db.EmailNotificationChannel, time.Now())
+	if err != nil || !allowed {
+		return err
 	}
-	return nil
+
+	return s.producer.Publish(PlagiarismTopicName, meta.ToJson())
 }
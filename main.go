@@ -13,7 +13,7 @@ import (
 
 	"github.com/jinzhu/configor"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/mongodb/mongo-go-driver/core/connstring"
 	"github.com/scorum/event-provider-go/provider"
 	"github.com/scorum/scorum-go"
@@ -26,9 +26,11 @@ import (
 	"gitlab.scorum.com/blog/api/db"
 	"gitlab.scorum.com/blog/api/domainprovider"
 	"gitlab.scorum.com/blog/api/mailer"
+	"gitlab.scorum.com/blog/api/metrics"
 	"gitlab.scorum.com/blog/api/push"
 	"gitlab.scorum.com/blog/api/rpc"
 	"gitlab.scorum.com/blog/api/service"
+	"gitlab.scorum.com/blog/api/subscription"
 	"gitlab.scorum.com/blog/core/locale"
 	"gitlab.scorum.com/blog/core/sentry"
 )
@@ -47,6 +49,24 @@ type BlockchainConfig struct {
 
 type RouterConfig struct {
 	MaxRequestSize int64 `yaml:"max_request_size" default:"25000000"` // 25 Megabytes
+	MaxBatchSize   int   `yaml:"max_batch_size" default:"20"`
+	// SaltWindow is the acceptance window of the signed request salt timestamp
+	SaltWindow time.Duration `yaml:"salt_window" default:"5m"`
+	// RequireTimestampedSalt rejects signed requests with the legacy salts without the timestamp,
+	// the legacy salts are accepted by default to keep the existing clients working
+	RequireTimestampedSalt bool `yaml:"require_timestamped_salt"`
+	// NonceStore is either "postgres" or "memory" for single node deployments
+	NonceStore string `yaml:"nonce_store" default:"postgres"`
+	// KeyCacheSize is the maximum number of accounts in the authorities cache, 0 disables the cache
+	KeyCacheSize int `yaml:"key_cache_size" default:"10000"`
+	// KeyCacheTTL is the lifetime of the cached account authorities
+	KeyCacheTTL time.Duration `yaml:"key_cache_ttl" default:"10m"`
+	// KeyCacheNegativeTTL is the lifetime of the cached unknown accounts
+	KeyCacheNegativeTTL time.Duration `yaml:"key_cache_negative_ttl" default:"30s"`
+	// MaxTransactionExpiration is the maximum time until the broadcasted transaction expiration
+	MaxTransactionExpiration time.Duration `yaml:"max_transaction_expiration" default:"1h"`
+	// CheckRefBlock validates the reference block of the broadcasted transactions against the blockchain
+	CheckRefBlock bool `yaml:"check_ref_block"`
 }
 
 type DBConfig struct {
@@ -130,6 +150,9 @@ func main() {
 	dbRead.SetMaxIdleConns(10)
 	dbRead.SetMaxOpenConns(10)
 
+	metrics.RegisterDBStats("write", dbWrite.DB)
+	metrics.RegisterDBStats("read", dbRead.DB)
+
 	transport := bct.NewTransport(config.Blockchain.HTTP)
 	blockchain := scorumgo.NewClient(transport)
 
@@ -142,6 +165,9 @@ func main() {
 		writer.Write([]byte("ok"))
 	})
 
+	// metrics
+	http.Handle("/metrics", metrics.Handler())
+
 	// version
 	http.HandleFunc("/version", func(writer http.ResponseWriter, _ *http.Request) {
 		writer.Write([]byte(version))
@@ -184,49 +210,140 @@ func main() {
 		log.Fatalf("failed to create domain provider: %s", err)
 	}
 
+	notificationPreferences := db.NewNotificationPreferencesStorage(dbWrite)
+
 	// push notifier
 	notifier := push.NewNotifier(
 		pusher, localizer, domainProvider,
-		db.NewPushTokensStorage(dbRead))
+		db.NewPushTokensStorage(dbRead), notificationPreferences)
 
 	blog := &service.Blog{
 		DB: service.Database{
 			Write: dbWrite,
 			Read:  dbRead,
 		},
-		Blockchain:              blockchain,
-		Blob:                    blob.NewService(config.Blob),
-		Config:                  config.Service,
-		Notifier:                notifier,
-		PushRegistrationStorage: db.NewPushTokensStorage(dbWrite),
-		NotificationStorage:     db.NewNotificationsStorage(dbWrite),
-		DownvotesStorage:        db.NewDownvotesStorage(dbWrite),
+		Blockchain:                     blockchain,
+		Blob:                           blob.NewService(config.Blob),
+		Config:                         config.Service,
+		Notifier:                       notifier,
+		PushRegistrationStorage:        db.NewPushTokensStorage(dbWrite),
+		NotificationStorage:            db.NewNotificationsStorage(dbWrite),
+		DownvotesStorage:               db.NewDownvotesStorage(dbWrite),
+		AuditStorage:                   db.NewAuditStorage(dbWrite),
+		RolesStorage:                   db.NewRolesStorage(dbWrite),
+		SuspensionsStorage:             db.NewSuspensionsStorage(dbWrite),
+		ModerationStorage:              db.NewModerationStorage(dbWrite),
+		AccountDataStorage:             db.NewAccountDataStorage(dbWrite),
+		NotificationPreferencesStorage: notificationPreferences,
+		AccountListsStorage:            db.NewAccountListsStorage(dbWrite),
+		SuggestionsStorage:             db.NewSuggestionsStorage(dbWrite),
+		TopicSubscriptionsStorage:      db.NewTopicSubscriptionsStorage(dbWrite),
+		Subscriptions:                  subscription.NewHub(),
+	}
+
+	// notifications listener pushes notifications to the websocket subscribers
+	listener := pq.NewListener(config.DB.Write, 10*time.Second, time.Minute, nil)
+	if err := listener.Listen(db.NotificationsChannel); err != nil {
+		log.Fatalf("failed to listen notifications: %s", err)
+	}
+	go blog.ListenNotifications(ctx, listener)
+
+	// used salts of the signed requests
+	var nonces rpc.NonceStore
+	switch config.Router.NonceStore {
+	case "memory":
+		nonces = rpc.NewMemoryNonceStore()
+	case "postgres":
+		nonces = db.NewNonceStorage(dbWrite)
+	default:
+		log.Fatalf("unknown nonce store: %s", config.Router.NonceStore)
+	}
+	// accepted transactions kept to reject duplicated broadcasts
+	transactions := db.NewTransactionStorage(dbWrite)
+
+	// remove expired salts and transactions once in a minute
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		for range ticker.C {
+			if err := nonces.DeleteExpired(); err != nil {
+				log.WithError(err).Error("failed to delete expired nonces")
+			}
+			if err := transactions.DeleteExpired(); err != nil {
+				log.WithError(err).Error("failed to delete expired transactions")
+			}
+		}
+	}()
+
+	// remove audited operations older than the retention once in an hour
+	if config.Service.AuditRetention > 0 {
+		go func() {
+			ticker := time.NewTicker(time.Hour)
+			for range ticker.C {
+				if err := blog.AuditStorage.DeleteBefore(time.Now().Add(-config.Service.AuditRetention)); err != nil {
+					log.WithError(err).Error("failed to delete audited operations")
+				}
+			}
+		}()
+	}
+
+	// recompute the follow suggestions in the background so the suggestions api stays cheap
+	if config.Service.Suggestions.RefreshInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(config.Service.Suggestions.RefreshInterval)
+			for range ticker.C {
+				if err := blog.RefreshSuggestions(); err != nil {
+					log.WithError(err).Error("failed to refresh follow suggestions")
+				}
+			}
+		}()
+	}
+
+	// account authorities cache shared by the signed requests and the broadcasts
+	keyCache := rpc.NewKeyCache(rpc.NewBlockchainAuthorityProvider(blockchain),
+		config.Router.KeyCacheSize, config.Router.KeyCacheTTL, config.Router.KeyCacheNegativeTTL)
+
+	// the cache is local to the instance so every instance watches the authority updates
+	if config.Router.KeyCacheSize > 0 {
+		watcher := &blockchain_monitor.OperationsWatcher{
+			Blockchain: blockchain,
+			Interval:   config.Blockchain.SyncInterval,
+		}
+		for _, opType := range blockchain_monitor.AuthorityOpTypes {
+			watcher.Handle(opType, blockchain_monitor.InvalidateAuthorities(keyCache))
+		}
+		go watcher.Watch(ctx)
 	}
 
 	// rpc handler
-	router := configureRPCRouter(&config, blockchain, blog, antiPlagiarism)
+	router := configureRPCRouter(&config, blockchain, blog, antiPlagiarism, nonces, keyCache, transactions)
 	http.HandleFunc("/", router.Handle)
+	http.Handle("/ws", router.HandleWebSocket())
 	http.HandleFunc("/unsubscribe", blog.UnsubscribeEndpoint)
 
 	if config.BlockchainMonitorEnabled {
 		log.Info("blockchain monitor enabled")
 
-		mailer, err := mailer.NewClient(config.NSQDAddress)
+		mailer, err := mailer.NewClient(config.NSQDAddress, notificationPreferences)
 		if err != nil {
 			log.Warnf("can't create nsq client err:%s", err)
 		}
 
 		monitor := blockchain_monitor.BlockchainMonitor{
 			DB:              dbWrite,
+			Blockchain:      blockchain,
 			CommentsStorage: db.NewCommentsStorage(dbWrite),
 			Provider: provider.NewProvider(config.Blockchain.HTTP,
 				provider.SyncInterval(config.Blockchain.SyncInterval)),
-			Plagiarism:          antiPlagiarism,
-			NotificationStorage: db.NewNotificationsStorage(dbWrite),
-			PushNotifier:        notifier,
-			DownvotesStorage:    db.NewDownvotesStorage(dbWrite),
-			PlagiarismStorage:   db.NewPlagiarismStorage(dbWrite),
-			MailerClient:        mailer,
+			Plagiarism:                antiPlagiarism,
+			NotificationStorage:       db.NewNotificationsStorage(dbWrite),
+			PushNotifier:              notifier,
+			DownvotesStorage:          db.NewDownvotesStorage(dbWrite),
+			PlagiarismStorage:         db.NewPlagiarismStorage(dbWrite),
+			ModerationStorage:         db.NewModerationStorage(dbWrite),
+			AccountListsStorage:       db.NewAccountListsStorage(dbWrite),
+			TopicSubscriptionsStorage: db.NewTopicSubscriptionsStorage(dbWrite),
+			MailerClient:              mailer,
+			UniquenessThreshold:       config.Service.Moderation.UniquenessThreshold,
 		}
 
 		go monitor.Monitor(ctx)
@@ -246,40 +363,72 @@ func main() {
 	log.Infof("app closed with signal: %s", s)
 }
 
-func configureRPCRouter(config *Config, blockchain *scorumgo.Client, blog *service.Blog, ap *service.AntiPlagiarism) *rpc.Router {
+func configureRPCRouter(config *Config, blockchain *scorumgo.Client, blog *service.Blog, ap *service.AntiPlagiarism,
+	nonces rpc.NonceStore, keyCache *rpc.KeyCache, transactions *db.TransactionStorage) *rpc.Router {
 	verifier := rpc.NewVerifier(config.Blockchain.ChainID)
-	transactionRouter := broadcast.NewTransactionRouter(blockchain, verifier)
+	transactionRouter := broadcast.NewTransactionRouter(blog.DB.Write, blockchain, verifier)
+	transactionRouter.Authorities = keyCache
+	transactionRouter.Transactions = transactions
+	transactionRouter.Audit = blog.AuditStorage
+	transactionRouter.MaxExpiration = config.Router.MaxTransactionExpiration
+	transactionRouter.CheckRefBlock = config.Router.CheckRefBlock
+	transactionRouter.Use(broadcast.Logging)
 
 	// rpc routes
-	rpcRouter := rpc.NewRouter(blockchain, verifier, config.Router.MaxRequestSize)
+	rpcRouter := rpc.NewRouter(blockchain, verifier, config.Router.MaxRequestSize, config.Router.MaxBatchSize)
+	rpcRouter.SetAuthorityProvider(keyCache)
+	rpcRouter.Use(rpc.Instrument, rpc.Logging)
+	rpcRouter.EnableReplayProtection(nonces, config.Router.SaltWindow, !config.Router.RequireTimestampedSalt)
 	rpcRouter.Register(rpc.Route{"account_api", "get_profile"}, blog.GetProfile)
 	rpcRouter.Register(rpc.Route{"account_api", "get_profiles"}, blog.GetProfiles)
+	rpcRouter.Register(rpc.Route{"account_api", "search_profiles"}, blog.SearchProfiles)
+	rpcRouter.Register(rpc.Route{"account_api", "get_profile_history"}, rpcRouter.SignedAPI(blog.GetProfileHistory))
 	rpcRouter.Register(rpc.Route{"account_api", "get_profile_settings"}, rpcRouter.SignedAPI(blog.GetProfileSettings))
+	rpcRouter.Register(rpc.Route{"account_api", "get_muted"}, rpcRouter.SignedAPI(blog.GetMuted))
+	rpcRouter.Register(rpc.Route{"account_api", "get_blocked"}, rpcRouter.SignedAPI(blog.GetBlocked))
+	rpcRouter.Register(rpc.Route{"account_api", "get_subscriptions"}, rpcRouter.SignedAPI(blog.GetSubscriptions))
 	rpcRouter.Register(rpc.Route{"account_api", "is_trusted"}, blog.IsAccountTrusted)
 	rpcRouter.Register(rpc.Route{"account_api", "get_trusted"}, blog.GetTrusted)
+	rpcRouter.Register(rpc.Route{"account_api", "get_trusted_page"}, blog.GetTrustedPage)
 	rpcRouter.Register(rpc.Route{"media_api", "get_media"}, blog.GetMedia)
 	rpcRouter.Register(rpc.Route{"category_api", "get_categories"}, blog.GetCategories)
 	rpcRouter.Register(rpc.Route{"category_api", "get_category"}, blog.GetCategory)
 	rpcRouter.Register(rpc.Route{"follow_api", "get_followers"}, blog.GetFollowers)
+	rpcRouter.Register(rpc.Route{"follow_api", "get_followers_page"}, blog.GetFollowersPage)
 	rpcRouter.Register(rpc.Route{"follow_api", "get_following"}, blog.GetFollowing)
+	rpcRouter.Register(rpc.Route{"follow_api", "get_following_page"}, blog.GetFollowingPage)
 	rpcRouter.Register(rpc.Route{"follow_api", "filter_followers"}, blog.FilterFollowers)
 	rpcRouter.Register(rpc.Route{"follow_api", "filter_following"}, blog.FilterFollowing)
+	rpcRouter.Register(rpc.Route{"follow_api", "get_suggestions"}, blog.GetSuggestions)
+	rpcRouter.Register(rpc.Route{"follow_api", "get_relationships"}, blog.GetRelationships)
+	rpcRouter.Register(rpc.Route{"follow_api", "get_mutual_followers"}, blog.GetMutualFollowers)
+	rpcRouter.Register(rpc.Route{"follow_api", "get_follower_history"}, blog.GetFollowerHistory)
 	rpcRouter.Register(rpc.Route{"blacklist_api", "is_blacklisted"}, blog.IsBlacklisted)
 	rpcRouter.Register(rpc.Route{"blacklist_api", "get_blacklist"}, blog.GetBlacklist)
+	rpcRouter.Register(rpc.Route{"blacklist_api", "get_blacklist_page"}, blog.GetBlacklistPage)
 	rpcRouter.Register(rpc.Route{"draft_api", "get_draft"}, rpcRouter.SignedAPI(blog.GetDraft))
 	rpcRouter.Register(rpc.Route{"draft_api", "get_drafts"}, rpcRouter.SignedAPI(blog.GetDrafts))
 	rpcRouter.Register(rpc.Route{"notification_api", "get_notifications"}, rpcRouter.SignedAPI(blog.GetNotifications))
+	rpcRouter.Register(rpc.Route{"notification_api", "subscribe"}, rpcRouter.SignedAPI(blog.SubscribeNotifications))
+	rpcRouter.Register(rpc.Route{"notification_api", "unsubscribe"}, rpcRouter.SignedAPI(blog.UnsubscribeNotifications))
 	rpcRouter.Register(rpc.Route{"post_api", "is_post_deleted"}, blog.IsPostDeleted)
 	rpcRouter.Register(rpc.Route{"post_api", "get_deleted_posts"}, blog.GetDeletedPosts)
+	rpcRouter.Register(rpc.Route{"post_api", "get_deleted_posts_page"}, blog.GetDeletedPostsPage)
 	rpcRouter.Register(rpc.Route{"post_api", "get_votes"}, blog.GetVotesForPostEndpoint)
 	rpcRouter.Register(rpc.Route{"post_api", "get_plagiarism_check_details"}, ap.GetCheckResultEndpoint)
 	rpcRouter.Register(rpc.Route{"post_api", "get_from_network"}, blog.GetPostsFromNetwork)
 	rpcRouter.Register(rpc.Route{"post_api", "get_downvotes"}, blog.Downvotes)
+	rpcRouter.Register(rpc.Route{"admin_api", "get_roles"}, rpcRouter.SignedAPI(blog.GetRoles))
+	rpcRouter.Register(rpc.Route{"admin_api", "export_account_data"}, rpcRouter.SignedAPI(blog.ExportAccountDataEndpoint))
+	rpcRouter.Register(rpc.Route{"audit_api", "get_operations"}, rpcRouter.SignedAPI(blog.GetAuditOperations))
+	rpcRouter.Register(rpc.Route{"moderation_api", "get_cases"}, rpcRouter.SignedAPI(blog.GetModerationCases))
 
 	// all transaction are going through network_broadcast_api
 	// redirect them to the transaction router
 	rpcRouter.Register(rpc.Route{"network_broadcast_api", "broadcast_transaction_synchronous"},
 		transactionRouter.Route)
+	rpcRouter.Register(rpc.Route{"network_broadcast_api", "validate_transaction"},
+		transactionRouter.ValidateTransaction)
 
 	// transaction routes
 	transactionRouter.Register(types.RegisterOpType, blog.Register)
@@ -302,6 +451,17 @@ func configureRPCRouter(config *Config, blockchain *scorumgo.Client, blog *servi
 	transactionRouter.Register(types.UpdateProfileSettingsOpType, blog.UpdateProfileSettings)
 	transactionRouter.Register(types.DownvoteOpType, blog.Downvote)
 	transactionRouter.Register(types.RemoveDownvoteOpType, blog.RemoveDownvote)
+	transactionRouter.Register(types.GrantRoleOpType, blog.GrantRole)
+	transactionRouter.Register(types.RevokeRoleOpType, blog.RevokeRole)
+	transactionRouter.Register(types.SuspendAccountAdminOpType, blog.SuspendAccountAdmin)
+	transactionRouter.Register(types.LiftSuspensionAdminOpType, blog.LiftSuspensionAdmin)
+	transactionRouter.Register(types.ResolveModerationCaseAdminOpType, blog.ResolveModerationCaseAdmin)
+	transactionRouter.Register(types.MuteOpType, blog.Mute)
+	transactionRouter.Register(types.UnmuteOpType, blog.Unmute)
+	transactionRouter.Register(types.BlockOpType, blog.Block)
+	transactionRouter.Register(types.UnblockOpType, blog.Unblock)
+	transactionRouter.Register(types.SubscribeOpType, blog.Subscribe)
+	transactionRouter.Register(types.UnsubscribeOpType, blog.Unsubscribe)
 
 	return rpcRouter
 }
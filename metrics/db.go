@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// dbStatsCollector exposes sql.DB connection pool stats
+type dbStatsCollector struct {
+	db *sql.DB
+
+	maxOpen      *prometheus.Desc
+	open         *prometheus.Desc
+	inUse        *prometheus.Desc
+	idle         *prometheus.Desc
+	waitCount    *prometheus.Desc
+	waitDuration *prometheus.Desc
+}
+
+// RegisterDBStats registers the connection pool stats of the db labeled with the pool name
+func RegisterDBStats(pool string, db *sql.DB) {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "db", name), help,
+			nil, prometheus.Labels{"pool": pool})
+	}
+
+	prometheus.MustRegister(&dbStatsCollector{
+		db:           db,
+		maxOpen:      desc("max_open_connections", "Maximum number of open connections."),
+		open:         desc("open_connections", "Number of established connections both in use and idle."),
+		inUse:        desc("in_use_connections", "Number of connections currently in use."),
+		idle:         desc("idle_connections", "Number of idle connections."),
+		waitCount:    desc("wait_count_total", "Total number of connections waited for."),
+		waitDuration: desc("wait_duration_seconds_total", "Total time blocked waiting for a new connection."),
+	})
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpen
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.db.Stats()
+
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+}
@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "blog_api"
+
+var (
+	// RPCRequests counts processed requests per route
+	RPCRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "requests_total",
+		Help:      "Number of processed rpc requests.",
+	}, []string{"api", "method"})
+
+	// RPCRequestDuration observes request processing time per route
+	RPCRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "request_duration_seconds",
+		Help:      "Time spent processing rpc requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"api", "method"})
+
+	// RPCErrors counts written errors per rpc error code
+	RPCErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "errors_total",
+		Help:      "Number of rpc errors by error code.",
+	}, []string{"code"})
+
+	// BroadcastOperations counts accepted and rejected operations per operation type
+	BroadcastOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "broadcast",
+		Name:      "operations_total",
+		Help:      "Number of broadcasted operations by status.",
+	}, []string{"op", "status"})
+
+	// MonitorProcessedBlockNum is the block number of the last processed blockchain event
+	MonitorProcessedBlockNum = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "blockchain_monitor",
+		Name:      "processed_block_num",
+		Help:      "Block number of the last processed blockchain event.",
+	})
+
+	// MonitorHeadBlockNum is the head block number of the blockchain
+	MonitorHeadBlockNum = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "blockchain_monitor",
+		Name:      "head_block_num",
+		Help:      "Head block number of the blockchain.",
+	})
+
+	// MonitorLag is the number of blocks between the head block and the last processed one
+	MonitorLag = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "blockchain_monitor",
+		Name:      "lag_blocks",
+		Help:      "Number of blocks between the head block and the last processed one.",
+	})
+
+	// TextRuRequestDuration observes text.ru api calls time
+	TextRuRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "textru",
+		Name:      "request_duration_seconds",
+		Help:      "Time spent calling text.ru api.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// TextRuFailures counts failed text.ru api calls
+	TextRuFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "textru",
+		Name:      "failures_total",
+		Help:      "Number of failed text.ru api calls.",
+	}, []string{"method"})
+
+	// PushNotifications counts sent push notifications by outcome
+	PushNotifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "push",
+		Name:      "notifications_total",
+		Help:      "Number of sent FCM push notifications by outcome.",
+	}, []string{"outcome"})
+
+	// KeyCacheRequests counts account authorities lookups by result: hit, negative_hit or miss
+	KeyCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "key_cache",
+		Name:      "requests_total",
+		Help:      "Number of account authorities cache lookups by result.",
+	}, []string{"result"})
+
+	// BlobUploadDuration observes media upload time to the Azure blob
+	BlobUploadDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "blob",
+		Name:      "upload_duration_seconds",
+		Help:      "Time spent uploading media to the Azure blob.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"status"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		RPCRequests,
+		RPCRequestDuration,
+		RPCErrors,
+		BroadcastOperations,
+		MonitorProcessedBlockNum,
+		MonitorHeadBlockNum,
+		MonitorLag,
+		TextRuRequestDuration,
+		TextRuFailures,
+		PushNotifications,
+		BlobUploadDuration,
+		KeyCacheRequests,
+	)
+}
+
+// Since returns seconds elapsed since the start, to be observed by histograms
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
+
+// Handler returns http handler exposing the registered metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
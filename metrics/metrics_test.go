@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	db, err := sqlx.Open("postgres", "host=127.0.0.1 sslmode=disable")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(7)
+
+	RegisterDBStats("test", db.DB)
+	RPCErrors.WithLabelValues("3").Inc()
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, err := ioutil.ReadAll(w.Result().Body)
+	require.NoError(t, err)
+
+	require.Contains(t, string(body), `blog_api_db_max_open_connections{pool="test"} 7`)
+	require.Contains(t, string(body), `blog_api_rpc_errors_total{code="3"} 1`)
+}
@@ -2,6 +2,7 @@ package push
 
 import (
 	"fmt"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"gitlab.scorum.com/blog/api/db"
@@ -21,20 +22,22 @@ type Notifier interface {
 }
 
 func NewNotifier(pusher *Pusher, localizer *locale.Localizer, dp *domainprovider.DomainProvider,
-	prs *db.PushTokensStorage) Notifier {
+	prs *db.PushTokensStorage, nps *db.NotificationPreferencesStorage) Notifier {
 	return &notifier{
-		Pusher:                  pusher,
-		Localizer:               localizer,
-		DomainProvider:          dp,
-		PushRegistrationStorage: prs,
+		Pusher:                         pusher,
+		Localizer:                      localizer,
+		DomainProvider:                 dp,
+		PushRegistrationStorage:        prs,
+		NotificationPreferencesStorage: nps,
 	}
 }
 
 type notifier struct {
-	Pusher                  *Pusher
-	Localizer               *locale.Localizer
-	PushRegistrationStorage *db.PushTokensStorage
-	DomainProvider          *domainprovider.DomainProvider
+	Pusher                         *Pusher
+	Localizer                      *locale.Localizer
+	PushRegistrationStorage        *db.PushTokensStorage
+	NotificationPreferencesStorage *db.NotificationPreferencesStorage
+	DomainProvider                 *domainprovider.DomainProvider
 }
 
 func (n *notifier) getAccountLocalization(account string) (Domain, *locale.Localization) {
@@ -46,7 +49,18 @@ func (n *notifier) getAccountLocalization(account string) (Domain, *locale.Local
 	return domain, n.Localizer.GetLocalization(domain)
 }
 
-func (n *notifier) notify(account string, push Push) {
+// notify sends the push to every device of the account unless it has disabled the pushes of the type
+// or it is the quiet hours of the account
+func (n *notifier) notify(account string, t db.NotificationType, push Push) {
+	allowed, err := n.NotificationPreferencesStorage.Allows(account, t, db.PushNotificationChannel, time.Now())
+	if err != nil {
+		log.Errorf("failed to get %s notification preferences: %s", account, err)
+		return
+	}
+	if !allowed {
+		return
+	}
+
 	tokens, err := n.PushRegistrationStorage.GetTokensByAccount(account)
 	if err != nil {
 		log.Errorf("failed to get %s push tokens: %s", account, err)
@@ -73,7 +87,7 @@ func (n *notifier) NotifyPostReplied(meta db.PostRelatedNotificationMeta) {
 	go func() {
 		_, loc := n.getAccountLocalization(meta.PostAuthor)
 
-		n.notify(meta.PostAuthor, Push{
+		n.notify(meta.PostAuthor, db.PostRepliedNotificationType, Push{
 			Title:       meta.Account,
 			Body:        fmt.Sprintf(`%s "%s"`, loc.Translate("blog.notifications.comment"), meta.PostTitle),
 			ClickAction: meta.PostLink(),
@@ -85,7 +99,7 @@ func (n *notifier) NotifyCommentReplied(parentCommentAuthor string, meta db.Post
 	go func() {
 		_, loc := n.getAccountLocalization(parentCommentAuthor)
 
-		n.notify(parentCommentAuthor, Push{
+		n.notify(parentCommentAuthor, db.CommentRepliedNotificationType, Push{
 			Title:       meta.Account,
 			Body:        fmt.Sprintf(`%s "%s`, loc.Translate("blog.notifications.respond"), meta.PostTitle),
 			ClickAction: meta.PostLink(),
@@ -97,7 +111,7 @@ func (n *notifier) NotifyStartedFollow(account string, who string) {
 	go func() {
 		domain, loc := n.getAccountLocalization(account)
 
-		n.notify(account, Push{
+		n.notify(account, db.StartedFollowNotificationType, Push{
 			Title:       who,
 			Body:        loc.Translate("blog.notifications.follow"),
 			ClickAction: fmt.Sprintf("https://scorum.%s/profile/@%s", domain, who),
@@ -109,7 +123,7 @@ func (n *notifier) NotifyPostVoted(meta db.PostRelatedNotificationMeta) {
 	go func() {
 		_, loc := n.getAccountLocalization(meta.PostAuthor)
 
-		n.notify(meta.PostAuthor, Push{
+		n.notify(meta.PostAuthor, db.PostVotedNotificationType, Push{
 			Title:       meta.Account,
 			Body:        fmt.Sprintf(`%s "%s`, loc.Translate("blog.notifications.upvote-post"), meta.PostTitle),
 			ClickAction: meta.PostLink(),
@@ -121,7 +135,7 @@ func (n *notifier) NotifyCommentVoted(parentCommentAuthor string, meta db.PostRe
 	go func() {
 		_, loc := n.getAccountLocalization(parentCommentAuthor)
 
-		n.notify(parentCommentAuthor, Push{
+		n.notify(parentCommentAuthor, db.CommentVotedNotificationType, Push{
 			Title:       meta.Account,
 			Body:        fmt.Sprintf(`%s "%s`, loc.Translate("blog.notifications.upvote-comment"), meta.PostTitle),
 			ClickAction: meta.PostLink(),
@@ -133,7 +147,7 @@ func (n *notifier) NotifyPostFlagged(meta db.PostRelatedNotificationMeta) {
 	go func() {
 		_, loc := n.getAccountLocalization(meta.PostAuthor)
 
-		n.notify(meta.PostAuthor, Push{
+		n.notify(meta.PostAuthor, db.PostFlaggedNotificationType, Push{
 			Title:       meta.Account,
 			Body:        fmt.Sprintf(`%s "%s`, loc.Translate("blog.notifications.downvote-post"), meta.PostTitle),
 			ClickAction: meta.PostLink(),
@@ -145,7 +159,7 @@ func (n *notifier) NotifyCommentFlagged(parentCommentAuthor string, meta db.Post
 	go func() {
 		_, loc := n.getAccountLocalization(parentCommentAuthor)
 
-		n.notify(parentCommentAuthor, Push{
+		n.notify(parentCommentAuthor, db.CommentFlaggedNotificationType, Push{
 			Title:       meta.Account,
 			Body:        fmt.Sprintf(`%s "%s`, loc.Translate("blog.notifications.downvote-comment"), meta.PostTitle),
 			ClickAction: meta.PostLink(),
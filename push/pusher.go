@@ -7,6 +7,7 @@ import (
 
 	"github.com/appleboy/go-fcm"
 	log "github.com/sirupsen/logrus"
+	"gitlab.scorum.com/blog/api/metrics"
 )
 
 var ErrTokenUnregistered = errors.New("token unregistered")
@@ -49,21 +50,27 @@ func (p *Pusher) SendWebPush(token string, push Push) error {
 
 	resp, err := p.client.Send(message)
 	if err != nil {
+		metrics.PushNotifications.WithLabelValues("failed").Inc()
 		return err
 	}
 
 	if resp.Error != nil {
+		metrics.PushNotifications.WithLabelValues("failed").Inc()
 		return resp.Error
 	}
 
 	if len(resp.Results) == 0 {
+		metrics.PushNotifications.WithLabelValues("failed").Inc()
 		return fmt.Errorf("empty results response")
 	}
 
 	if resp.Results[0].Unregistered() {
+		metrics.PushNotifications.WithLabelValues("unregistered").Inc()
 		return ErrTokenUnregistered
 	}
 
+	metrics.PushNotifications.WithLabelValues("sent").Inc()
+
 	log.Debugf("successfully send message to %s: %v", token, resp)
 	return nil
 }
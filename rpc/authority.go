@@ -0,0 +1,219 @@
+package rpc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/pkg/errors"
+	"github.com/scorum/scorum-go"
+	scorumtype "github.com/scorum/scorum-go/types"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+)
+
+const pubKeyPrefix = "SCR"
+
+// maxAuthorityDepth limits the depth of the account_auths delegation the same way the blockchain does
+const maxAuthorityDepth = 2
+
+// ErrAccountNotFound is returned by the AuthorityProvider for accounts which do not exist in the blockchain
+var ErrAccountNotFound = errors.New("account not found")
+
+// Authorities are the owner, active and posting authorities of the account
+type Authorities struct {
+	Owner   scorumtype.Authority
+	Active  scorumtype.Authority
+	Posting scorumtype.Authority
+}
+
+// AuthorityProvider provides authorities of the blockchain accounts
+type AuthorityProvider interface {
+	GetAuthorities(account string) (*Authorities, error)
+}
+
+type blockchainAuthorityProvider struct {
+	blockchain *scorumgo.Client
+}
+
+// NewBlockchainAuthorityProvider creates an AuthorityProvider requesting the blockchain on every call
+func NewBlockchainAuthorityProvider(blockchain *scorumgo.Client) AuthorityProvider {
+	return &blockchainAuthorityProvider{blockchain: blockchain}
+}
+
+func (p *blockchainAuthorityProvider) GetAuthorities(account string) (*Authorities, error) {
+	accounts, err := p.blockchain.Database.GetAccounts(account)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(accounts) == 0 {
+		return nil, ErrAccountNotFound
+	}
+
+	return &Authorities{
+		Owner:   accounts[0].Owner,
+		Active:  accounts[0].Active,
+		Posting: accounts[0].Posting,
+	}, nil
+}
+
+// SignPubKeys returns public keys of the owner, active and posting authorities
+func (a *Authorities) SignPubKeys() ([][]byte, error) {
+	var out [][]byte
+
+	//owner
+	keys, err := extractPubKeys(a.Owner.KeyAuths)
+	if err != nil {
+		return nil, errors.Wrap(err, "extract owner keys failed")
+	}
+	out = append(out, keys...)
+
+	//active
+	keys, err = extractPubKeys(a.Active.KeyAuths)
+	if err != nil {
+		return nil, errors.Wrap(err, "extract active keys failed")
+	}
+	out = append(out, keys...)
+
+	//posting
+	keys, err = extractPubKeys(a.Posting.KeyAuths)
+	if err != nil {
+		return nil, errors.Wrap(err, "extract posting keys failed")
+	}
+	out = append(out, keys...)
+
+	if len(out) == 0 {
+		return nil, errors.New("sing keys not found")
+	}
+
+	return out, nil
+}
+
+func extractPubKeys(keys scorumtype.StringInt64Map) ([][]byte, error) {
+	var out [][]byte
+	for key := range keys {
+		pubKey, err := decodePubKey(key)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, pubKey)
+	}
+	return out, nil
+}
+
+// decodePubKey strips the prefix and the checksum of the SCR public key
+func decodePubKey(key string) ([]byte, error) {
+	if strings.Index(key, pubKeyPrefix) != 0 {
+		return nil, fmt.Errorf("%s is not a valid key", key)
+	}
+	keyWithChecksum := base58.Decode(key[len(pubKeyPrefix):])
+	if len(keyWithChecksum) <= 4 {
+		return nil, fmt.Errorf("%s is not a valid key", key)
+	}
+	return keyWithChecksum[:len(keyWithChecksum)-4], nil
+}
+
+// CheckAuthority reports whether the signer keys satisfy the authority of the account required by the level,
+// the owner and the active authorities satisfy the lower ones as well
+func CheckAuthority(provider AuthorityProvider, account string, level types.AuthorityLevel, signers [][]byte) (bool, error) {
+	authorities, err := provider.GetAuthorities(account)
+	if err != nil {
+		return false, err
+	}
+
+	return newAuthorityCheck(provider, level, signers).authoritiesSatisfied(authorities, 0)
+}
+
+// get returns the authority of the given level
+func (a *Authorities) get(level types.AuthorityLevel) scorumtype.Authority {
+	switch level {
+	case types.OwnerAuthority:
+		return a.Owner
+	case types.ActiveAuthority:
+		return a.Active
+	}
+	return a.Posting
+}
+
+// authorityCheck sums up weights of the signer keys and the approved account_auths,
+// delegated accounts are checked against the authority of the required level the same way as the account
+type authorityCheck struct {
+	provider AuthorityProvider
+	level    types.AuthorityLevel
+	signers  map[string]bool
+	approved map[string]bool
+}
+
+func newAuthorityCheck(provider AuthorityProvider, level types.AuthorityLevel, signers [][]byte) *authorityCheck {
+	check := &authorityCheck{
+		provider: provider,
+		level:    level,
+		signers:  make(map[string]bool, len(signers)),
+		approved: make(map[string]bool),
+	}
+	for _, signer := range signers {
+		check.signers[string(signer)] = true
+	}
+	return check
+}
+
+// authoritiesSatisfied checks the authority of the required level and the higher ones
+func (c *authorityCheck) authoritiesSatisfied(authorities *Authorities, depth int) (bool, error) {
+	for l := c.level; l <= types.OwnerAuthority; l++ {
+		ok, err := c.satisfied(authorities.get(l), depth)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func (c *authorityCheck) satisfied(auth scorumtype.Authority, depth int) (bool, error) {
+	threshold := int64(auth.WeightThreshold)
+
+	var weight int64
+	for key, keyWeight := range auth.KeyAuths {
+		pubKey, err := decodePubKey(key)
+		if err != nil {
+			return false, err
+		}
+		if c.signers[string(pubKey)] {
+			weight += keyWeight
+			if weight >= threshold {
+				return true, nil
+			}
+		}
+	}
+
+	for account, accountWeight := range auth.AccountAuths {
+		if !c.approved[account] {
+			if depth >= maxAuthorityDepth {
+				continue
+			}
+
+			authorities, err := c.provider.GetAuthorities(account)
+			if err == ErrAccountNotFound {
+				continue
+			}
+			if err != nil {
+				return false, errors.Wrapf(err, "failed to get %s authorities", account)
+			}
+
+			ok, err := c.authoritiesSatisfied(authorities, depth+1)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				continue
+			}
+			c.approved[account] = true
+		}
+
+		weight += accountWeight
+		if weight >= threshold {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
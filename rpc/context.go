@@ -4,18 +4,31 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	protocol "github.com/scorum/scorum-go/transport"
 	log "github.com/sirupsen/logrus"
+	"gitlab.scorum.com/blog/api/metrics"
 )
 
 type Context struct {
 	ID      uint64
 	Request *http.Request
 	Writer  http.ResponseWriter
+	// Session is set when the request is received over websocket
+	Session *Session
 	params  *Params
 	log     *log.Entry
 
+	// set by the Signed middleware
+	account      string
+	signedParams []*json.RawMessage
+
+	// indicates that the context is a part of a batch request:
+	// response is kept in memory instead of being written to the Writer
+	batched  bool
+	response *protocol.RPCResponse
+
 	// indicates that the context, has been flushed: response is already written
 	flushed bool
 }
@@ -28,6 +41,17 @@ func NewContext(req *http.Request, writer http.ResponseWriter) *Context {
 	}
 }
 
+// newBatchContext creates a context for a single request of the batch
+// or for a request received over websocket
+func newBatchContext(req *http.Request, session *Session) *Context {
+	return &Context{
+		Request: req,
+		Session: session,
+		batched: true,
+		log:     log.NewEntry(log.StandardLogger()),
+	}
+}
+
 // API returns API name
 func (c Context) API() string {
 	return c.params.API
@@ -38,6 +62,16 @@ func (c Context) Method() string {
 	return c.params.Method
 }
 
+// Account returns the account of the signed request
+func (c Context) Account() string {
+	return c.account
+}
+
+// SignedParams returns params of the signed request
+func (c Context) SignedParams() []*json.RawMessage {
+	return c.signedParams
+}
+
 func (c Context) Param(at int, p interface{}) error {
 	if at >= len(c.params.Args) {
 		return fmt.Errorf("no params at index %d", at)
@@ -60,6 +94,21 @@ func (c *Context) Parse() (ok bool) {
 		return false
 	}
 
+	return c.parse(rpcRequest)
+}
+
+// parseRaw parses a single RPC request of the batch
+func (c *Context) parseRaw(raw json.RawMessage) (ok bool) {
+	var rpcRequest Request
+	if err := json.Unmarshal(raw, &rpcRequest); err != nil {
+		c.WriteError(InvalidRequestCode, fmt.Sprintf("batch item is not an rpc request: %s", err.Error()))
+		return false
+	}
+
+	return c.parse(rpcRequest)
+}
+
+func (c *Context) parse(rpcRequest Request) (ok bool) {
 	c.ID = rpcRequest.ID
 	c.params = &rpcRequest.Params
 
@@ -90,20 +139,26 @@ func (c *Context) WriteResult(out interface{}) {
 	}()
 
 	if out == nil {
+		if c.batched {
+			// batch response has to contain an item for every request
+			null := json.RawMessage("null")
+			c.write(protocol.RPCResponse{
+				ID:     c.ID,
+				Result: &null,
+			})
+			return
+		}
 		c.Writer.WriteHeader(http.StatusNoContent)
 		return
 	}
 
-	c.Writer.Header().Set("Content-Type", "application/json")
-	c.Writer.WriteHeader(http.StatusOK)
-
 	bytes, err := json.Marshal(out)
 	if err != nil {
 		c.log.Fatal(err)
 	}
 
 	raw := json.RawMessage(bytes)
-	json.NewEncoder(c.Writer).Encode(&protocol.RPCResponse{
+	c.write(protocol.RPCResponse{
 		ID:     c.ID,
 		Result: &raw,
 	})
@@ -115,8 +170,7 @@ func (c *Context) WriteError(code int, message string) {
 		panic("context is flushed")
 	}
 
-	c.Writer.Header().Set("Content-Type", "application/json")
-	c.Writer.WriteHeader(http.StatusOK)
+	metrics.RPCErrors.WithLabelValues(strconv.Itoa(code)).Inc()
 
 	if code == InternalErrorCode {
 		c.log.Errorf("error: %s", message)
@@ -132,6 +186,36 @@ func (c *Context) WriteError(code int, message string) {
 		},
 	}
 
+	if err := c.write(out); err != nil {
+		c.log.Fatal(err)
+	}
+
+	c.flushed = true
+}
+
+// write sends the response to the client,
+// batched context keeps the response to be sent within the batch
+func (c *Context) write(out protocol.RPCResponse) error {
+	if c.batched {
+		c.response = &out
+		return nil
+	}
+
+	c.Writer.Header().Set("Content-Type", "application/json")
+	c.Writer.WriteHeader(http.StatusOK)
+
+	return json.NewEncoder(c.Writer).Encode(out)
+}
+
+// writeBatch sends responses of the batch request in the order of the requests
+func (c *Context) writeBatch(out []*protocol.RPCResponse) {
+	if c.flushed {
+		panic("context is flushed")
+	}
+
+	c.Writer.Header().Set("Content-Type", "application/json")
+	c.Writer.WriteHeader(http.StatusOK)
+
 	if err := json.NewEncoder(c.Writer).Encode(out); err != nil {
 		c.log.Fatal(err)
 	}
@@ -23,6 +23,16 @@ const (
 	PlagiarismDetailsNotFoundCode
 	DownvoteNotFoundCode
 	BlacklistEntityNotFoundCode
+	ReplayedRequestCode
+	ExpiredTransactionCode
+	DuplicateTransactionCode
+	RoleNotFoundCode
+	AccountSuspendedCode
+	SuspensionNotFoundCode
+	ModerationCaseNotFoundCode
+	ModerationCaseResolvedCode
+	PostNotFoundCode
+	AccountBlockedCode
 )
 
 type Error struct {
@@ -0,0 +1,140 @@
+package rpc
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"gitlab.scorum.com/blog/api/metrics"
+)
+
+// key cache lookup results of the metrics
+const (
+	keyCacheHit         = "hit"
+	keyCacheNegativeHit = "negative_hit"
+	keyCacheMiss        = "miss"
+)
+
+// KeyCache is a bounded LRU cache of the account authorities with the entries expiring after the ttl,
+// unknown accounts are cached for the negativeTTL
+type KeyCache struct {
+	provider    AuthorityProvider
+	size        int
+	ttl         time.Duration
+	negativeTTL time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type keyCacheEntry struct {
+	account     string
+	authorities *Authorities
+	expiresAt   time.Time
+}
+
+// NewKeyCache creates KeyCache of the given size on top of the provider
+func NewKeyCache(provider AuthorityProvider, size int, ttl, negativeTTL time.Duration) *KeyCache {
+	return &KeyCache{
+		provider:    provider,
+		size:        size,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		now:         time.Now,
+		lru:         list.New(),
+		entries:     make(map[string]*list.Element),
+	}
+}
+
+// GetAuthorities returns the cached authorities of the account or requests them from the provider
+func (c *KeyCache) GetAuthorities(account string) (*Authorities, error) {
+	if entry, ok := c.get(account); ok {
+		if entry.authorities == nil {
+			metrics.KeyCacheRequests.WithLabelValues(keyCacheNegativeHit).Inc()
+			return nil, ErrAccountNotFound
+		}
+		metrics.KeyCacheRequests.WithLabelValues(keyCacheHit).Inc()
+		return entry.authorities, nil
+	}
+	metrics.KeyCacheRequests.WithLabelValues(keyCacheMiss).Inc()
+
+	authorities, err := c.provider.GetAuthorities(account)
+	switch {
+	case err == ErrAccountNotFound:
+		c.put(account, nil, c.negativeTTL)
+	case err == nil:
+		c.put(account, authorities, c.ttl)
+	}
+	return authorities, err
+}
+
+// Invalidate removes the accounts from the cache
+func (c *KeyCache) Invalidate(accounts ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, account := range accounts {
+		if el, ok := c.entries[account]; ok {
+			c.remove(el)
+		}
+	}
+}
+
+// Len returns the number of the cached entries including the expired ones
+func (c *KeyCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *KeyCache) get(account string) (*keyCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[account]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*keyCacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.remove(el)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(el)
+	return entry, true
+}
+
+func (c *KeyCache) put(account string, authorities *Authorities, ttl time.Duration) {
+	if c.size <= 0 || ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &keyCacheEntry{
+		account:     account,
+		authorities: authorities,
+		expiresAt:   c.now().Add(ttl),
+	}
+
+	if el, ok := c.entries[account]; ok {
+		el.Value = entry
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.entries[account] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *KeyCache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*keyCacheEntry).account)
+}
@@ -0,0 +1,98 @@
+package rpc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/scorum/scorum-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+type countingAuthorityProvider struct {
+	calls map[string]int
+	err   error
+}
+
+func (p *countingAuthorityProvider) GetAuthorities(account string) (*Authorities, error) {
+	p.calls[account]++
+	if p.err != nil {
+		return nil, p.err
+	}
+	if account == "unknown" {
+		return nil, ErrAccountNotFound
+	}
+	return &Authorities{
+		Posting: types.Authority{WeightThreshold: 1},
+	}, nil
+}
+
+func TestKeyCache(t *testing.T) {
+	now := time.Now()
+	provider := &countingAuthorityProvider{calls: make(map[string]int)}
+
+	cache := NewKeyCache(provider, 2, time.Minute, time.Second)
+	cache.now = func() time.Time { return now }
+
+	t.Run("hit", func(t *testing.T) {
+		_, err := cache.GetAuthorities("leonarda")
+		require.NoError(t, err)
+		_, err = cache.GetAuthorities("leonarda")
+		require.NoError(t, err)
+		require.Equal(t, 1, provider.calls["leonarda"])
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		_, err := cache.GetAuthorities("unknown")
+		require.Equal(t, ErrAccountNotFound, err)
+		_, err = cache.GetAuthorities("unknown")
+		require.Equal(t, ErrAccountNotFound, err)
+		require.Equal(t, 1, provider.calls["unknown"])
+
+		now = now.Add(2 * time.Second)
+		_, err = cache.GetAuthorities("unknown")
+		require.Equal(t, ErrAccountNotFound, err)
+		require.Equal(t, 2, provider.calls["unknown"])
+
+		// positive entries live longer
+		_, err = cache.GetAuthorities("leonarda")
+		require.NoError(t, err)
+		require.Equal(t, 1, provider.calls["leonarda"])
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+		_, err := cache.GetAuthorities("leonarda")
+		require.NoError(t, err)
+		require.Equal(t, 2, provider.calls["leonarda"])
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		_, err := cache.GetAuthorities("kristie")
+		require.NoError(t, err)
+		_, err = cache.GetAuthorities("roselle")
+		require.NoError(t, err)
+		require.Equal(t, 2, cache.Len())
+
+		// leonarda is the least recently used
+		_, err = cache.GetAuthorities("leonarda")
+		require.NoError(t, err)
+		require.Equal(t, 3, provider.calls["leonarda"])
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		cache.Invalidate("leonarda")
+		_, err := cache.GetAuthorities("leonarda")
+		require.NoError(t, err)
+		require.Equal(t, 4, provider.calls["leonarda"])
+	})
+
+	t.Run("errors_not_cached", func(t *testing.T) {
+		provider.err = errors.New("connection refused")
+		_, err := cache.GetAuthorities("azucena")
+		require.Error(t, err)
+		_, err = cache.GetAuthorities("azucena")
+		require.Error(t, err)
+		require.Equal(t, 2, provider.calls["azucena"])
+	})
+}
@@ -0,0 +1,134 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gitlab.scorum.com/blog/api/metrics"
+)
+
+// Middleware wraps the handler to run the code before and after it
+type Middleware func(next APIHandler) APIHandler
+
+// chain wraps the handler with the middlewares, the first middleware is the outermost
+func chain(handler APIHandler, middlewares []Middleware) APIHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Recovery recovers the panic of the handler and writes it as an internal error
+func Recovery(next APIHandler) APIHandler {
+	return func(ctx *Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				err, ok := r.(error)
+				if !ok {
+					err = errors.New(fmt.Sprintf("unknown panic: %s", r))
+				}
+
+				ctx.log.Error(err)
+				if ctx.flushed {
+					ctx.log.Warningf("Has panic but context flushed: %s", err)
+					return
+				}
+				ctx.WriteError(InternalErrorCode, err.Error())
+			}
+		}()
+
+		next(ctx)
+	}
+}
+
+// Logging logs the processed request with the elapsed time
+func Logging(next APIHandler) APIHandler {
+	return func(ctx *Context) {
+		ctx.log.Debug("processing started")
+		defer func(start time.Time) {
+			ctx.log.WithFields(log.Fields{
+				"elapsed": time.Since(start),
+				"route":   fmt.Sprintf("%s.%s", ctx.API(), ctx.Method()),
+			}).Debug("processed")
+		}(time.Now())
+
+		next(ctx)
+	}
+}
+
+// Instrument collects request count and processing time metrics per route
+func Instrument(next APIHandler) APIHandler {
+	return func(ctx *Context) {
+		defer func(start time.Time) {
+			metrics.RPCRequests.WithLabelValues(ctx.API(), ctx.Method()).Inc()
+			metrics.RPCRequestDuration.WithLabelValues(ctx.API(), ctx.Method()).Observe(metrics.Since(start))
+		}(time.Now())
+
+		next(ctx)
+	}
+}
+
+// Signed verifies the signed request: [account, salt, signature, params].
+// The account and params are available in the Context for the next handler
+func (router *Router) Signed(next APIHandler) APIHandler {
+	return func(ctx *Context) {
+		var account string
+		if err := ctx.Param(0, &account); err != nil {
+			ctx.WriteError(InvalidParameterCode, err.Error())
+			return
+		}
+
+		var salt string
+		if err := ctx.Param(1, &salt); err != nil {
+			ctx.WriteError(InvalidParameterCode, err.Error())
+			return
+		}
+
+		var signature string
+		if err := ctx.Param(2, &signature); err != nil {
+			ctx.WriteError(InvalidParameterCode, err.Error())
+			return
+		}
+
+		var params []*json.RawMessage
+		if err := ctx.Param(3, &params); err != nil {
+			ctx.WriteError(InvalidParameterCode, err.Error())
+			return
+		}
+
+		authorities, err := router.authorities.GetAuthorities(account)
+		if err != nil {
+			ctx.WriteError(InvalidParameterCode, err.Error())
+			return
+		}
+
+		keys, err := authorities.SignPubKeys()
+		if err != nil {
+			ctx.WriteError(InvalidParameterCode, err.Error())
+			return
+		}
+
+		valid, err := router.verifier.VerifySignedRequest(account, salt, signature, params, keys)
+		if err != nil {
+			ctx.WriteError(InvalidRequestCode, err.Error())
+			return
+		}
+
+		if !valid {
+			ctx.WriteError(InvalidParameterCode, "signature is not valid")
+			return
+		}
+
+		if err := router.checkSalt(account, salt); err != nil {
+			ctx.WriteError(err.Code, err.Message)
+			return
+		}
+
+		ctx.account = account
+		ctx.signedParams = params
+		next(ctx)
+	}
+}
@@ -0,0 +1,67 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	protocol "github.com/scorum/scorum-go/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_Middlewares(t *testing.T) {
+	router := NewRouter(client, NewVerifier(ChainID), MaxRequestSize, MaxBatchSize)
+
+	var calls []string
+	middleware := func(name string) Middleware {
+		return func(next APIHandler) APIHandler {
+			return func(ctx *Context) {
+				calls = append(calls, name)
+				next(ctx)
+			}
+		}
+	}
+
+	router.Use(middleware("router"))
+	router.Register(Route{"api", "method"}, func(ctx *Context) {
+		calls = append(calls, "handler")
+		ctx.WriteResult(nil)
+	}, middleware("route_1"), middleware("route_2"))
+	router.Register(Route{"api", "panic"}, func(ctx *Context) {
+		panic("boom")
+	})
+
+	handle := func(method string) protocol.RPCResponse {
+		req := httptest.NewRequest("POST", "/", bytes.NewBufferString(
+			`{"id": 1, "method": "call", "params": ["api", "`+method+`", []]}`))
+		w := httptest.NewRecorder()
+		router.Handle(w, req)
+
+		var resp protocol.RPCResponse
+		if w.Body.Len() > 0 {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return resp
+	}
+
+	t.Run("order", func(t *testing.T) {
+		calls = nil
+		handle("method")
+		require.Equal(t, []string{"router", "route_1", "route_2", "handler"}, calls)
+	})
+
+	t.Run("added_after_register", func(t *testing.T) {
+		router.Use(middleware("late"))
+		calls = nil
+		handle("method")
+		require.Equal(t, []string{"router", "late", "route_1", "route_2", "handler"}, calls)
+	})
+
+	t.Run("recovery", func(t *testing.T) {
+		resp := handle("panic")
+		require.NotNil(t, resp.Error)
+		require.Equal(t, InternalErrorCode, resp.Error.Code)
+		require.Equal(t, "unknown panic: boom", resp.Error.Message)
+	})
+}
@@ -0,0 +1,117 @@
+package rpc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NonceStore keeps used salts of the signed requests until they expire
+type NonceStore interface {
+	// Use marks the salt of the account as used, returns false if it has been already used
+	Use(account, salt string, expiresAt time.Time) (bool, error)
+	// DeleteExpired removes salts which can not be accepted anymore
+	DeleteExpired() error
+}
+
+// EnableReplayProtection makes signed requests require the salt in the "<unix timestamp>:<random>" format,
+// the request is accepted only once and only within the window of the salt timestamp.
+// Salts without the timestamp are accepted as is if allowLegacySalt is set
+func (router *Router) EnableReplayProtection(nonces NonceStore, window time.Duration, allowLegacySalt bool) {
+	router.nonces = nonces
+	router.saltWindow = window
+	router.allowLegacySalt = allowLegacySalt
+}
+
+// checkSalt rejects expired and already used salts
+func (router *Router) checkSalt(account, salt string) *Error {
+	if router.nonces == nil {
+		return nil
+	}
+
+	issuedAt, ok := parseSaltTimestamp(salt)
+	if !ok {
+		if router.allowLegacySalt {
+			return nil
+		}
+		return &Error{
+			Code:    InvalidParameterCode,
+			Message: "salt should be in the <unix timestamp>:<random> format",
+		}
+	}
+
+	if age := time.Since(issuedAt); age > router.saltWindow || age < -router.saltWindow {
+		return &Error{
+			Code:    ReplayedRequestCode,
+			Message: fmt.Sprintf("request is expired: salt timestamp is out of %s window", router.saltWindow),
+		}
+	}
+
+	// the salt can not be accepted after the window anyway
+	ok, err := router.nonces.Use(account, salt, issuedAt.Add(router.saltWindow))
+	if err != nil {
+		return &Error{Code: InternalErrorCode, Message: err.Error()}
+	}
+
+	if !ok {
+		return &Error{Code: ReplayedRequestCode, Message: "request has been already used"}
+	}
+
+	return nil
+}
+
+// parseSaltTimestamp extracts the timestamp of the "<unix timestamp>:<random>" salt
+func parseSaltTimestamp(salt string) (time.Time, bool) {
+	parts := strings.SplitN(salt, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return time.Time{}, false
+	}
+
+	timestamp, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(timestamp, 0), true
+}
+
+type memoryNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+// NewMemoryNonceStore creates NonceStore keeping salts in memory,
+// suitable for a single node deployment only
+func NewMemoryNonceStore() NonceStore {
+	return &memoryNonceStore{
+		nonces: make(map[string]time.Time),
+	}
+}
+
+func (s *memoryNonceStore) Use(account, salt string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := account + "/" + salt
+	if expires, ok := s.nonces[key]; ok && expires.After(time.Now()) {
+		return false, nil
+	}
+
+	s.nonces[key] = expiresAt
+	return true, nil
+}
+
+func (s *memoryNonceStore) DeleteExpired() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, expires := range s.nonces {
+		if expires.Before(now) {
+			delete(s.nonces, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package rpc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_CheckSalt(t *testing.T) {
+	router := NewRouter(client, NewVerifier(ChainID), MaxRequestSize, MaxBatchSize)
+
+	salt := func(issuedAt time.Time) string {
+		return fmt.Sprintf("%d:%s", issuedAt.Unix(), "random")
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		require.Nil(t, router.checkSalt("leonarda", "1111"))
+	})
+
+	router.EnableReplayProtection(NewMemoryNonceStore(), time.Minute, false)
+
+	t.Run("valid", func(t *testing.T) {
+		require.Nil(t, router.checkSalt("leonarda", salt(time.Now())))
+	})
+
+	t.Run("replayed", func(t *testing.T) {
+		s := salt(time.Now().Add(-time.Second))
+		require.Nil(t, router.checkSalt("leonarda", s))
+
+		err := router.checkSalt("leonarda", s)
+		require.NotNil(t, err)
+		require.Equal(t, ReplayedRequestCode, err.Code)
+
+		// salts are kept per account
+		require.Nil(t, router.checkSalt("kristie", s))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		err := router.checkSalt("leonarda", salt(time.Now().Add(-2*time.Minute)))
+		require.NotNil(t, err)
+		require.Equal(t, ReplayedRequestCode, err.Code)
+
+		err = router.checkSalt("leonarda", salt(time.Now().Add(2*time.Minute)))
+		require.NotNil(t, err)
+		require.Equal(t, ReplayedRequestCode, err.Code)
+	})
+
+	t.Run("legacy", func(t *testing.T) {
+		err := router.checkSalt("leonarda", "1111")
+		require.NotNil(t, err)
+		require.Equal(t, InvalidParameterCode, err.Code)
+
+		router.EnableReplayProtection(NewMemoryNonceStore(), time.Minute, true)
+		require.Nil(t, router.checkSalt("leonarda", "1111"))
+		require.Nil(t, router.checkSalt("leonarda", "1111"))
+	})
+}
+
+func TestMemoryNonceStore(t *testing.T) {
+	store := NewMemoryNonceStore()
+
+	ok, err := store.Use("leonarda", "1:a", time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// expired nonce could be used again
+	ok, err = store.Use("leonarda", "1:a", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = store.Use("leonarda", "1:a", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, store.DeleteExpired())
+	require.Len(t, store.(*memoryNonceStore).nonces, 1)
+}
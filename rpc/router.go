@@ -1,16 +1,19 @@
 package rpc
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
-	"strings"
+	"time"
 
 	"encoding/json"
 
-	"github.com/btcsuite/btcutil/base58"
-	"github.com/pkg/errors"
 	"github.com/scorum/scorum-go"
-	"github.com/scorum/scorum-go/types"
+	protocol "github.com/scorum/scorum-go/transport"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/websocket"
 )
 
 type Route struct {
@@ -22,58 +25,55 @@ type APIHandler func(ctx *Context)
 type SignedAPIHandler func(ctx *Context, account string, params []*json.RawMessage)
 
 type Router struct {
-	blockchain  *scorumgo.Client
+	authorities AuthorityProvider
 	verifier    Verifier
 	routes      map[Route]APIHandler
+	middlewares []Middleware
 	maxBodySize int64
+	// maximum number of requests in the batch, 0 means unlimited
+	maxBatchSize int
+
+	// replay protection of the signed requests, disabled if nonces is nil
+	nonces          NonceStore
+	saltWindow      time.Duration
+	allowLegacySalt bool
 }
 
-func NewRouter(blockchain *scorumgo.Client, verifier Verifier, maxBodySize int64) *Router {
+func NewRouter(blockchain *scorumgo.Client, verifier Verifier, maxBodySize int64, maxBatchSize int) *Router {
 	return &Router{
-		blockchain:  blockchain,
-		verifier:    verifier,
-		routes:      make(map[Route]APIHandler),
-		maxBodySize: maxBodySize,
+		authorities:  NewBlockchainAuthorityProvider(blockchain),
+		verifier:     verifier,
+		routes:       make(map[Route]APIHandler),
+		middlewares:  []Middleware{Recovery},
+		maxBodySize:  maxBodySize,
+		maxBatchSize: maxBatchSize,
 	}
 }
 
-func (router *Router) Register(r Route, h APIHandler) {
-	router.routes[r] = h
+// SetAuthorityProvider replaces the provider of the account authorities used to verify signed requests,
+// by default the blockchain is requested on every call
+func (router *Router) SetAuthorityProvider(authorities AuthorityProvider) {
+	router.authorities = authorities
 }
 
-func (router *Router) route(r Route, ctx *Context) {
-	defer func() {
-		if r := recover(); r != nil {
-			err, ok := r.(error)
-			if !ok {
-				err = errors.New(fmt.Sprintf("unknown panic: %s", r))
-			}
+// Use adds middlewares applied to every route, Recovery is used by default
+func (router *Router) Use(middlewares ...Middleware) {
+	router.middlewares = append(router.middlewares, middlewares...)
+}
 
-			ctx.log.Error(err)
-			if ctx.flushed {
-				ctx.log.Warningf("Has panic but context flushed: %s", err)
-				return
-			}
-			ctx.WriteError(InternalErrorCode, err.Error())
-		}
-	}()
+// Register handler of the route, the route middlewares are applied after the router ones
+func (router *Router) Register(r Route, h APIHandler, middlewares ...Middleware) {
+	router.routes[r] = chain(h, middlewares)
+}
 
+func (router *Router) route(r Route, ctx *Context) {
 	route, ok := router.routes[r]
 	if !ok {
 		ctx.WriteError(RouteNotRegisteredCode, fmt.Sprintf("route: %s not registered", r))
 		return
 	}
 
-	/*
-		ctx.log.Debug("processing started")
-		defer func(start time.Time) {
-			ctx.log.WithFields(log.Fields{
-				"elapsed": time.Since(start),
-				"route":   fmt.Sprintf("%+v", r),
-			}).Debug("processed")
-		}(time.Now())
-	*/
-	route(ctx)
+	chain(route, router.middlewares)(ctx)
 }
 
 func (router *Router) Handle(writer http.ResponseWriter, request *http.Request) {
@@ -90,6 +90,18 @@ func (router *Router) Handle(writer http.ResponseWriter, request *http.Request)
 
 	switch request.Method {
 	case "POST":
+		body, err := ioutil.ReadAll(request.Body)
+		if err != nil {
+			ctx.WriteError(InvalidRequestCode, fmt.Sprintf("failed to read message body: %s", err.Error()))
+			return
+		}
+
+		if isBatch(body) {
+			router.handleBatch(ctx, body)
+			return
+		}
+
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
 		if ok := ctx.Parse(); !ok {
 			return
 		}
@@ -107,105 +119,119 @@ func (router *Router) Handle(writer http.ResponseWriter, request *http.Request)
 	}
 }
 
-func (router *Router) SignedAPI(handler SignedAPIHandler) APIHandler {
-	return func(ctx *Context) {
-		var account string
-		if err := ctx.Param(0, &account); err != nil {
-			ctx.WriteError(InvalidParameterCode, err.Error())
-			return
-		}
+// handleBatch processes every request of the batch on its own context
+// and writes responses as an array in the order of the requests
+func (router *Router) handleBatch(ctx *Context, body []byte) {
+	responses, err := router.processBatch(ctx.Request, body, nil)
+	if err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
 
-		var salt string
-		if err := ctx.Param(1, &salt); err != nil {
-			ctx.WriteError(InvalidParameterCode, err.Error())
-			return
-		}
+	ctx.writeBatch(responses)
+}
 
-		var signature string
-		if err := ctx.Param(2, &signature); err != nil {
-			ctx.WriteError(InvalidParameterCode, err.Error())
-			return
+func (router *Router) processBatch(req *http.Request, body []byte, session *Session) ([]*protocol.RPCResponse, *Error) {
+	var requests []json.RawMessage
+	if err := json.Unmarshal(body, &requests); err != nil {
+		return nil, &Error{
+			Code:    InvalidRequestCode,
+			Message: fmt.Sprintf("message body is not an rpc batch request: %s", err.Error()),
 		}
+	}
 
-		var params []*json.RawMessage
-		if err := ctx.Param(3, &params); err != nil {
-			ctx.WriteError(InvalidParameterCode, err.Error())
-			return
-		}
+	if len(requests) == 0 {
+		return nil, &Error{Code: InvalidRequestCode, Message: "batch request is empty"}
+	}
 
-		keys, err := GetSignPubKeys(router.blockchain, account)
-		if err != nil {
-			ctx.WriteError(InvalidParameterCode, err.Error())
-			return
+	if router.maxBatchSize > 0 && len(requests) > router.maxBatchSize {
+		return nil, &Error{
+			Code:    InvalidRequestCode,
+			Message: fmt.Sprintf("batch request is too large: %d > %d", len(requests), router.maxBatchSize),
 		}
+	}
 
-		valid, err := router.verifier.VerifySignedRequest(account, salt, signature, params, keys)
-		if err != nil {
-			ctx.WriteError(InvalidRequestCode, err.Error())
-			return
-		}
+	responses := make([]*protocol.RPCResponse, len(requests))
+	for i, raw := range requests {
+		responses[i] = router.process(req, raw, session)
+	}
+	return responses, nil
+}
 
-		if !valid {
-			ctx.WriteError(InvalidParameterCode, "signature is not valid")
-			return
-		}
+// process routes a single request on its own context and returns the response
+func (router *Router) process(req *http.Request, raw json.RawMessage, session *Session) *protocol.RPCResponse {
+	ctx := newBatchContext(req, session)
+	if ok := ctx.parseRaw(raw); ok {
+		router.route(Route{
+			API:    ctx.API(),
+			Method: ctx.Method(),
+		}, ctx)
+	}
 
-		handler(ctx, account, params)
+	if ctx.response == nil {
+		ctx.WriteError(InternalErrorCode, "no response has been written")
 	}
+	return ctx.response
 }
 
-func GetSignPubKeys(blockchain *scorumgo.Client, name string) ([][]byte, error) {
-	const prefix = "SCR"
+// HandleWebSocket serves rpc requests received over websocket,
+// every message is either a single call or a batch of calls
+func (router *Router) HandleWebSocket() http.Handler {
+	// no origin check, same as CORS of the http handler
+	return websocket.Server{Handler: router.serveWebSocket}
+}
+
+func (router *Router) serveWebSocket(conn *websocket.Conn) {
+	conn.MaxPayloadBytes = int(router.maxBodySize)
+
+	session := newSession(conn)
+	defer session.close()
 
-	extractKeys := func(keys types.StringInt64Map) ([][]byte, error) {
-		var out [][]byte
-		for key := range keys {
-			if strings.Index(key, prefix) != 0 {
-				return nil, fmt.Errorf("%s is not a valid key", key)
+	for {
+		var message []byte
+		if err := websocket.Message.Receive(conn, &message); err != nil {
+			if err != io.EOF {
+				log.WithError(err).Debug("websocket: failed to receive message")
 			}
-			key = key[len(prefix):]
-			keyWithChecksum := base58.Decode(key)
-			out = append(out, keyWithChecksum[:len(keyWithChecksum)-4])
+			return
 		}
-		return out, nil
-	}
 
-	accounts, err := blockchain.Database.GetAccounts(name)
-	if err != nil {
-		return nil, err
-	}
+		var out interface{}
+		if isBatch(message) {
+			responses, err := router.processBatch(conn.Request(), message, session)
+			if err != nil {
+				out = &protocol.RPCResponse{Error: &protocol.RPCError{Code: err.Code, Message: err.Message}}
+			} else {
+				out = responses
+			}
+		} else {
+			out = router.process(conn.Request(), message, session)
+		}
 
-	if len(accounts) == 0 {
-		return nil, errors.New("account not found")
+		if err := session.Send(out); err != nil {
+			log.WithError(err).Debug("websocket: failed to send response")
+			return
+		}
 	}
+}
 
-	var out [][]byte
-	account := accounts[0]
-
-	//owner
-	keys, err := extractKeys(account.Owner.KeyAuths)
-	if err != nil {
-		return nil, errors.Wrap(err, "extract owner keys failed")
-	}
-	out = append(out, keys...)
+// isBatch checks whether the message body is a JSON array
+func isBatch(body []byte) bool {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
 
-	//active
-	keys, err = extractKeys(account.Active.KeyAuths)
-	if err != nil {
-		return nil, errors.Wrap(err, "extract active keys failed")
-	}
-	out = append(out, keys...)
+// SignedAPI verifies the signed request and passes the account and its params to the handler
+func (router *Router) SignedAPI(handler SignedAPIHandler) APIHandler {
+	return router.Signed(func(ctx *Context) {
+		handler(ctx, ctx.Account(), ctx.SignedParams())
+	})
+}
 
-	//posting
-	keys, err = extractKeys(account.Posting.KeyAuths)
+func GetSignPubKeys(blockchain *scorumgo.Client, name string) ([][]byte, error) {
+	authorities, err := NewBlockchainAuthorityProvider(blockchain).GetAuthorities(name)
 	if err != nil {
-		return nil, errors.Wrap(err, "extract posting keys failed")
-	}
-	out = append(out, keys...)
-
-	if len(out) == 0 {
-		return nil, errors.New("sing keys not found")
+		return nil, err
 	}
-
-	return out, nil
+	return authorities.SignPubKeys()
 }
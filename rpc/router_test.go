@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/scorum/scorum-go"
@@ -14,9 +15,11 @@ import (
 	protocolHttp "github.com/scorum/scorum-go/transport/http"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
 )
 
 const MaxRequestSize = 25000000
+const MaxBatchSize = 20
 const NodeHTTPS = "https://testnet.scorum.com"
 const ChainID = "d3c1f19a4947c296446583f988c43fd1a83818fabaf3454a0020198cb361ebd2"
 
@@ -26,7 +29,7 @@ var client *scorumgo.Client
 func init() {
 	transport := protocolHttp.NewTransport(NodeHTTPS)
 	client = scorumgo.NewClient(transport)
-	router = NewRouter(client, NewVerifier(ChainID), MaxRequestSize)
+	router = NewRouter(client, NewVerifier(ChainID), MaxRequestSize, MaxBatchSize)
 }
 
 func TestRouter_ExistingRoute(t *testing.T) {
@@ -52,7 +55,7 @@ func TestRouter_NotFoundRoute(t *testing.T) {
 }
 
 func TestRouter_MaxRequestSize(t *testing.T) {
-	router := NewRouter(client, NewVerifier(ChainID), 1000)
+	router := NewRouter(client, NewVerifier(ChainID), 1000, MaxBatchSize)
 	route := Route{"api", "dummy"}
 
 	invoked := false
@@ -117,6 +120,162 @@ func TestRouter_HTTPMethod(t *testing.T) {
 	})
 }
 
+func TestRouter_Batch(t *testing.T) {
+	router := NewRouter(client, NewVerifier(ChainID), MaxRequestSize, 3)
+	router.Register(Route{"api", "echo"}, func(ctx *Context) {
+		var in string
+		if err := ctx.Param(0, &in); err != nil {
+			ctx.WriteError(InvalidParameterCode, err.Error())
+			return
+		}
+		ctx.WriteResult(in)
+	})
+	router.Register(Route{"api", "panic"}, func(ctx *Context) {
+		panic("boom")
+	})
+	router.Register(Route{"api", "empty"}, func(ctx *Context) {
+		ctx.WriteResult(nil)
+	})
+
+	handle := func(body string) []protocol.RPCResponse {
+		req := httptest.NewRequest("POST", "/", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+
+		router.Handle(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var out []protocol.RPCResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &out))
+		return out
+	}
+
+	t.Run("same_order", func(t *testing.T) {
+		out := handle(`[
+			{"id": 1, "method": "call", "params": ["api", "echo", ["first"]]},
+			{"id": 2, "method": "call", "params": ["api", "echo", ["second"]]},
+			{"id": 3, "method": "call", "params": ["api", "empty", []]}
+		]`)
+
+		require.Len(t, out, 3)
+		require.EqualValues(t, 1, out[0].ID)
+		require.Equal(t, `"first"`, string(*out[0].Result))
+		require.EqualValues(t, 2, out[1].ID)
+		require.Equal(t, `"second"`, string(*out[1].Result))
+		require.EqualValues(t, 3, out[2].ID)
+		require.Nil(t, out[2].Error)
+	})
+
+	t.Run("per_item_errors", func(t *testing.T) {
+		out := handle(`[
+			{"id": 1, "method": "call", "params": ["api", "panic", []]},
+			{"id": 2, "method": "call", "params": ["api", "echo", []]},
+			{"id": 3, "method": "call", "params": ["api", "echo", ["ok"]]}
+		]`)
+
+		require.Len(t, out, 3)
+		require.Equal(t, InternalErrorCode, out[0].Error.Code)
+		require.Equal(t, InvalidParameterCode, out[1].Error.Code)
+		require.Nil(t, out[2].Error)
+		require.Equal(t, `"ok"`, string(*out[2].Result))
+	})
+
+	t.Run("invalid_item", func(t *testing.T) {
+		out := handle(`[
+			{"id": 1, "method": "call1", "params": ["api", "echo", ["first"]]},
+			{"id": 2, "method": "call", "params": ["api", "dummy", []]},
+			42
+		]`)
+
+		require.Len(t, out, 3)
+		require.Equal(t, InvalidRequestCode, out[0].Error.Code)
+		require.Equal(t, RouteNotRegisteredCode, out[1].Error.Code)
+		require.Equal(t, InvalidRequestCode, out[2].Error.Code)
+	})
+
+	t.Run("too_large", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", bytes.NewBufferString(`[
+			{"id": 1, "method": "call", "params": ["api", "echo", ["1"]]},
+			{"id": 2, "method": "call", "params": ["api", "echo", ["2"]]},
+			{"id": 3, "method": "call", "params": ["api", "echo", ["3"]]},
+			{"id": 4, "method": "call", "params": ["api", "echo", ["4"]]}
+		]`))
+		w := httptest.NewRecorder()
+
+		router.Handle(w, req)
+
+		var rpcResp protocol.RPCResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rpcResp))
+		require.Equal(t, InvalidRequestCode, rpcResp.Error.Code)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", bytes.NewBufferString(`[]`))
+		w := httptest.NewRecorder()
+
+		router.Handle(w, req)
+
+		var rpcResp protocol.RPCResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rpcResp))
+		require.Equal(t, InvalidRequestCode, rpcResp.Error.Code)
+	})
+}
+
+func TestRouter_WebSocket(t *testing.T) {
+	router := NewRouter(client, NewVerifier(ChainID), MaxRequestSize, MaxBatchSize)
+
+	var session *Session
+	router.Register(Route{"api", "echo"}, func(ctx *Context) {
+		session = ctx.Session
+
+		var in string
+		if err := ctx.Param(0, &in); err != nil {
+			ctx.WriteError(InvalidParameterCode, err.Error())
+			return
+		}
+		ctx.WriteResult(in)
+	})
+
+	server := httptest.NewServer(router.HandleWebSocket())
+	defer server.Close()
+
+	conn, err := websocket.Dial(strings.Replace(server.URL, "http", "ws", 1), "", server.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	t.Run("single", func(t *testing.T) {
+		require.NoError(t, websocket.Message.Send(conn,
+			`{"id": 1, "method": "call", "params": ["api", "echo", ["first"]]}`))
+
+		var resp protocol.RPCResponse
+		require.NoError(t, websocket.JSON.Receive(conn, &resp))
+		require.EqualValues(t, 1, resp.ID)
+		require.Equal(t, `"first"`, string(*resp.Result))
+		require.NotNil(t, session)
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		require.NoError(t, websocket.Message.Send(conn, `[
+			{"id": 2, "method": "call", "params": ["api", "echo", ["second"]]},
+			{"id": 3, "method": "call", "params": ["api", "dummy", []]}
+		]`))
+
+		var resp []protocol.RPCResponse
+		require.NoError(t, websocket.JSON.Receive(conn, &resp))
+		require.Len(t, resp, 2)
+		require.Equal(t, `"second"`, string(*resp[0].Result))
+		require.Equal(t, RouteNotRegisteredCode, resp[1].Error.Code)
+	})
+
+	t.Run("notify", func(t *testing.T) {
+		require.NoError(t, session.Notify("subject", "data"))
+
+		var notice Notice
+		require.NoError(t, websocket.JSON.Receive(conn, &notice))
+		require.Equal(t, "notice", notice.Method)
+		require.Equal(t, []interface{}{"subject", "data"}, notice.Params)
+	})
+}
+
 func TestGetSignPubKey(t *testing.T) {
 	expected := "0366b11f2f616e44c59bcf082a3e00e77e6b9c0057161a62af3fc16176eb6ba104"
 
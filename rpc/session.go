@@ -0,0 +1,75 @@
+package rpc
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const sessionWriteTimeout = 10 * time.Second
+
+// Notice is a message pushed by the server to the websocket session
+type Notice struct {
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// Session is a websocket connection of the client,
+// it is available in the Context of the requests received over websocket
+type Session struct {
+	conn *websocket.Conn
+
+	mu      sync.Mutex
+	closed  bool
+	onClose []func()
+}
+
+func newSession(conn *websocket.Conn) *Session {
+	return &Session{
+		conn: conn,
+	}
+}
+
+// Send writes the message to the websocket connection
+func (s *Session) Send(v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.conn.SetWriteDeadline(time.Now().Add(sessionWriteTimeout))
+	return websocket.JSON.Send(s.conn, v)
+}
+
+// Notify pushes the notice of the given subject to the client
+func (s *Session) Notify(subject string, data interface{}) error {
+	return s.Send(Notice{
+		Method: "notice",
+		Params: []interface{}{subject, data},
+	})
+}
+
+// OnClose registers a callback invoked once the session is closed,
+// the callback is invoked immediately if the session is already closed
+func (s *Session) OnClose(f func()) {
+	s.mu.Lock()
+	if !s.closed {
+		s.onClose = append(s.onClose, f)
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+	f()
+}
+
+func (s *Session) close() {
+	s.mu.Lock()
+	s.closed = true
+	callbacks := s.onClose
+	s.onClose = nil
+	s.mu.Unlock()
+
+	for _, f := range callbacks {
+		f()
+	}
+	s.conn.Close()
+}
@@ -13,7 +13,8 @@ import (
 )
 
 type Verifier interface {
-	VerifyTransaction(tx *types.Transaction, pubKeys [][]byte) (bool, error)
+	// VerifyTransaction checks that the transaction signatures satisfy the authorities required by its operations
+	VerifyTransaction(tx *types.Transaction, authorities AuthorityProvider) (bool, error)
 	TransactionDigest(tx *types.Transaction) ([]byte, error)
 
 	VerifySignedRequest(account, salt, signature string, params []*json.RawMessage, pubKeys [][]byte) (bool, error)
@@ -28,18 +29,66 @@ type verifier struct {
 	chain string
 }
 
-func (v *verifier) VerifyTransaction(tx *types.Transaction, pubKeys [][]byte) (bool, error) {
+func (v *verifier) VerifyTransaction(tx *types.Transaction, authorities AuthorityProvider) (bool, error) {
 	digest, err := v.TransactionDigest(tx)
 	if err != nil {
 		return false, err
 	}
 
-	// transactions only with one signature are supported
-	if len(tx.Signatures) != 1 {
+	signers, err := recoverSigners(tx.Signatures, digest)
+	if err != nil || signers == nil {
+		return false, err
+	}
+
+	if len(tx.Operations) == 0 {
 		return false, nil
 	}
 
-	return verify.VerifyAny(pubKeys, tx.Signatures[0], digest)
+	// the highest authority required by the operations of the account
+	required := make(map[string]types.AuthorityLevel)
+	for _, op := range tx.Operations {
+		if _, ok := op.(*types.UnknownOperation); ok {
+			return false, nil
+		}
+
+		level := op.Type().RequiredAuthority()
+		if current, ok := required[op.GetAccount()]; !ok || level > current {
+			required[op.GetAccount()] = level
+		}
+	}
+
+	for account, level := range required {
+		ok, err := CheckAuthority(authorities, account, level, signers)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// recoverSigners returns public keys of the signatures,
+// nil is returned if any of the signatures is invalid or duplicated
+func recoverSigners(signatures []string, digest []byte) ([][]byte, error) {
+	if len(signatures) == 0 {
+		return nil, nil
+	}
+
+	signers := make([][]byte, 0, len(signatures))
+	seen := make(map[string]bool, len(signatures))
+	for _, signature := range signatures {
+		pubKey, err := verify.RecoverPublicKey(signature, digest)
+		if err != nil || pubKey == nil {
+			return nil, err
+		}
+
+		if seen[string(pubKey)] {
+			return nil, nil
+		}
+		seen[string(pubKey)] = true
+		signers = append(signers, pubKey)
+	}
+	return signers, nil
 }
 
 // TransactionDigest calculates digest of the given transaction
@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcutil/base58"
 	"github.com/scorum/scorum-go/sign"
 	scorumtype "github.com/scorum/scorum-go/types"
 	"github.com/stretchr/testify/require"
@@ -16,6 +18,27 @@ var (
 	trx    types.Transaction
 )
 
+type staticAuthorities map[string]*Authorities
+
+func (a staticAuthorities) GetAuthorities(account string) (*Authorities, error) {
+	authorities, ok := a[account]
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+	return authorities, nil
+}
+
+// encodePubKey encodes the public key the way it is stored in the blockchain, the checksum is not verified
+func encodePubKey(key *btcec.PrivateKey) string {
+	return pubKeyPrefix + base58.Encode(append(key.PubKey().SerializeCompressed(), 0, 0, 0, 0))
+}
+
+func newPrivateKey(t *testing.T) *btcec.PrivateKey {
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(t, err)
+	return key
+}
+
 func init() {
 	pubKey = []byte("1dbe5db4f9c3da58e429673ee9265254f1e5cc5962ba")
 
@@ -27,11 +50,17 @@ func init() {
 	}
 }
 
+func authoritiesOf(pubKey []byte) staticAuthorities {
+	key := pubKeyPrefix + base58.Encode(append(pubKey, 0, 0, 0, 0))
+	auth := scorumtype.Authority{WeightThreshold: 1, KeyAuths: scorumtype.StringInt64Map{key: 1}}
+	return staticAuthorities{"leonarda": {Owner: auth, Active: auth, Posting: auth}}
+}
+
 func TestVerifyInvalid(t *testing.T) {
 	verifier := NewVerifier(sign.ScorumChain.ID)
 	trx.Signatures = []string{"1f023c608241bdd20b56c87a7a3ee3919393714a036cd15eaad71a7fdd3077f27d23dbe5db4f9c3da58e429673ee9265254f1e5cc5962ba0f4024e58b83076a6d5"}
 
-	valid, err := verifier.VerifyTransaction(&trx, [][]byte{pubKey})
+	valid, err := verifier.VerifyTransaction(&trx, authoritiesOf(pubKey))
 	require.NoError(t, err)
 	require.False(t, valid)
 }
@@ -41,7 +70,7 @@ func TestVerifyManyInvalidSignatures(t *testing.T) {
 	signature := "1f023c608241bdd20b56c87a7a3ee3919393714a036cd15eaad71a7fdd3077f27d23dbe5db4f9c3da58e429673ee9265254f1e5cc5962ba0f4024e58b83076a6d5"
 	trx.Signatures = []string{signature, signature, signature, signature}
 
-	valid, err := verifier.VerifyTransaction(&trx, [][]byte{pubKey})
+	valid, err := verifier.VerifyTransaction(&trx, authoritiesOf(pubKey))
 	require.NoError(t, err)
 	require.False(t, valid)
 }
@@ -50,11 +79,113 @@ func TestVerifyInvalidSignature(t *testing.T) {
 	verifier := NewVerifier(sign.ScorumChain.ID)
 	trx.Signatures = []string{"1234"}
 
-	valid, err := verifier.VerifyTransaction(&trx, [][]byte{pubKey})
+	valid, err := verifier.VerifyTransaction(&trx, authoritiesOf(pubKey))
 	require.NoError(t, err)
 	require.False(t, valid)
 }
 
+func TestVerifyAuthority(t *testing.T) {
+	verifier := NewVerifier(sign.ScorumChain.ID)
+
+	owner, active, posting := newPrivateKey(t), newPrivateKey(t), newPrivateKey(t)
+	app, appActive, appOwner := newPrivateKey(t), newPrivateKey(t), newPrivateKey(t)
+	cosigner := newPrivateKey(t)
+
+	single := func(key *btcec.PrivateKey) scorumtype.Authority {
+		return scorumtype.Authority{WeightThreshold: 1, KeyAuths: scorumtype.StringInt64Map{encodePubKey(key): 1}}
+	}
+
+	authorities := staticAuthorities{
+		"leonarda": {
+			Owner:  single(owner),
+			Active: single(active),
+			Posting: scorumtype.Authority{
+				WeightThreshold: 1,
+				KeyAuths:        scorumtype.StringInt64Map{encodePubKey(posting): 1},
+				AccountAuths:    scorumtype.StringInt64Map{"app": 1},
+			},
+		},
+		"kristie": {
+			Owner: single(owner),
+			Active: scorumtype.Authority{
+				WeightThreshold: 2,
+				KeyAuths: scorumtype.StringInt64Map{
+					encodePubKey(active):   1,
+					encodePubKey(cosigner): 1,
+				},
+			},
+			Posting: single(posting),
+		},
+		"sheldon": {
+			Owner:   scorumtype.Authority{WeightThreshold: 1, AccountAuths: scorumtype.StringInt64Map{"app": 1}},
+			Active:  single(active),
+			Posting: single(posting),
+		},
+		"app": {
+			Owner:   single(appOwner),
+			Active:  single(appActive),
+			Posting: single(app),
+		},
+	}
+
+	signed := func(op types.Operation, keys ...*btcec.PrivateKey) *types.Transaction {
+		expires := time.Unix(0, 0)
+		tx := &types.Transaction{
+			Expiration: &scorumtype.Time{Time: &expires},
+			Operations: types.Operations{op},
+		}
+		digest, err := verifier.TransactionDigest(tx)
+		require.NoError(t, err)
+		for _, key := range keys {
+			tx.Signatures = append(tx.Signatures, hex.EncodeToString(sign.SignBufferSha256(digest, key.ToECDSA())))
+		}
+		return tx
+	}
+
+	follow := &types.FollowOperation{Account: "leonarda", Follow: "kristie"}
+	updateProfile := &types.UpdateProfileOperation{Account: "kristie"}
+	admin := &types.SetAccountTrustedAdminOperation{Account: "leonarda", BlogAccount: "kristie"}
+	adminByDelegate := &types.SetAccountTrustedAdminOperation{Account: "sheldon", BlogAccount: "kristie"}
+
+	cases := []struct {
+		name  string
+		tx    *types.Transaction
+		valid bool
+	}{
+		{"posting_by_posting", signed(follow, posting), true},
+		{"posting_by_active", signed(follow, active), true},
+		{"posting_by_owner", signed(follow, owner), true},
+		{"posting_by_delegate", signed(follow, app), true},
+		{"posting_by_stranger", signed(follow, cosigner), false},
+		{"active_by_posting", signed(updateProfile, posting), false},
+		{"active_below_threshold", signed(updateProfile, active), false},
+		{"active_multisig", signed(updateProfile, active, cosigner), true},
+		{"active_by_owner", signed(updateProfile, owner), true},
+		{"owner_by_active", signed(admin, active), false},
+		{"owner_by_delegate", signed(admin, app), false},
+		{"owner_by_owner", signed(admin, owner), true},
+		{"owner_by_delegate_posting", signed(adminByDelegate, app), false},
+		{"owner_by_delegate_active", signed(adminByDelegate, appActive), false},
+		{"owner_by_delegate_owner", signed(adminByDelegate, appOwner), true},
+		{"duplicated_signature", signed(follow, posting, posting), false},
+		{"not_signed", signed(follow), false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			valid, err := verifier.VerifyTransaction(c.tx, authorities)
+			require.NoError(t, err)
+			require.Equal(t, c.valid, valid)
+		})
+	}
+
+	t.Run("unknown_account", func(t *testing.T) {
+		tx := signed(&types.FollowOperation{Account: "azucena", Follow: "kristie"}, posting)
+		_, err := verifier.VerifyTransaction(tx, authorities)
+		require.Equal(t, ErrAccountNotFound, err)
+	})
+}
+
 func TestDigest(t *testing.T) {
 	verifier := NewVerifier(sign.ScorumChain.ID)
 	digest, err := verifier.TransactionDigest(&trx)
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
+)
+
+// AccountData is everything kept off-chain for the account
+type AccountData struct {
+	Account    string                     `json:"account"`
+	ExportedAt string                     `json:"exported"`
+	Data       map[string]json.RawMessage `json:"data"`
+	// Blobs are the urls of the media contents including the generated ones, e.g. thumbnails
+	Blobs []string `json:"blobs"`
+}
+
+// ErasureReport is the result of the account data erasure
+type ErasureReport struct {
+	Account string `json:"account"`
+	// Rows is the number of the erased rows in each section
+	Rows         map[string]int64 `json:"rows"`
+	BlobsRemoved []string         `json:"blobs_removed"`
+	// BlobErrors are the blobs failed to be removed
+	BlobErrors map[string]string `json:"blob_errors,omitempty"`
+}
+
+// ExportAccountDataEndpoint returns the off-chain data of the account, superadmin only
+func (blog *Blog) ExportAccountDataEndpoint(ctx *rpc.Context, account string, params []*json.RawMessage) {
+	if err := blog.checkPermission(blog.RolesStorage, account, db.RoleSuperadmin, ""); err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	var target string
+	if err := getParam(params, 0, &target); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	data, err := blog.ExportAccountData(target)
+	if err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	ctx.WriteResult(data)
+}
+
+func (blog *Blog) ExportAccountData(account string) (*AccountData, *rpc.Error) {
+	exists, err := blog.checkAccountExists(blog.DB.Write, account)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+	if !exists {
+		return nil, NewError(rpc.ProfileNotFoundCode, fmt.Sprintf("%s not found", account))
+	}
+
+	data, err := blog.AccountDataStorage.Export(account)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	IDs, err := blog.Blob.ListMedia(account)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	blobs := make([]string, len(IDs))
+	for idx, ID := range IDs {
+		blobs[idx] = blog.Blob.MediaURL(account, ID)
+	}
+
+	return &AccountData{
+		Account:    account,
+		ExportedAt: time.Now().UTC().Format(TimeLayout),
+		Data:       data,
+		Blobs:      blobs,
+	}, nil
+}
+
+// EraseAccountData deletes or anonymizes the off-chain data of the account and removes its media contents.
+// The rows are erased in a single transaction, the blobs are removed once it is committed
+func (blog *Blog) EraseAccountData(account string) (*ErasureReport, *rpc.Error) {
+	tx, err := blog.DB.Write.Beginx()
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+	defer tx.Rollback()
+
+	exists, err := blog.checkAccountExists(tx, account)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+	if !exists {
+		return nil, NewError(rpc.ProfileNotFoundCode, fmt.Sprintf("%s not found", account))
+	}
+
+	rows, err := blog.AccountDataStorage.InTx(tx).Erase(account)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	report := &ErasureReport{
+		Account:      account,
+		Rows:         rows,
+		BlobsRemoved: []string{},
+		BlobErrors:   make(map[string]string),
+	}
+
+	IDs, err := blog.Blob.ListMedia(account)
+	if err != nil {
+		return report, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	for _, ID := range IDs {
+		url := blog.Blob.MediaURL(account, ID)
+		if err := blog.Blob.DeleteMedia(account, ID); err != nil {
+			report.BlobErrors[url] = err.Error()
+			continue
+		}
+		report.BlobsRemoved = append(report.BlobsRemoved, url)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"gitlab.scorum.com/blog/api/broadcast"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
+	"gitlab.scorum.com/blog/api/utils/postgres"
+)
+
+func (blog *Blog) Mute(tx *broadcast.Tx, op types.Operation) *rpc.Error {
+	in := op.(*types.MuteOperation)
+	return blog.addToAccountList(tx.Tx, in.Account, in.Mute, db.MutedList)
+}
+
+func (blog *Blog) Unmute(tx *broadcast.Tx, op types.Operation) *rpc.Error {
+	in := op.(*types.UnmuteOperation)
+
+	if err := blog.AccountListsStorage.InTx(tx.Tx).Remove(in.Account, in.Unmute, db.MutedList); err != nil {
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+	return nil
+}
+
+// Block blocks the account and removes its follow of the blocking account
+func (blog *Blog) Block(tx *broadcast.Tx, op types.Operation) *rpc.Error {
+	in := op.(*types.BlockOperation)
+
+	if err := blog.addToAccountList(tx.Tx, in.Account, in.Block, db.BlockedList); err != nil {
+		return err
+	}
+
+	return blog.removeFollow(tx.Tx, in.Block, in.Account)
+}
+
+func (blog *Blog) Unblock(tx *broadcast.Tx, op types.Operation) *rpc.Error {
+	in := op.(*types.UnblockOperation)
+
+	if err := blog.AccountListsStorage.InTx(tx.Tx).Remove(in.Account, in.Unblock, db.BlockedList); err != nil {
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+	return nil
+}
+
+func (blog *Blog) addToAccountList(tx *sqlx.Tx, account, target string, list db.AccountList) *rpc.Error {
+	err := blog.AccountListsStorage.InTx(tx).Add(db.AccountListEntry{
+		Account:   account,
+		Target:    target,
+		List:      list,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		if foreignKeyError, _ := postgres.IsForeignKeyViolationError(err); foreignKeyError {
+			return NewError(rpc.ProfileNotFoundCode, fmt.Sprintf("%s not found", target))
+		}
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+	return nil
+}
+
+// GetMuted returns the accounts muted by the signer starting from the recently muted ones
+func (blog *Blog) GetMuted(ctx *rpc.Context, account string, params []*json.RawMessage) {
+	blog.getAccountList(ctx, account, db.MutedList, params)
+}
+
+// GetBlocked returns the accounts blocked by the signer starting from the recently blocked ones
+func (blog *Blog) GetBlocked(ctx *rpc.Context, account string, params []*json.RawMessage) {
+	blog.getAccountList(ctx, account, db.BlockedList, params)
+}
+
+func (blog *Blog) getAccountList(ctx *rpc.Context, account string, list db.AccountList, params []*json.RawMessage) {
+	var from uint32
+	if err := getParam(params, 0, &from); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	var limit uint32
+	if err := getParam(params, 1, &limit); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	if limit > maxLargePageSize {
+		ctx.WriteError(rpc.InvalidParameterCode, "invalid limit")
+		return
+	}
+
+	profiles, err := blog.doGetAccountList(account, list, from, limit)
+	if err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	ctx.WriteResult(profiles)
+}
+
+func (blog *Blog) doGetAccountList(account string, list db.AccountList, from, limit uint32) ([]*Profile, *rpc.Error) {
+	var profiles []*db.Profile
+	err := blog.DB.Read.Select(&profiles,
+		`SELECT p.account, display_name, location, bio, avatar_url, cover_url, p.created_at
+				FROM profiles p INNER JOIN account_lists l ON p.account = l.target
+				WHERE l.account = $1 AND l.list = $2
+				ORDER BY l.created_at DESC, l.target
+				LIMIT $3 OFFSET $4`,
+		account, list, limit, from)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+	return toAPIProfiles(profiles), nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/push"
+	"gitlab.scorum.com/blog/api/rpc"
+	. "gitlab.scorum.com/blog/core/domain"
+)
+
+func TestBlog_Mute(t *testing.T) {
+	defer cleanUp(t)
+
+	registerAccount(t, leonarda)
+	registerAccount(t, kristie)
+	registerAccount(t, sheldon)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	notifier := push.NewMockNotifier(mockCtrl)
+	handler.Notifier = notifier
+	notifier.EXPECT().NotifyStartedFollow(gomock.Any(), gomock.Any()).Times(2)
+
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: kristie, Follow: leonarda}))
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: kristie, Follow: sheldon}))
+
+	insertPost(t, leonarda, "post 1", DomainCom)
+	insertPost(t, sheldon, "post 1", DomainCom)
+
+	require.Nil(t, apply(t, handler.Mute, &types.MuteOperation{Account: kristie, Mute: sheldon}))
+	// muting twice does nothing
+	require.Nil(t, apply(t, handler.Mute, &types.MuteOperation{Account: kristie, Mute: sheldon}))
+
+	muted, err := handler.doGetAccountList(kristie, db.MutedList, 0, 10)
+	require.Nil(t, err)
+	require.Len(t, muted, 1)
+	require.Equal(t, sheldon, muted[0].Account)
+
+	posts, err := handler.doGetPostsFromNetwork(kristie, DomainCom, 0, 100)
+	require.Nil(t, err)
+	require.Len(t, posts, 1)
+	require.Equal(t, leonarda, posts[0].Account)
+
+	require.Nil(t, apply(t, handler.Unmute, &types.UnmuteOperation{Account: kristie, Unmute: sheldon}))
+
+	posts, err = handler.doGetPostsFromNetwork(kristie, DomainCom, 0, 100)
+	require.Nil(t, err)
+	require.Len(t, posts, 2)
+
+	t.Run("unknown_account", func(t *testing.T) {
+		rpcErr := apply(t, handler.Mute, &types.MuteOperation{Account: kristie, Mute: "unknown"})
+		require.NotNil(t, rpcErr)
+		require.Equal(t, rpc.ProfileNotFoundCode, rpcErr.Code)
+	})
+}
+
+func TestBlog_Block(t *testing.T) {
+	defer cleanUp(t)
+
+	registerAccount(t, leonarda)
+	registerAccount(t, kristie)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	notifier := push.NewMockNotifier(mockCtrl)
+	handler.Notifier = notifier
+	notifier.EXPECT().NotifyStartedFollow(gomock.Any(), gomock.Any()).Times(3)
+
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: kristie, Follow: leonarda}))
+
+	require.Nil(t, apply(t, handler.Block, &types.BlockOperation{Account: leonarda, Block: kristie}))
+
+	// the block removes the follow and its notification
+	followers, err := handler.doGetFollowers(leonarda, 0, 10)
+	require.Nil(t, err)
+	require.Empty(t, followers)
+
+	notifications, dbErr := handler.NotificationStorage.GetNotifications(leonarda, 100)
+	require.NoError(t, dbErr)
+	require.Empty(t, notifications)
+
+	blocked, err := handler.doGetAccountList(leonarda, db.BlockedList, 0, 10)
+	require.Nil(t, err)
+	require.Len(t, blocked, 1)
+	require.Equal(t, kristie, blocked[0].Account)
+
+	rpcErr := apply(t, handler.Follow, &types.FollowOperation{Account: kristie, Follow: leonarda})
+	require.NotNil(t, rpcErr)
+	require.Equal(t, rpc.AccountBlockedCode, rpcErr.Code)
+
+	// the blocked account is still able to be followed
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: leonarda, Follow: kristie}))
+
+	require.Nil(t, apply(t, handler.Unblock, &types.UnblockOperation{Account: leonarda, Unblock: kristie}))
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: kristie, Follow: leonarda}))
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
+)
+
+// AuditQuery is the filter of the audit_api.get_operations, empty fields are ignored
+type AuditQuery struct {
+	Account string `json:"account"`
+	OpType  string `json:"op_type"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	// Before is the id of the last entry of the previous page
+	Before int64 `json:"before"`
+	Limit  int   `json:"limit"`
+}
+
+func (q AuditQuery) toFilter() (db.AuditFilter, *rpc.Error) {
+	filter := db.AuditFilter{
+		Account: q.Account,
+		OpType:  q.OpType,
+		Before:  q.Before,
+		Limit:   q.Limit,
+	}
+
+	if filter.Limit <= 0 || filter.Limit > maxLargePageSize {
+		return filter, NewError(rpc.InvalidParameterCode, "invalid limit")
+	}
+
+	var err error
+	if q.From != "" {
+		if filter.From, err = time.Parse(TimeLayout, q.From); err != nil {
+			return filter, NewError(rpc.InvalidParameterCode, "invalid from")
+		}
+	}
+	if q.To != "" {
+		if filter.To, err = time.Parse(TimeLayout, q.To); err != nil {
+			return filter, NewError(rpc.InvalidParameterCode, "invalid to")
+		}
+	}
+
+	return filter, nil
+}
+
+// GetAuditOperations returns the audited operations starting from the latest one, superadmin only
+func (blog *Blog) GetAuditOperations(ctx *rpc.Context, account string, params []*json.RawMessage) {
+	if err := blog.checkPermission(blog.RolesStorage, account, db.RoleSuperadmin, ""); err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	var query AuditQuery
+	if err := getParam(params, 0, &query); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	filter, rpcErr := query.toFilter()
+	if rpcErr != nil {
+		ctx.WriteError(rpcErr.Code, rpcErr.Message)
+		return
+	}
+
+	entries, err := blog.AuditStorage.Find(filter)
+	if err != nil {
+		ctx.WriteError(rpc.InternalErrorCode, err.Error())
+		return
+	}
+
+	ctx.WriteResult(toAPIAuditEntries(entries))
+}
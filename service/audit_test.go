@@ -0,0 +1,31 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.scorum.com/blog/api/rpc"
+)
+
+func TestAuditQuery_ToFilter(t *testing.T) {
+	filter, err := AuditQuery{
+		Account: leonarda,
+		From:    "2018-09-01T00:00:00",
+		Before:  42,
+		Limit:   100,
+	}.toFilter()
+	require.Nil(t, err)
+	require.Equal(t, leonarda, filter.Account)
+	require.Equal(t, time.Date(2018, 9, 1, 0, 0, 0, 0, time.UTC), filter.From)
+	require.True(t, filter.To.IsZero())
+	require.Equal(t, int64(42), filter.Before)
+
+	_, err = AuditQuery{Limit: maxLargePageSize + 1}.toFilter()
+	require.NotNil(t, err)
+	require.Equal(t, rpc.InvalidParameterCode, err.Code)
+
+	_, err = AuditQuery{To: "yesterday", Limit: 10}.toFilter()
+	require.NotNil(t, err)
+	require.Equal(t, rpc.InvalidParameterCode, err.Code)
+}
@@ -1,26 +1,53 @@
 package service
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"gitlab.scorum.com/blog/api/broadcast"
 	"gitlab.scorum.com/blog/api/broadcast/types"
 	"gitlab.scorum.com/blog/api/db"
 	"gitlab.scorum.com/blog/api/rpc"
 	"gitlab.scorum.com/blog/api/utils/postgres"
 )
 
-func (blog *Blog) AddToBlacklistAdmin(op types.Operation) *rpc.Error {
+func (blog *Blog) AddToBlacklistAdmin(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.AddToBlacklistAdminOperation)
 
-	if in.Account != blog.Config.Admin {
-		return NewError(rpc.AccessDeniedCode, "access denied")
+	if err := blog.checkPermission(blog.RolesStorage.InTx(tx.Tx), in.Account, db.RoleModerator, ""); err != nil {
+		return err
+	}
+
+	reason := db.BlacklistReason(in.Reason)
+	if reason == "" {
+		reason = db.BlacklistReasonOther
+	}
+
+	if !reason.IsValid() {
+		return NewError(rpc.InvalidParameterCode, fmt.Sprintf("reason %s is invalid", in.Reason))
 	}
 
-	return blog.doAddToBlacklist(in.BlogAccount, in.Permlink)
+	return blog.doAddToBlacklist(tx.Tx, db.BlacklistEntry{
+		Account:   in.BlogAccount,
+		Permlink:  in.Permlink,
+		Reason:    reason,
+		Note:      in.Note,
+		AddedBy:   sql.NullString{String: in.Account, Valid: true},
+		CreatedAt: pq.NullTime{Time: time.Now().UTC(), Valid: true},
+	})
 }
 
-func (blog *Blog) doAddToBlacklist(account, permlink string) *rpc.Error {
-	_, err := blog.DB.Write.Exec(`INSERT INTO blacklist VALUES($1, $2)
-								   ON CONFLICT DO NOTHING`, account, permlink)
+// doAddToBlacklist adds the entry, the reason and the note of the existing entry are updated
+func (blog *Blog) doAddToBlacklist(tx *sqlx.Tx, entry db.BlacklistEntry) *rpc.Error {
+	_, err := tx.NamedExec(`INSERT INTO blacklist (account, permlink, reason, note, added_by, created_at)
+								   VALUES (:account, :permlink, :reason, :note, :added_by, :created_at)
+								   ON CONFLICT (account, permlink) DO UPDATE
+								   SET reason = :reason, note = :note, added_by = :added_by`, entry)
 
 	if err != nil {
 		if isErr, constraint := postgres.IsForeignKeyViolationError(err); isErr && constraint == "blacklist_account_fk" {
@@ -32,14 +59,14 @@ func (blog *Blog) doAddToBlacklist(account, permlink string) *rpc.Error {
 	return nil
 }
 
-func (blog *Blog) RemoveFromBlacklistAdmin(op types.Operation) *rpc.Error {
+func (blog *Blog) RemoveFromBlacklistAdmin(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.RemoveFromBlacklistAdminOperation)
 
-	if in.Account != blog.Config.Admin {
-		return NewError(rpc.AccessDeniedCode, "access denied")
+	if err := blog.checkPermission(blog.RolesStorage.InTx(tx.Tx), in.Account, db.RoleModerator, ""); err != nil {
+		return err
 	}
 
-	rows, err := blog.DB.Write.Exec(`DELETE FROM blacklist WHERE account = $1 AND permlink = $2`, in.BlogAccount, in.Permlink)
+	rows, err := tx.Exec(`DELETE FROM blacklist WHERE account = $1 AND permlink = $2`, in.BlogAccount, in.Permlink)
 	if err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
 	}
@@ -78,9 +105,10 @@ func (blog *Blog) IsBlacklisted(ctx *rpc.Context) {
 	ctx.WriteResult(isBlacklisted)
 }
 
+// checkIsBlacklisted reports whether the post or every post of the account is blacklisted
 func (blog *Blog) checkIsBlacklisted(account, permlink string) (*bool, *rpc.Error) {
 	var exists bool
-	err := blog.DB.Read.Get(&exists, `SELECT EXISTS(SELECT * FROM blacklist WHERE account = $1 AND permlink = $2)`, account, permlink)
+	err := blog.DB.Read.Get(&exists, `SELECT EXISTS(SELECT * FROM blacklist WHERE account = $1 AND permlink IN ($2, ''))`, account, permlink)
 	if err != nil {
 		return nil, WrapError(rpc.InternalErrorCode, err)
 	}
@@ -88,6 +116,7 @@ func (blog *Blog) checkIsBlacklisted(account, permlink string) (*bool, *rpc.Erro
 	return &exists, nil
 }
 
+// GetBlacklist returns the blacklisted posts in the order they have been added
 func (blog *Blog) GetBlacklist(ctx *rpc.Context) {
 	var from uint32
 	if err := ctx.Param(0, &from); err != nil {
@@ -118,10 +147,80 @@ func (blog *Blog) GetBlacklist(ctx *rpc.Context) {
 func (blog *Blog) doGetBlacklist(from uint32, limit uint16) ([]*PostID, *rpc.Error) {
 	var entries []*db.PostID
 
-	err := blog.DB.Read.Select(&entries, `SELECT account, permlink FROM blacklist LIMIT $1 OFFSET $2`, limit, from)
+	err := blog.DB.Read.Select(&entries, `SELECT account, permlink FROM blacklist ORDER BY id LIMIT $1 OFFSET $2`, limit, from)
 	if err != nil {
 		return nil, WrapError(rpc.InternalErrorCode, err)
 	}
 
 	return toAPIPostIDs(entries), nil
 }
+
+// BlacklistQuery filters the blacklist_api.get_blacklist_page entries, empty filters match every entry
+type BlacklistQuery struct {
+	Account string `json:"account"`
+	Reason  string `json:"reason"`
+	// Cursor is the next_cursor of the previous page
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+// GetBlacklistPage returns the blacklist entries with the reason and the note starting from the latest one,
+// the entries are filtered by the account and the reason and paginated by the cursor, the legacy entries without created_at go last
+func (blog *Blog) GetBlacklistPage(ctx *rpc.Context) {
+	var query BlacklistQuery
+	if err := ctx.Param(0, &query); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	page, err := blog.doGetBlacklistPage(query)
+	if err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	ctx.WriteResult(page)
+}
+
+func (blog *Blog) doGetBlacklistPage(query BlacklistQuery) (*BlacklistPage, *rpc.Error) {
+	if query.Limit < 0 {
+		return nil, NewError(rpc.InvalidParameterCode, "invalid limit")
+	}
+	limit := uint32(query.Limit)
+
+	after, keys, rpcErr := pageParams(query.Cursor, 1, limit)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	var afterID int64
+	if after.Valid {
+		var err error
+		if afterID, err = strconv.ParseInt(keys[0], 10, 64); err != nil {
+			return nil, NewError(rpc.InvalidParameterCode, "invalid cursor")
+		}
+	}
+
+	var entries []*db.BlacklistEntry
+	err := blog.DB.Read.Select(&entries,
+		`SELECT id, account, permlink, reason, note, added_by, created_at FROM blacklist
+				WHERE ($1 = '' OR account = $1) AND ($2 = '' OR reason = $2)
+					AND ($3::TIMESTAMP IS NULL OR (COALESCE(created_at, 'epoch'), id) < ($3, $4))
+				ORDER BY COALESCE(created_at, 'epoch') DESC, id DESC
+				LIMIT $5`, query.Account, query.Reason, after, afterID, limit)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	page := BlacklistPage{Items: toAPIBlacklistEntries(entries)}
+	if len(entries) == int(limit) {
+		last := entries[len(entries)-1]
+		listedAt := time.Unix(0, 0).UTC()
+		if last.CreatedAt.Valid {
+			listedAt = last.CreatedAt.Time
+		}
+		page.NextCursor = pageCursor{Time: listedAt, Keys: []string{strconv.FormatInt(last.ID, 10)}}.encode()
+	}
+
+	return &page, nil
+}
@@ -1,10 +1,13 @@
 package service
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
 )
 
 func TestBlog_AddToBlacklistAdmin(t *testing.T) {
@@ -18,8 +21,8 @@ func TestBlog_AddToBlacklistAdmin(t *testing.T) {
 	}
 
 	// It is normal to blacklist one post twice
-	require.Nil(t, handler.AddToBlacklistAdmin(op))
-	require.Nil(t, handler.AddToBlacklistAdmin(op))
+	require.Nil(t, apply(t, handler.AddToBlacklistAdmin, op))
+	require.Nil(t, apply(t, handler.AddToBlacklistAdmin, op))
 	list, err := handler.doGetBlacklist(0, 100)
 	require.Nil(t, err)
 	require.Len(t, list, 1)
@@ -49,8 +52,15 @@ func TestBlog_AddToBlacklistAdmin_ValidationTest(t *testing.T) {
 	})
 
 	t.Run("empty_permlink", func(t *testing.T) {
+		// blacklists every post of the blog account
 		cop := op
 		cop.Permlink = ""
+		require.NoError(t, validate.Struct(cop))
+	})
+
+	t.Run("too_long_note", func(t *testing.T) {
+		cop := op
+		cop.Note = strings.Repeat("a", 501)
 		require.Error(t, validate.Struct(cop))
 	})
 }
@@ -66,10 +76,10 @@ func TestBlog_RemoveFromBlacklistAdmin(t *testing.T) {
 	}
 
 	// Remove not existing post
-	require.NotNil(t, handler.RemoveFromBlacklistAdmin(op))
+	require.NotNil(t, apply(t, handler.RemoveFromBlacklistAdmin, op))
 
 	// Ban post
-	require.Nil(t, handler.AddToBlacklistAdmin(&types.AddToBlacklistAdminOperation{
+	require.Nil(t, apply(t, handler.AddToBlacklistAdmin, &types.AddToBlacklistAdminOperation{
 		Account:     leonarda,
 		BlogAccount: op.BlogAccount,
 		Permlink:    op.Permlink,
@@ -79,7 +89,7 @@ func TestBlog_RemoveFromBlacklistAdmin(t *testing.T) {
 	require.Len(t, list, 1)
 
 	// Remove existing post
-	require.Nil(t, handler.RemoveFromBlacklistAdmin(op))
+	require.Nil(t, apply(t, handler.RemoveFromBlacklistAdmin, op))
 
 	list, err = handler.doGetBlacklist(0, 100)
 	require.Nil(t, err)
@@ -110,9 +120,10 @@ func TestBlog_RemoveFromBlacklistAdmin_ValidationTest(t *testing.T) {
 	})
 
 	t.Run("empty_permlink", func(t *testing.T) {
+		// removes the entry blacklisting every post of the blog account
 		cop := op
 		cop.Permlink = ""
-		require.Error(t, validate.Struct(cop))
+		require.NoError(t, validate.Struct(cop))
 	})
 }
 
@@ -127,12 +138,88 @@ func TestBlog_GetBlacklist(t *testing.T) {
 		Permlink:    "permlink",
 	}
 
-	require.Nil(t, handler.AddToBlacklistAdmin(op))
+	require.Nil(t, apply(t, handler.AddToBlacklistAdmin, op))
 	list, err := handler.doGetBlacklist(0, 100)
 	require.Nil(t, err)
 	require.Len(t, list, 1)
 	require.Equal(t, list[0].Permlink, op.Permlink)
 	require.Equal(t, list[0].Account, op.BlogAccount)
+
+	page, err := handler.doGetBlacklistPage(BlacklistQuery{Limit: 100})
+	require.Nil(t, err)
+	require.Len(t, page.Items, 1)
+	require.Equal(t, string(db.BlacklistReasonOther), page.Items[0].Reason)
+	require.Equal(t, leonarda, page.Items[0].AddedBy)
+	require.NotEmpty(t, page.Items[0].CreatedAt)
+
+	t.Run("invalid_reason", func(t *testing.T) {
+		cop := *op
+		cop.Reason = "boring"
+		err := apply(t, handler.AddToBlacklistAdmin, &cop)
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		require.Nil(t, apply(t, handler.AddToBlacklistAdmin, &types.AddToBlacklistAdminOperation{
+			Account:     leonarda,
+			BlogAccount: leonarda,
+			Permlink:    "spam",
+			Reason:      string(db.BlacklistReasonSpam),
+			Note:        "links to a casino",
+		}))
+
+		page, err := handler.doGetBlacklistPage(BlacklistQuery{Reason: string(db.BlacklistReasonSpam), Limit: 100})
+		require.Nil(t, err)
+		require.Len(t, page.Items, 1)
+		require.Equal(t, "links to a casino", page.Items[0].Note)
+
+		page, err = handler.doGetBlacklistPage(BlacklistQuery{Account: sheldon, Limit: 100})
+		require.Nil(t, err)
+		require.Len(t, page.Items, 1)
+		require.Equal(t, op.Permlink, page.Items[0].Permlink)
+	})
+
+	t.Run("cursor", func(t *testing.T) {
+		page, err := handler.doGetBlacklistPage(BlacklistQuery{Limit: 1})
+		require.Nil(t, err)
+		require.Len(t, page.Items, 1)
+		require.Equal(t, "spam", page.Items[0].Permlink)
+		require.NotEmpty(t, page.NextCursor)
+
+		page, err = handler.doGetBlacklistPage(BlacklistQuery{Cursor: page.NextCursor, Limit: 1})
+		require.Nil(t, err)
+		require.Len(t, page.Items, 1)
+		require.Equal(t, op.Permlink, page.Items[0].Permlink)
+
+		page, err = handler.doGetBlacklistPage(BlacklistQuery{Cursor: page.NextCursor, Limit: 1})
+		require.Nil(t, err)
+		require.Empty(t, page.Items)
+	})
+}
+
+func TestBlog_AddToBlacklistAdmin_Author(t *testing.T) {
+	defer cleanUp(t)
+
+	registerAccount(t, sheldon)
+	require.Nil(t, apply(t, handler.AddToBlacklistAdmin, &types.AddToBlacklistAdminOperation{
+		Account:     leonarda,
+		BlogAccount: sheldon,
+		Reason:      string(db.BlacklistReasonSpam),
+	}))
+
+	isBlacklisted, err := handler.checkIsBlacklisted(sheldon, "any-post")
+	require.Nil(t, err)
+	require.True(t, *isBlacklisted)
+
+	require.Nil(t, apply(t, handler.RemoveFromBlacklistAdmin, &types.RemoveFromBlacklistAdminOperation{
+		Account:     leonarda,
+		BlogAccount: sheldon,
+	}))
+
+	isBlacklisted, err = handler.checkIsBlacklisted(sheldon, "any-post")
+	require.Nil(t, err)
+	require.False(t, *isBlacklisted)
 }
 
 func TestBlog_IsBlacklisted(t *testing.T) {
@@ -145,13 +232,13 @@ func TestBlog_IsBlacklisted(t *testing.T) {
 		Permlink:    "permlink",
 	}
 
-	require.Nil(t, handler.AddToBlacklistAdmin(op))
+	require.Nil(t, apply(t, handler.AddToBlacklistAdmin, op))
 
 	isBlacklisted, err := handler.checkIsBlacklisted(op.Account, op.Permlink)
 	require.Nil(t, err)
 	require.True(t, *isBlacklisted)
 
-	require.Nil(t, handler.RemoveFromBlacklistAdmin(&types.RemoveFromBlacklistAdminOperation{
+	require.Nil(t, apply(t, handler.RemoveFromBlacklistAdmin, &types.RemoveFromBlacklistAdminOperation{
 		Account:     leonarda,
 		BlogAccount: op.Account,
 		Permlink:    op.Permlink,
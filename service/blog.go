@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
@@ -15,6 +16,7 @@ import (
 	"gitlab.scorum.com/blog/api/db"
 	"gitlab.scorum.com/blog/api/push"
 	"gitlab.scorum.com/blog/api/rpc"
+	"gitlab.scorum.com/blog/api/subscription"
 	"gopkg.in/go-playground/validator.v9"
 )
 
@@ -46,26 +48,59 @@ type Database struct {
 }
 
 type Config struct {
-	Admin                   string `yaml:"admin"`
-	NotificationsLimit      int    `yaml:"notifications_limit"`
-	UnsubscribeApiJwtSecret string `yaml:"unsubscribe_api_jwt_secret"`
-	MaxFollow               int    `yaml:"max_follow"`
+	// Admin is the bootstrap superadmin, the account passes every permission check without a role grant
+	Admin                   string            `yaml:"admin"`
+	NotificationsLimit      int               `yaml:"notifications_limit"`
+	UnsubscribeApiJwtSecret string            `yaml:"unsubscribe_api_jwt_secret"`
+	MaxFollow               int               `yaml:"max_follow"`
+	Moderation              ModerationConfig  `yaml:"moderation"`
+	Suggestions             SuggestionsConfig `yaml:"suggestions"`
+	// AuditRetention is the lifetime of the audited operations, 0 keeps them forever
+	AuditRetention time.Duration `yaml:"audit_retention" default:"2160h"`
+}
+
+// ModerationConfig configures when the posts are put into the moderation queue
+type ModerationConfig struct {
+	// DownvoteReasons are counted towards the DownvoteThreshold, the rest of the reasons are ignored,
+	// 0 threshold disables the downvote cases
+	DownvoteReasons   []db.DownvoteReason `yaml:"downvote_reasons"`
+	DownvoteThreshold int                 `yaml:"downvote_threshold"`
+	// UniquenessThreshold opens a case for the checked posts with the lower uniqueness, 0 disables the check
+	UniquenessThreshold float32 `yaml:"uniqueness_threshold"`
+}
+
+// SuggestionsConfig configures the follow suggestions job
+type SuggestionsConfig struct {
+	// RefreshInterval is the interval the suggestions of every account are recomputed with, 0 disables the job
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+	// Limit is the number of the suggestions kept per account and domain
+	Limit int `yaml:"limit"`
 }
 
 type Blog struct {
-	DB                      Database
-	Config                  Config
-	Blockchain              *scorumgo.Client
-	Blob                    *blob.Service
-	Notifier                push.Notifier
-	PushRegistrationStorage *db.PushTokensStorage
-	NotificationStorage     *db.NotificationStorage
-	DownvotesStorage        *db.DownvotesStorage
+	DB                             Database
+	Config                         Config
+	Blockchain                     *scorumgo.Client
+	Blob                           *blob.Service
+	Notifier                       push.Notifier
+	PushRegistrationStorage        *db.PushTokensStorage
+	NotificationStorage            *db.NotificationStorage
+	DownvotesStorage               *db.DownvotesStorage
+	AuditStorage                   *db.AuditStorage
+	RolesStorage                   *db.RolesStorage
+	SuspensionsStorage             *db.SuspensionsStorage
+	ModerationStorage              *db.ModerationStorage
+	AccountDataStorage             *db.AccountDataStorage
+	NotificationPreferencesStorage *db.NotificationPreferencesStorage
+	AccountListsStorage            *db.AccountListsStorage
+	SuggestionsStorage             *db.SuggestionsStorage
+	TopicSubscriptionsStorage      *db.TopicSubscriptionsStorage
+	Subscriptions                  *subscription.Hub
 }
 
-func (blog *Blog) getMediaByUrl(account, url string) (*db.Media, error) {
+func (blog *Blog) getMediaByUrl(q sqlx.Queryer, account, url string) (*db.Media, error) {
 	var media db.Media
-	err := blog.DB.Read.Get(&media,
+	err := sqlx.Get(q, &media,
 		`SELECT * FROM media WHERE account = $1 AND url = $2`,
 		account, url)
 
@@ -75,9 +110,9 @@ func (blog *Blog) getMediaByUrl(account, url string) (*db.Media, error) {
 	return &media, err
 }
 
-func (blog *Blog) checkAccountExists(account string) (bool, error) {
+func (blog *Blog) checkAccountExists(q sqlx.Queryer, account string) (bool, error) {
 	var exists bool
-	err := blog.DB.Read.Get(&exists, `SELECT EXISTS(SELECT * FROM profiles WHERE account = $1)`, account)
+	err := sqlx.Get(q, &exists, `SELECT EXISTS(SELECT * FROM profiles WHERE account = $1)`, account)
 	return exists, err
 }
 
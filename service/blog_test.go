@@ -8,7 +8,10 @@ import (
 	"github.com/scorum/scorum-go/transport/http"
 	"github.com/stretchr/testify/require"
 	"gitlab.scorum.com/blog/api/blob"
+	"gitlab.scorum.com/blog/api/broadcast"
+	"gitlab.scorum.com/blog/api/broadcast/types"
 	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
 	"gitlab.scorum.com/blog/api/utils"
 )
 
@@ -41,6 +44,10 @@ func init() {
 			Admin:              leonarda,
 			NotificationsLimit: notificationsLimit,
 			MaxFollow:          followsLimit,
+			Moderation: ModerationConfig{
+				DownvoteReasons:   []db.DownvoteReason{db.DownvoteReasonSpam, db.DownvoteReasonPlagiarism},
+				DownvoteThreshold: 2,
+			},
 		},
 	}
 }
@@ -56,6 +63,8 @@ func cleanUp(t *testing.T) {
 	require.NoError(t, err)
 	_, err = dbWrite.Exec("DELETE FROM profile_settings")
 	require.NoError(t, err)
+	_, err = dbWrite.Exec("DELETE FROM notification_preferences")
+	require.NoError(t, err)
 	_, err = dbWrite.Exec("DELETE FROM posts_plagiarism")
 	require.NoError(t, err)
 	_, err = dbWrite.Exec("DELETE FROM categories")
@@ -66,8 +75,26 @@ func cleanUp(t *testing.T) {
 	require.NoError(t, err)
 	_, err = dbWrite.Exec("DELETE FROM followers")
 	require.NoError(t, err)
+	_, err = dbWrite.Exec("DELETE FROM unfollows")
+	require.NoError(t, err)
+	_, err = dbWrite.Exec("DELETE FROM account_lists")
+	require.NoError(t, err)
+	_, err = dbWrite.Exec("DELETE FROM follow_suggestions")
+	require.NoError(t, err)
+	_, err = dbWrite.Exec("DELETE FROM topic_subscriptions")
+	require.NoError(t, err)
 	_, err = dbWrite.Exec("DELETE FROM media")
 	require.NoError(t, err)
+	_, err = dbWrite.Exec("DELETE FROM moderation_cases")
+	require.NoError(t, err)
+	_, err = dbWrite.Exec("DELETE FROM downvotes")
+	require.NoError(t, err)
+	_, err = dbWrite.Exec("DELETE FROM suspensions")
+	require.NoError(t, err)
+	_, err = dbWrite.Exec("DELETE FROM profile_history")
+	require.NoError(t, err)
+	_, err = dbWrite.Exec("DELETE FROM roles")
+	require.NoError(t, err)
 	_, err = dbWrite.Exec("DELETE FROM profiles")
 	require.NoError(t, err)
 }
@@ -77,6 +104,20 @@ var (
 	dbRead  *sqlx.DB
 )
 
+// apply invokes the transaction handler within a database transaction committed on success
+func apply(t *testing.T, h func(tx *broadcast.Tx, op types.Operation) *rpc.Error, op types.Operation) *rpc.Error {
+	dbTx, err := dbWrite.Beginx()
+	require.NoError(t, err)
+
+	tx := broadcast.NewTx(dbTx, false)
+	if err := h(tx, op); err != nil {
+		require.NoError(t, tx.Rollback())
+		return err
+	}
+	require.NoError(t, tx.Commit())
+	return nil
+}
+
 func registerAccount(t *testing.T, account string) {
 	_, err := dbWrite.Exec(
 		`INSERT INTO profiles(account, display_name) VALUES($1, $2) ON CONFLICT DO NOTHING`,
@@ -95,5 +136,14 @@ func TestMain(m *testing.M) {
 		handler.DB.Read = dbRead
 
 		handler.NotificationStorage = db.NewNotificationsStorage(dbWrite)
+		handler.RolesStorage = db.NewRolesStorage(dbWrite)
+		handler.SuspensionsStorage = db.NewSuspensionsStorage(dbWrite)
+		handler.DownvotesStorage = db.NewDownvotesStorage(dbWrite)
+		handler.ModerationStorage = db.NewModerationStorage(dbWrite)
+		handler.AccountDataStorage = db.NewAccountDataStorage(dbWrite)
+		handler.NotificationPreferencesStorage = db.NewNotificationPreferencesStorage(dbWrite)
+		handler.AccountListsStorage = db.NewAccountListsStorage(dbWrite)
+		handler.SuggestionsStorage = db.NewSuggestionsStorage(dbWrite)
+		handler.TopicSubscriptionsStorage = db.NewTopicSubscriptionsStorage(dbWrite)
 	})
 }
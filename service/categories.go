@@ -4,20 +4,21 @@ import (
 	"database/sql"
 	"strings"
 
+	"gitlab.scorum.com/blog/api/broadcast"
 	"gitlab.scorum.com/blog/api/broadcast/types"
 	"gitlab.scorum.com/blog/api/db"
 	"gitlab.scorum.com/blog/api/rpc"
 	"gitlab.scorum.com/blog/api/utils/postgres"
 )
 
-func (blog *Blog) AddCategoryAdmin(op types.Operation) *rpc.Error {
+func (blog *Blog) AddCategoryAdmin(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.AddCategoryAdminOperation)
 
-	if in.Account != blog.Config.Admin {
-		return NewError(rpc.AccessDeniedCode, "access denied")
+	if err := blog.checkPermission(blog.RolesStorage.InTx(tx.Tx), in.Account, db.RoleCategoryEditor, in.Domain); err != nil {
+		return err
 	}
 
-	_, err := blog.DB.Write.NamedExec(`SELECT add_category(:domain, :label, :localization_key)`,
+	_, err := tx.NamedExec(`SELECT add_category(:domain, :label, :localization_key)`,
 		db.Category{
 			Domain:          in.Domain,
 			Label:           in.Label,
@@ -37,14 +38,14 @@ func (blog *Blog) AddCategoryAdmin(op types.Operation) *rpc.Error {
 	return nil
 }
 
-func (blog *Blog) UpdateCategoryAdmin(op types.Operation) *rpc.Error {
+func (blog *Blog) UpdateCategoryAdmin(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.UpdateCategoryAdminOperation)
 
-	if in.Account != blog.Config.Admin {
-		return NewError(rpc.AccessDeniedCode, "access denied")
+	if err := blog.checkPermission(blog.RolesStorage.InTx(tx.Tx), in.Account, db.RoleCategoryEditor, in.Domain); err != nil {
+		return err
 	}
 
-	_, err := blog.DB.Write.NamedExec(`SELECT update_category(:domain, :label, :order, :localization_key)`,
+	_, err := tx.NamedExec(`SELECT update_category(:domain, :label, :order, :localization_key)`,
 		db.Category{
 			Domain:          in.Domain,
 			Label:           in.Label,
@@ -62,14 +63,14 @@ func (blog *Blog) UpdateCategoryAdmin(op types.Operation) *rpc.Error {
 	return nil
 }
 
-func (blog *Blog) RemoveCategoryAdmin(op types.Operation) *rpc.Error {
+func (blog *Blog) RemoveCategoryAdmin(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.RemoveCategoryAdminOperation)
 
-	if in.Account != blog.Config.Admin {
-		return NewError(rpc.AccessDeniedCode, "access denied")
+	if err := blog.checkPermission(blog.RolesStorage.InTx(tx.Tx), in.Account, db.RoleCategoryEditor, in.Domain); err != nil {
+		return err
 	}
 
-	_, err := blog.DB.Write.Exec(`SELECT remove_category($1, $2)`, in.Domain, in.Label)
+	_, err := tx.Exec(`SELECT remove_category($1, $2)`, in.Domain, in.Label)
 	if err != nil {
 		if isInvalidDomainValueErr(err) {
 			return NewError(rpc.InvalidParameterCode, "domain is invalid")
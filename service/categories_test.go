@@ -22,17 +22,17 @@ func TestBlog_AddCategory(t *testing.T) {
 	t.Run("wrong_domain", func(t *testing.T) {
 		cop := *op
 		cop.Domain = "not_domain"
-		err := handler.AddCategoryAdmin(&cop)
+		err := apply(t, handler.AddCategoryAdmin, &cop)
 		require.NotNil(t, err)
 		require.Equal(t, err.Code, rpc.InvalidParameterCode)
 	})
 
 	t.Run("first", func(t *testing.T) {
-		require.Nil(t, handler.AddCategoryAdmin(op))
+		require.Nil(t, apply(t, handler.AddCategoryAdmin, op))
 	})
 
 	t.Run("second", func(t *testing.T) {
-		err := handler.AddCategoryAdmin(op)
+		err := apply(t, handler.AddCategoryAdmin, op)
 		require.NotNil(t, err)
 		require.Equal(t, err.Code, rpc.CategoryAlreadyExistsCode)
 	})
@@ -40,7 +40,7 @@ func TestBlog_AddCategory(t *testing.T) {
 	t.Run("not_admin", func(t *testing.T) {
 		cop := *op
 		cop.Account = sheldon
-		err := handler.AddCategoryAdmin(&cop)
+		err := apply(t, handler.AddCategoryAdmin, &cop)
 		require.NotNil(t, err)
 		require.Equal(t, err.Code, rpc.AccessDeniedCode)
 	})
@@ -89,7 +89,7 @@ func TestBlog_UpdateCategory(t *testing.T) {
 			Label:           strconv.Itoa(i),
 			LocalizationKey: "me.soccer",
 		}
-		require.Nil(t, handler.AddCategoryAdmin(addOp))
+		require.Nil(t, apply(t, handler.AddCategoryAdmin, addOp))
 	}
 
 	updateOp := &types.UpdateCategoryAdminOperation{
@@ -101,7 +101,7 @@ func TestBlog_UpdateCategory(t *testing.T) {
 	}
 
 	t.Run("admin", func(t *testing.T) {
-		require.Nil(t, handler.UpdateCategoryAdmin(updateOp))
+		require.Nil(t, apply(t, handler.UpdateCategoryAdmin, updateOp))
 		category, err := handler.doGetCategory("me", updateOp.Label)
 		require.Nil(t, err)
 		require.Equal(t, category.LocalizationKey, updateOp.LocalizationKey)
@@ -115,7 +115,7 @@ func TestBlog_UpdateCategory(t *testing.T) {
 		}
 
 		updateOp.Order = 30
-		require.Nil(t, handler.UpdateCategoryAdmin(updateOp))
+		require.Nil(t, apply(t, handler.UpdateCategoryAdmin, updateOp))
 		categories, err = handler.doGetCategories(domain)
 		require.Nil(t, err)
 		for i, category := range categories {
@@ -123,7 +123,7 @@ func TestBlog_UpdateCategory(t *testing.T) {
 		}
 
 		updateOp.Order = 1
-		require.Nil(t, handler.UpdateCategoryAdmin(updateOp))
+		require.Nil(t, apply(t, handler.UpdateCategoryAdmin, updateOp))
 		categories, err = handler.doGetCategories(domain)
 		require.Nil(t, err)
 		for i, category := range categories {
@@ -135,7 +135,7 @@ func TestBlog_UpdateCategory(t *testing.T) {
 	t.Run("not_admin", func(t *testing.T) {
 		cop := *updateOp
 		cop.Account = sheldon
-		err := handler.UpdateCategoryAdmin(&cop)
+		err := apply(t, handler.UpdateCategoryAdmin, &cop)
 		require.NotNil(t, err)
 		require.Equal(t, err.Code, rpc.AccessDeniedCode)
 	})
@@ -185,7 +185,7 @@ func TestBlog_RemoveCategory(t *testing.T) {
 			Label:           strconv.Itoa(i),
 			LocalizationKey: "me.soccer",
 		}
-		require.Nil(t, handler.AddCategoryAdmin(addOp))
+		require.Nil(t, apply(t, handler.AddCategoryAdmin, addOp))
 	}
 
 	categories, err := handler.doGetCategories(domain)
@@ -199,7 +199,7 @@ func TestBlog_RemoveCategory(t *testing.T) {
 	}
 
 	t.Run("admin", func(t *testing.T) {
-		require.Nil(t, handler.RemoveCategoryAdmin(removeOp))
+		require.Nil(t, apply(t, handler.RemoveCategoryAdmin, removeOp))
 		categories, err = handler.doGetCategories(domain)
 		require.Nil(t, err)
 		require.Len(t, categories, 49)
@@ -213,7 +213,7 @@ func TestBlog_RemoveCategory(t *testing.T) {
 	t.Run("not_admin", func(t *testing.T) {
 		cop := *removeOp
 		cop.Account = sheldon
-		err := handler.RemoveCategoryAdmin(&cop)
+		err := apply(t, handler.RemoveCategoryAdmin, &cop)
 		require.NotNil(t, err)
 		require.Equal(t, err.Code, rpc.AccessDeniedCode)
 	})
@@ -246,42 +246,42 @@ func TestBlog_RemoveCategory_Validation(t *testing.T) {
 func TestBlog_GetCategories(t *testing.T) {
 	defer cleanUp(t)
 
-	require.Nil(t, handler.AddCategoryAdmin(&types.AddCategoryAdminOperation{
+	require.Nil(t, apply(t, handler.AddCategoryAdmin, &types.AddCategoryAdminOperation{
 		Account:         leonarda,
 		Domain:          "me",
 		Label:           "hockey",
 		LocalizationKey: "me.hockey",
 	}))
 
-	require.Nil(t, handler.AddCategoryAdmin(&types.AddCategoryAdminOperation{
+	require.Nil(t, apply(t, handler.AddCategoryAdmin, &types.AddCategoryAdminOperation{
 		Account:         leonarda,
 		Domain:          "me",
 		Label:           "soccer",
 		LocalizationKey: "me.soccer",
 	}))
 
-	require.Nil(t, handler.AddCategoryAdmin(&types.AddCategoryAdminOperation{
+	require.Nil(t, apply(t, handler.AddCategoryAdmin, &types.AddCategoryAdminOperation{
 		Account:         leonarda,
 		Domain:          "com",
 		Label:           "soccer",
 		LocalizationKey: "com.soccer",
 	}))
 
-	require.Nil(t, handler.AddCategoryAdmin(&types.AddCategoryAdminOperation{
+	require.Nil(t, apply(t, handler.AddCategoryAdmin, &types.AddCategoryAdminOperation{
 		Account:         leonarda,
 		Domain:          "tc",
 		Label:           "soccer",
 		LocalizationKey: "tc.soccer",
 	}))
 
-	require.Nil(t, handler.AddCategoryAdmin(&types.AddCategoryAdminOperation{
+	require.Nil(t, apply(t, handler.AddCategoryAdmin, &types.AddCategoryAdminOperation{
 		Account:         leonarda,
 		Domain:          "in",
 		Label:           "soccer",
 		LocalizationKey: "in.soccer",
 	}))
 
-	require.Nil(t, handler.AddCategoryAdmin(&types.AddCategoryAdminOperation{
+	require.Nil(t, apply(t, handler.AddCategoryAdmin, &types.AddCategoryAdminOperation{
 		Account:         leonarda,
 		Domain:          "fr",
 		Label:           "soccer",
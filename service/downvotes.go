@@ -2,12 +2,14 @@ package service
 
 import (
 	"database/sql"
+
+	"gitlab.scorum.com/blog/api/broadcast"
 	"gitlab.scorum.com/blog/api/broadcast/types"
 	"gitlab.scorum.com/blog/api/db"
 	"gitlab.scorum.com/blog/api/rpc"
 )
 
-func (blog *Blog) Downvote(op types.Operation) *rpc.Error {
+func (blog *Blog) Downvote(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.DownvoteOperation)
 
 	reason := db.DownvoteReason(in.Reason)
@@ -24,15 +26,15 @@ func (blog *Blog) Downvote(op types.Operation) *rpc.Error {
 		Comment:  in.Comment,
 	}
 
-	err := blog.DownvotesStorage.Downvote(downvote)
+	err := blog.DownvotesStorage.InTx(tx.Tx).Downvote(downvote)
 	if err != nil {
 		return &rpc.Error{Code: rpc.InternalErrorCode, Message: err.Error()}
 	}
 
-	return nil
+	return blog.openDownvotesCase(tx.Tx, in.Author, in.Permlink)
 }
 
-func (blog *Blog) RemoveDownvote(op types.Operation) *rpc.Error {
+func (blog *Blog) RemoveDownvote(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.RemoveDownvoteOperation)
 
 	downvote := db.Downvote{
@@ -41,7 +43,7 @@ func (blog *Blog) RemoveDownvote(op types.Operation) *rpc.Error {
 		Permlink: in.Permlink,
 	}
 
-	err := blog.DownvotesStorage.Delete(downvote)
+	err := blog.DownvotesStorage.InTx(tx.Tx).Delete(downvote)
 	if err != nil && err == sql.ErrNoRows {
 		return &rpc.Error{Code: rpc.DownvoteNotFoundCode, Message: err.Error()}
 	}
@@ -3,15 +3,21 @@ package service
 import (
 	"database/sql"
 	"encoding/json"
+
+	"gitlab.scorum.com/blog/api/broadcast"
 	"gitlab.scorum.com/blog/api/broadcast/types"
 	"gitlab.scorum.com/blog/api/db"
 	"gitlab.scorum.com/blog/api/rpc"
 	"gopkg.in/go-playground/validator.v9"
 )
 
-func (blog *Blog) UpsertDraft(op types.Operation) *rpc.Error {
+func (blog *Blog) UpsertDraft(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.UpsertDraftOperation)
 
+	if err := blog.checkNotSuspended(tx.Tx, in.Account); err != nil {
+		return err
+	}
+
 	if in.Body == "" && in.Title == "" {
 		return NewError(rpc.InvalidParameterCode, "empty body and title")
 	}
@@ -33,7 +39,7 @@ func (blog *Blog) UpsertDraft(op types.Operation) *rpc.Error {
 		JsonMetadata: in.JsonMetadata,
 	}
 
-	if _, err := blog.DB.Write.NamedExec(
+	if _, err := tx.NamedExec(
 		`INSERT INTO drafts VALUES(:account, :id, :title, :body, :json_metadata)
 		ON CONFLICT (account,id) DO UPDATE SET title=:title, body=:body, json_metadata = :json_metadata`, draft); err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
@@ -42,10 +48,10 @@ func (blog *Blog) UpsertDraft(op types.Operation) *rpc.Error {
 	return nil
 }
 
-func (blog *Blog) RemoveDraft(op types.Operation) *rpc.Error {
+func (blog *Blog) RemoveDraft(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.RemoveDraftOperation)
 
-	result, err := blog.DB.Write.Exec(`DELETE FROM drafts WHERE account = $1 AND id =$2`, in.Account, in.ID)
+	result, err := tx.Exec(`DELETE FROM drafts WHERE account = $1 AND id =$2`, in.Account, in.ID)
 	if err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
 	}
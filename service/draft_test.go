@@ -21,7 +21,7 @@ func TestBlog_SetDraft(t *testing.T) {
 		JsonMetadata: "metadata",
 	}
 
-	require.Nil(t, handler.UpsertDraft(op))
+	require.Nil(t, apply(t, handler.UpsertDraft, op))
 
 	draft, err := handler.doGetDraft(op.Account, op.ID)
 	require.Nil(t, err)
@@ -35,13 +35,13 @@ func TestBlog_SetDraft(t *testing.T) {
 	t.Run("invalid_op", func(t *testing.T) {
 		op.Title = ""
 		op.Body = ""
-		require.NotNil(t, handler.UpsertDraft(op))
+		require.NotNil(t, apply(t, handler.UpsertDraft, op))
 	})
 
 	t.Run("update_draft", func(t *testing.T) {
 		op.Title = "updated_title"
 
-		require.Nil(t, handler.UpsertDraft(op))
+		require.Nil(t, apply(t, handler.UpsertDraft, op))
 
 		draft, err := handler.doGetDraft(op.Account, op.ID)
 		require.Nil(t, err)
@@ -58,7 +58,7 @@ func TestBlog_SetDraft(t *testing.T) {
 		veryLongTitle = veryLongTitle + veryLongTitle
 	}
 	op.Title = veryLongTitle
-	require.NotNil(t, handler.UpsertDraft(op))
+	require.NotNil(t, apply(t, handler.UpsertDraft, op))
 
 	op.Title = "title"
 
@@ -67,7 +67,7 @@ func TestBlog_SetDraft(t *testing.T) {
 		veryLongBody = veryLongBody + veryLongBody
 	}
 	op.Body = veryLongBody
-	require.NotNil(t, handler.UpsertDraft(op))
+	require.NotNil(t, apply(t, handler.UpsertDraft, op))
 }
 
 func TestBlog_RemoveDraft(t *testing.T) {
@@ -82,7 +82,7 @@ func TestBlog_RemoveDraft(t *testing.T) {
 		JsonMetadata: "metadata",
 	}
 
-	require.Nil(t, handler.UpsertDraft(op))
+	require.Nil(t, apply(t, handler.UpsertDraft, op))
 
 	_, err := handler.doGetDraft(op.Account, op.ID)
 	require.Nil(t, err)
@@ -92,7 +92,7 @@ func TestBlog_RemoveDraft(t *testing.T) {
 		ID:      op.ID,
 	}
 
-	require.Nil(t, handler.RemoveDraft(removeOp))
+	require.Nil(t, apply(t, handler.RemoveDraft, removeOp))
 
 	_, err = handler.doGetDraft(op.Account, op.ID)
 	require.NotNil(t, err)
@@ -112,12 +112,12 @@ func TestBlog_GetDrafts(t *testing.T) {
 		JsonMetadata: "metadata",
 	}
 
-	require.Nil(t, handler.UpsertDraft(op))
+	require.Nil(t, apply(t, handler.UpsertDraft, op))
 
 	op.ID = "id2"
 	op.Title = "title2"
 
-	require.Nil(t, handler.UpsertDraft(op))
+	require.Nil(t, apply(t, handler.UpsertDraft, op))
 
 	drafts, err := handler.doGetDrafts(op.Account)
 	require.Nil(t, err)
@@ -137,7 +137,7 @@ func TestBlog_DraftMarshalling(t *testing.T) {
 		JsonMetadata: "metadata",
 	}
 
-	require.Nil(t, handler.UpsertDraft(op))
+	require.Nil(t, apply(t, handler.UpsertDraft, op))
 
 	draft, err := handler.doGetDraft(op.Account, op.ID)
 	require.Nil(t, err)
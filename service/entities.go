@@ -2,6 +2,7 @@ package service
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/google/uuid"
 	"gitlab.scorum.com/blog/api/db"
@@ -39,19 +40,66 @@ func toAPIProfiles(profiles []*db.Profile) []*Profile {
 
 type ExtendedProfile struct {
 	Profile
+	ProfileExtra
 
-	FollowersCount int64 `json:"followers_count"`
-	FollowingCount int64 `json:"following_count"`
+	FollowersCount int64       `json:"followers_count"`
+	FollowingCount int64       `json:"following_count"`
+	Suspension     *Suspension `json:"suspension,omitempty"`
 }
 
 func toAPIExtendedProfile(extendedProfile *db.ExtendedProfile) *ExtendedProfile {
 	return &ExtendedProfile{
 		Profile:        *(toAPIProfile(&extendedProfile.Profile)),
+		ProfileExtra:   toAPIProfileExtra(extendedProfile.ProfileExtra),
 		FollowingCount: extendedProfile.FollowingCount,
 		FollowersCount: extendedProfile.FollowersCount,
 	}
 }
 
+type ProfileExtra struct {
+	Website    string            `json:"website,omitempty"`
+	Links      map[string]string `json:"links,omitempty"`
+	Language   string            `json:"language,omitempty"`
+	PinnedPost string            `json:"pinned_post,omitempty"`
+}
+
+func toAPIProfileExtra(extra db.ProfileExtra) ProfileExtra {
+	return ProfileExtra{
+		Website:    extra.Website,
+		Links:      extra.Links,
+		Language:   extra.Language,
+		PinnedPost: extra.PinnedPost,
+	}
+}
+
+type ProfileHistoryEntry struct {
+	ID          int64  `json:"id"`
+	DisplayName string `json:"display_name"`
+	Location    string `json:"location"`
+	Bio         string `json:"bio"`
+	AvatarUrl   string `json:"avatar_url"`
+	CoverUrl    string `json:"cover_url"`
+	ProfileExtra
+	ChangedAt string `json:"changed"`
+}
+
+func toAPIProfileHistoryEntries(entries []*db.ProfileHistoryEntry) []*ProfileHistoryEntry {
+	out := make([]*ProfileHistoryEntry, len(entries))
+	for idx, entry := range entries {
+		out[idx] = &ProfileHistoryEntry{
+			ID:           entry.ID,
+			DisplayName:  entry.DisplayName,
+			Location:     entry.Location,
+			Bio:          entry.Bio,
+			AvatarUrl:    entry.AvatarUrl,
+			CoverUrl:     entry.CoverUrl,
+			ProfileExtra: toAPIProfileExtra(entry.ProfileExtra),
+			ChangedAt:    entry.ChangedAt.Format(TimeLayout),
+		}
+	}
+	return out
+}
+
 type PostID struct {
 	Account  string `json:"account"`
 	Permlink string `json:"permlink"`
@@ -134,16 +182,59 @@ type Notification struct {
 	Meta      json.RawMessage     `json:"meta"`
 }
 
+func toAPINotification(notification db.Notification) *Notification {
+	return &Notification{
+		UUID:      notification.ID,
+		Timestamp: notification.Timestamp.Format(TimeLayout),
+		IsRead:    notification.IsRead,
+		IsSeen:    notification.IsSeen,
+		Type:      notification.Type,
+		Meta:      notification.Meta,
+	}
+}
+
 func toAPINotifications(notifications []*db.Notification) []*Notification {
 	out := make([]*Notification, len(notifications))
 	for idx, notification := range notifications {
-		out[idx] = &Notification{
-			UUID:      notification.ID,
-			Timestamp: notification.Timestamp.Format(TimeLayout),
-			IsRead:    notification.IsRead,
-			IsSeen:    notification.IsSeen,
-			Type:      notification.Type,
-			Meta:      notification.Meta,
+		out[idx] = toAPINotification(*notification)
+	}
+	return out
+}
+
+// FollowSuggestion is the profile suggested to follow, the reasons are friends, categories and trusted
+type FollowSuggestion struct {
+	Profile
+	Reasons []string `json:"reasons"`
+}
+
+func toAPIFollowSuggestions(suggestions []*db.FollowSuggestion) []*FollowSuggestion {
+	out := make([]*FollowSuggestion, len(suggestions))
+	for idx, suggestion := range suggestions {
+		out[idx] = &FollowSuggestion{
+			Profile: *toAPIProfile(&suggestion.Profile),
+			Reasons: suggestion.Reasons,
+		}
+	}
+	return out
+}
+
+type TopicSubscription struct {
+	Domain    string `json:"domain"`
+	Kind      string `json:"kind"`
+	Topic     string `json:"topic"`
+	Notify    bool   `json:"notify"`
+	CreatedAt string `json:"created_at"`
+}
+
+func toAPITopicSubscriptions(subscriptions []*db.TopicSubscription) []*TopicSubscription {
+	out := make([]*TopicSubscription, len(subscriptions))
+	for idx, s := range subscriptions {
+		out[idx] = &TopicSubscription{
+			Domain:    s.Domain,
+			Kind:      string(s.Kind),
+			Topic:     s.Topic,
+			Notify:    s.Notify,
+			CreatedAt: s.CreatedAt.Format(TimeLayout),
 		}
 	}
 	return out
@@ -152,6 +243,32 @@ func toAPINotifications(notifications []*db.Notification) []*Notification {
 type ProfileSettings struct {
 	Account                        string `json:"account"`
 	EnableEmailUnseenNotifications bool   `json:"enable_email_unseen_notifications"`
+	// NotificationPreferences is the full matrix of the notification types and channels
+	NotificationPreferences []*NotificationPreference `json:"notification_preferences"`
+	QuietHours              *QuietHours               `json:"quiet_hours"`
+}
+
+type NotificationPreference struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel"`
+	Enabled bool   `json:"enabled"`
+}
+
+type QuietHours struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	TimeZone string `json:"time_zone"`
+}
+
+func toAPIQuietHours(quietHours *db.QuietHours) *QuietHours {
+	if quietHours == nil {
+		return nil
+	}
+	return &QuietHours{
+		Start:    fmt.Sprintf("%02d:%02d", quietHours.Start/60, quietHours.Start%60),
+		End:      fmt.Sprintf("%02d:%02d", quietHours.End/60, quietHours.End%60),
+		TimeZone: quietHours.TimeZone,
+	}
 }
 
 func toAPIProfileSettings(profileSettings *db.ProfileSettings) *ProfileSettings {
@@ -160,3 +277,164 @@ func toAPIProfileSettings(profileSettings *db.ProfileSettings) *ProfileSettings
 		EnableEmailUnseenNotifications: profileSettings.EnableEmailUnseenNotifications,
 	}
 }
+
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	Account    string          `json:"account"`
+	OpType     string          `json:"op_type"`
+	Payload    json.RawMessage `json:"payload"`
+	TrxID      string          `json:"trx_id"`
+	Digest     string          `json:"digest"`
+	Signatures []string        `json:"signatures"`
+	Code       int             `json:"code"`
+	CreatedAt  string          `json:"created"`
+}
+
+func toAPIAuditEntry(entry db.AuditEntry) *AuditEntry {
+	return &AuditEntry{
+		ID:         entry.ID,
+		Account:    entry.Account,
+		OpType:     entry.OpType,
+		Payload:    json.RawMessage(entry.Payload),
+		TrxID:      entry.TrxID,
+		Digest:     entry.Digest,
+		Signatures: entry.Signatures,
+		Code:       entry.Code,
+		CreatedAt:  entry.CreatedAt.Format(TimeLayout),
+	}
+}
+
+func toAPIAuditEntries(entries []*db.AuditEntry) []*AuditEntry {
+	out := make([]*AuditEntry, len(entries))
+	for idx, entry := range entries {
+		out[idx] = toAPIAuditEntry(*entry)
+	}
+	return out
+}
+
+type RoleGrant struct {
+	Account   string `json:"account"`
+	Role      string `json:"role"`
+	Domain    string `json:"domain,omitempty"`
+	GrantedBy string `json:"granted_by"`
+	CreatedAt string `json:"created"`
+}
+
+func toAPIRoleGrant(grant db.RoleGrant) *RoleGrant {
+	return &RoleGrant{
+		Account:   grant.Account,
+		Role:      string(grant.Role),
+		Domain:    grant.Domain,
+		GrantedBy: grant.GrantedBy,
+		CreatedAt: grant.CreatedAt.Format(TimeLayout),
+	}
+}
+
+func toAPIRoleGrants(grants []*db.RoleGrant) []*RoleGrant {
+	out := make([]*RoleGrant, len(grants))
+	for idx, grant := range grants {
+		out[idx] = toAPIRoleGrant(*grant)
+	}
+	return out
+}
+
+type Suspension struct {
+	Reason      string `json:"reason"`
+	ExpiresAt   string `json:"expires_at,omitempty"`
+	SuspendedBy string `json:"suspended_by"`
+	CreatedAt   string `json:"created"`
+}
+
+func toAPISuspension(suspension *db.Suspension) *Suspension {
+	out := &Suspension{
+		Reason:      suspension.Reason,
+		SuspendedBy: suspension.SuspendedBy,
+		CreatedAt:   suspension.CreatedAt.Format(TimeLayout),
+	}
+	if suspension.ExpiresAt.Valid {
+		out.ExpiresAt = suspension.ExpiresAt.Time.Format(TimeLayout)
+	}
+	return out
+}
+
+type BlacklistEntry struct {
+	ID       int64  `json:"id"`
+	Account  string `json:"account"`
+	Permlink string `json:"permlink"`
+	Reason   string `json:"reason"`
+	Note     string `json:"note"`
+	// AddedBy and CreatedAt are empty for the legacy entries
+	AddedBy   string `json:"added_by,omitempty"`
+	CreatedAt string `json:"created,omitempty"`
+}
+
+// BlacklistPage is the page of the blacklist_api.get_blacklist_page, NextCursor is empty on the last page
+type BlacklistPage struct {
+	Items      []*BlacklistEntry `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+func toAPIBlacklistEntry(entry db.BlacklistEntry) *BlacklistEntry {
+	out := &BlacklistEntry{
+		ID:       entry.ID,
+		Account:  entry.Account,
+		Permlink: entry.Permlink,
+		Reason:   string(entry.Reason),
+		Note:     entry.Note,
+		AddedBy:  entry.AddedBy.String,
+	}
+	if entry.CreatedAt.Valid {
+		out.CreatedAt = entry.CreatedAt.Time.Format(TimeLayout)
+	}
+	return out
+}
+
+func toAPIBlacklistEntries(entries []*db.BlacklistEntry) []*BlacklistEntry {
+	out := make([]*BlacklistEntry, len(entries))
+	for idx, entry := range entries {
+		out[idx] = toAPIBlacklistEntry(*entry)
+	}
+	return out
+}
+
+type ModerationCase struct {
+	ID       int64  `json:"id"`
+	Author   string `json:"author"`
+	Permlink string `json:"permlink"`
+	Domain   string `json:"domain"`
+	Source   string `json:"source"`
+	Reason   string `json:"reason"`
+	Status   string `json:"status"`
+	OpenedAt string `json:"opened"`
+	// Moderator, Note and DecidedAt are empty for the open cases
+	Moderator string `json:"moderator,omitempty"`
+	Note      string `json:"note,omitempty"`
+	DecidedAt string `json:"decided,omitempty"`
+}
+
+func toAPIModerationCase(c db.ModerationCase) *ModerationCase {
+	out := &ModerationCase{
+		ID:        c.ID,
+		Author:    c.Author,
+		Permlink:  c.Permlink,
+		Domain:    c.Domain,
+		Source:    string(c.Source),
+		Reason:    c.Reason,
+		Status:    string(c.Status),
+		OpenedAt:  c.OpenedAt.Format(TimeLayout),
+		Moderator: c.Moderator.String,
+		Note:      c.Note.String,
+	}
+	if c.DecidedAt.Valid {
+		out.DecidedAt = c.DecidedAt.Time.Format(TimeLayout)
+	}
+	return out
+}
+
+func toAPIModerationCases(cases []*db.ModerationCase) []*ModerationCase {
+	out := make([]*ModerationCase, len(cases))
+	for idx, c := range cases {
+		out[idx] = toAPIModerationCase(*c)
+	}
+	return out
+}
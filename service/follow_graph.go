@@ -0,0 +1,245 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
+)
+
+// maxFollowerHistoryBuckets limits the follower history to about a year of days
+const maxFollowerHistoryBuckets = 366
+
+// followerHistoryBuckets are the supported follower history buckets and their lengths
+var followerHistoryBuckets = map[string]time.Duration{
+	"day":  24 * time.Hour,
+	"week": 7 * 24 * time.Hour,
+}
+
+// Relationship is the follow relationship between the account and the other one
+type Relationship struct {
+	Account    string `json:"account" db:"account"`
+	Following  bool   `json:"following" db:"following"`
+	FollowedBy bool   `json:"followed_by" db:"followed_by"`
+	Mutual     bool   `json:"mutual" db:"-"`
+}
+
+// FollowerHistoryPoint is the followers gained and lost during the bucket started at Time,
+// Total is the followers count at the end of the bucket
+type FollowerHistoryPoint struct {
+	Time       string `json:"time"`
+	Followed   int64  `json:"followed"`
+	Unfollowed int64  `json:"unfollowed"`
+	Net        int64  `json:"net"`
+	Total      int64  `json:"total"`
+}
+
+func (blog *Blog) GetRelationships(ctx *rpc.Context) {
+	var account string
+	if err := ctx.Param(0, &account); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	var others []string
+	if err := ctx.Param(1, &others); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	relationships, err := blog.doGetRelationships(account, others)
+	if err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	ctx.WriteResult(relationships)
+}
+
+// doGetRelationships returns the relationships of the account with the others in the order of the others
+func (blog *Blog) doGetRelationships(account string, others []string) ([]*Relationship, *rpc.Error) {
+	others = uniqueStrings(others)
+	if len(others) > maxLargePageSize {
+		return nil, NewError(rpc.InvalidParameterCode, "too many accounts")
+	}
+
+	relationships := make([]*Relationship, 0, len(others))
+	if len(others) == 0 {
+		return relationships, nil
+	}
+
+	err := blog.DB.Read.Select(&relationships,
+		`SELECT t.account,
+				EXISTS(SELECT * FROM followers f WHERE f.account = $1 AND f.follow_account = t.account) AS following,
+				EXISTS(SELECT * FROM followers f WHERE f.account = t.account AND f.follow_account = $1) AS followed_by
+		FROM UNNEST($2::TEXT[]) WITH ORDINALITY t(account, ord)
+		ORDER BY t.ord`,
+		account, pq.Array(others))
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	for _, r := range relationships {
+		r.Mutual = r.Following && r.FollowedBy
+	}
+	return relationships, nil
+}
+
+func (blog *Blog) GetMutualFollowers(ctx *rpc.Context) {
+	var a string
+	if err := ctx.Param(0, &a); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	var b string
+	if err := ctx.Param(1, &b); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	var from uint32
+	if err := ctx.Param(2, &from); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	var limit uint32
+	if err := ctx.Param(3, &limit); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	if limit > maxLargePageSize {
+		ctx.WriteError(rpc.InvalidParameterCode, "invalid limit")
+		return
+	}
+
+	profiles, err := blog.doGetMutualFollowers(a, b, from, limit)
+	if err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	ctx.WriteResult(profiles)
+}
+
+// doGetMutualFollowers returns the accounts following both a and b starting from the recent follows
+func (blog *Blog) doGetMutualFollowers(a, b string, from, limit uint32) ([]*Profile, *rpc.Error) {
+	var profiles []*db.Profile
+	err := blog.DB.Read.Select(&profiles,
+		`SELECT p.account, display_name, location, bio, avatar_url, cover_url, p.created_at
+				FROM profiles p
+				INNER JOIN followers fa ON p.account = fa.account AND fa.follow_account = $1
+				INNER JOIN followers fb ON p.account = fb.account AND fb.follow_account = $2
+				ORDER BY GREATEST(fa.created_at, fb.created_at) DESC, p.account
+				LIMIT $3 OFFSET $4`,
+		a, b, limit, from)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+	return toAPIProfiles(profiles), nil
+}
+
+func (blog *Blog) GetFollowerHistory(ctx *rpc.Context) {
+	var account string
+	if err := ctx.Param(0, &account); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	var from string
+	if err := ctx.Param(1, &from); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	var to string
+	if err := ctx.Param(2, &to); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	var bucket string
+	if err := ctx.Param(3, &bucket); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	history, err := blog.doGetFollowerHistory(account, from, to, bucket)
+	if err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	ctx.WriteResult(history)
+}
+
+// doGetFollowerHistory aggregates the follows and the unfollows of the account by day or week,
+// the first bucket is truncated to the start of the day or the week, the weeks start on Monday
+func (blog *Blog) doGetFollowerHistory(account, from, to, bucket string) ([]*FollowerHistoryPoint, *rpc.Error) {
+	length, ok := followerHistoryBuckets[bucket]
+	if !ok {
+		return nil, NewError(rpc.InvalidParameterCode, fmt.Sprintf("%s is not a valid bucket", bucket))
+	}
+
+	fromTime, err := time.Parse(TimeLayout, from)
+	if err != nil {
+		return nil, NewError(rpc.InvalidParameterCode, "invalid from")
+	}
+
+	toTime, err := time.Parse(TimeLayout, to)
+	if err != nil {
+		return nil, NewError(rpc.InvalidParameterCode, "invalid to")
+	}
+
+	if toTime.Before(fromTime) {
+		return nil, NewError(rpc.InvalidParameterCode, "to is before from")
+	}
+	if toTime.Sub(fromTime)/length >= maxFollowerHistoryBuckets {
+		return nil, NewError(rpc.InvalidParameterCode, "too many buckets")
+	}
+
+	var points []*struct {
+		Time       time.Time `db:"time"`
+		Followed   int64     `db:"followed"`
+		Unfollowed int64     `db:"unfollowed"`
+		Total      int64     `db:"total"`
+	}
+	err = blog.DB.Read.Select(&points,
+		`WITH buckets AS (
+			SELECT start, start + CAST('1 ' || $4::TEXT AS INTERVAL) AS finish
+			FROM generate_series(date_trunc($4::TEXT, $2::TIMESTAMP), $3::TIMESTAMP, CAST('1 ' || $4::TEXT AS INTERVAL)) start
+		)
+		SELECT b.start AS time,
+			(SELECT COUNT(*) FROM followers f
+				WHERE f.follow_account = $1 AND f.created_at >= b.start AND f.created_at < b.finish) +
+			(SELECT COUNT(*) FROM unfollows u
+				WHERE u.follow_account = $1 AND u.followed_at >= b.start AND u.followed_at < b.finish) AS followed,
+			(SELECT COUNT(*) FROM unfollows u
+				WHERE u.follow_account = $1 AND u.unfollowed_at >= b.start AND u.unfollowed_at < b.finish) AS unfollowed,
+			(SELECT COUNT(*) FROM followers f
+				WHERE f.follow_account = $1 AND f.created_at < b.finish) +
+			(SELECT COUNT(*) FROM unfollows u
+				WHERE u.follow_account = $1 AND u.followed_at < b.finish AND u.unfollowed_at >= b.finish) AS total
+		FROM buckets b
+		ORDER BY b.start`,
+		account, fromTime, toTime, bucket)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	history := make([]*FollowerHistoryPoint, len(points))
+	for idx, p := range points {
+		history[idx] = &FollowerHistoryPoint{
+			Time:       p.Time.Format(TimeLayout),
+			Followed:   p.Followed,
+			Unfollowed: p.Unfollowed,
+			Net:        p.Followed - p.Unfollowed,
+			Total:      p.Total,
+		}
+	}
+	return history, nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/push"
+	"gitlab.scorum.com/blog/api/rpc"
+)
+
+func TestBlog_FollowGraph(t *testing.T) {
+	defer cleanUp(t)
+
+	registerAccount(t, leonarda)
+	registerAccount(t, kristie)
+	registerAccount(t, sheldon)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	notifier := push.NewMockNotifier(mockCtrl)
+	handler.Notifier = notifier
+	notifier.EXPECT().NotifyStartedFollow(gomock.Any(), gomock.Any()).Times(4)
+
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: leonarda, Follow: kristie}))
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: kristie, Follow: leonarda}))
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: sheldon, Follow: leonarda}))
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: sheldon, Follow: kristie}))
+
+	t.Run("relationships", func(t *testing.T) {
+		relationships, err := handler.doGetRelationships(leonarda, []string{sheldon, kristie, sheldon})
+		require.Nil(t, err)
+		require.Equal(t, []*Relationship{
+			{Account: sheldon, Following: false, FollowedBy: true, Mutual: false},
+			{Account: kristie, Following: true, FollowedBy: true, Mutual: true},
+		}, relationships)
+
+		relationships, err = handler.doGetRelationships(leonarda, nil)
+		require.Nil(t, err)
+		require.Empty(t, relationships)
+	})
+
+	t.Run("mutual_followers", func(t *testing.T) {
+		profiles, err := handler.doGetMutualFollowers(leonarda, kristie, 0, 10)
+		require.Nil(t, err)
+		require.Len(t, profiles, 1)
+		require.Equal(t, sheldon, profiles[0].Account)
+	})
+
+	require.Nil(t, apply(t, handler.Unfollow, &types.UnfollowOperation{Account: sheldon, Unfollow: leonarda}))
+
+	t.Run("follower_history", func(t *testing.T) {
+		now := time.Now().UTC()
+		from := now.Add(-48 * time.Hour).Format(TimeLayout)
+		to := now.Format(TimeLayout)
+
+		history, err := handler.doGetFollowerHistory(leonarda, from, to, "day")
+		require.Nil(t, err)
+		require.Len(t, history, 3)
+		require.Zero(t, history[0].Total)
+		require.Equal(t, &FollowerHistoryPoint{
+			Time:       now.Truncate(24 * time.Hour).Format(TimeLayout),
+			Followed:   2,
+			Unfollowed: 1,
+			Net:        1,
+			Total:      1,
+		}, history[2])
+
+		_, err = handler.doGetFollowerHistory(leonarda, from, to, "month")
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+
+		_, err = handler.doGetFollowerHistory(leonarda, to, from, "day")
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+	})
+}
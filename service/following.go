@@ -4,29 +4,30 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 
+	"gitlab.scorum.com/blog/api/broadcast"
 	"gitlab.scorum.com/blog/api/broadcast/types"
 	"gitlab.scorum.com/blog/api/db"
 	"gitlab.scorum.com/blog/api/rpc"
 	"gitlab.scorum.com/blog/api/utils/postgres"
 )
 
-func (blog *Blog) Follow(op types.Operation) (rerr *rpc.Error) {
+func (blog *Blog) Follow(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.FollowOperation)
 
-	tx, err := blog.DB.Write.Beginx()
+	if err := blog.checkNotSuspended(tx.Tx, in.Account); err != nil {
+		return err
+	}
+
+	blocked, err := blog.AccountListsStorage.InTx(tx.Tx).Contains(in.Follow, in.Account, db.BlockedList)
 	if err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
 	}
-
-	defer func() {
-		if rerr != nil {
-			tx.Rollback()
-		} else if err := tx.Commit(); err != nil {
-			rerr = WrapError(rpc.InternalErrorCode, err)
-		}
-	}()
+	if blocked {
+		return NewError(rpc.AccountBlockedCode, fmt.Sprintf("%s is blocked by %s", in.Account, in.Follow))
+	}
 
 	var followCount int
 	err = tx.Get(&followCount, `SELECT COUNT(*) FROM followers WHERE account=$1`, in.Account)
@@ -67,41 +68,41 @@ func (blog *Blog) Follow(op types.Operation) (rerr *rpc.Error) {
 		Meta:      db.StartedFollowNotificationMeta{Account: in.Account}.ToJson(),
 	}
 
-	if err := blog.NotificationStorage.InTx(tx).Insert(notification); err != nil {
+	if err := blog.NotificationStorage.InTx(tx.Tx).Insert(notification); err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
 	}
 
-	blog.Notifier.NotifyStartedFollow(follow.FollowAccount, follow.Account)
+	tx.AfterCommit(func() {
+		blog.Notifier.NotifyStartedFollow(follow.FollowAccount, follow.Account)
+	})
 	return nil
 }
 
-func (blog *Blog) Unfollow(op types.Operation) (rerr *rpc.Error) {
+func (blog *Blog) Unfollow(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.UnfollowOperation)
 
-	tx, err := blog.DB.Write.Beginx()
-	if err != nil {
-		return WrapError(rpc.InternalErrorCode, err)
-	}
-
-	defer func() {
-		if rerr != nil {
-			tx.Rollback()
-		} else if err := tx.Commit(); err != nil {
-			rerr = WrapError(rpc.InternalErrorCode, err)
-		}
-	}()
+	return blog.removeFollow(tx.Tx, in.Account, in.Unfollow)
+}
 
-	_, err = tx.Exec(
-		`DELETE FROM followers
-				WHERE account = $1 AND follow_account = $2`, in.Account, in.Unfollow)
+// removeFollow deletes the follow and its notification, the follow is moved to the unfollows for the follower history
+func (blog *Blog) removeFollow(tx *sqlx.Tx, account, follow string) *rpc.Error {
+	_, err := tx.Exec(
+		`WITH removed AS (
+					DELETE FROM followers
+					WHERE account = $1 AND follow_account = $2
+					RETURNING account, follow_account, created_at
+				)
+				INSERT INTO unfollows (account, follow_account, followed_at, unfollowed_at)
+				SELECT account, follow_account, created_at, $3 FROM removed`,
+		account, follow, time.Now().UTC())
 	if err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
 	}
 
 	notification := db.Notification{
-		Account: in.Unfollow,
+		Account: follow,
 		Type:    db.StartedFollowNotificationType,
-		Meta:    db.StartedFollowNotificationMeta{Account: in.Account}.ToJson(),
+		Meta:    db.StartedFollowNotificationMeta{Account: account}.ToJson(),
 	}
 
 	if err := blog.NotificationStorage.InTx(tx).Delete(notification); err != nil {
@@ -159,6 +160,48 @@ func (blog *Blog) doGetFollowers(account string, from uint32, limit uint32) ([]*
 	return toAPIProfiles(profiles), nil
 }
 
+// GetFollowersPage is get_followers paginated by the cursor, the page is stable when the follows happen mid-scroll
+func (blog *Blog) GetFollowersPage(ctx *rpc.Context) {
+	var account string
+	if err := ctx.Param(0, &account); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	cursor, limit, ok := readPageParams(ctx, 1)
+	if !ok {
+		return
+	}
+
+	page, err := blog.doGetFollowersPage(account, cursor, limit)
+	if err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	ctx.WriteResult(page)
+}
+
+func (blog *Blog) doGetFollowersPage(account, cursor string, limit uint32) (*ProfilesPage, *rpc.Error) {
+	after, keys, rpcErr := pageParams(cursor, 1, limit)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	var rows []*profileCursorRow
+	err := blog.DB.Read.Select(&rows,
+		`SELECT p.account, display_name, location, bio, avatar_url, cover_url, p.created_at, f.created_at AS listed_at
+				FROM profiles p INNER JOIN followers f ON p.account = f.account
+				WHERE f.follow_account = $1 AND ($2::TIMESTAMP IS NULL OR (f.created_at, f.account) < ($2, $3))
+				ORDER BY f.created_at DESC, f.account DESC
+				LIMIT $4`,
+		account, after, keys[0], limit)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+	return toProfilesPage(rows, limit), nil
+}
+
 func (blog *Blog) FilterFollowers(ctx *rpc.Context) {
 	var account string
 	if err := ctx.Param(0, &account); err != nil {
@@ -270,6 +313,48 @@ func (blog *Blog) doGetFollowing(account string, from uint32, limit uint32) ([]*
 	return toAPIProfiles(profiles), nil
 }
 
+// GetFollowingPage is get_following paginated by the cursor, the page is stable when the follows happen mid-scroll
+func (blog *Blog) GetFollowingPage(ctx *rpc.Context) {
+	var account string
+	if err := ctx.Param(0, &account); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	cursor, limit, ok := readPageParams(ctx, 1)
+	if !ok {
+		return
+	}
+
+	page, err := blog.doGetFollowingPage(account, cursor, limit)
+	if err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	ctx.WriteResult(page)
+}
+
+func (blog *Blog) doGetFollowingPage(account, cursor string, limit uint32) (*ProfilesPage, *rpc.Error) {
+	after, keys, rpcErr := pageParams(cursor, 1, limit)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	var rows []*profileCursorRow
+	err := blog.DB.Read.Select(&rows,
+		`SELECT p.account, display_name, location, bio, avatar_url, cover_url, p.created_at, f.created_at AS listed_at
+				FROM profiles p INNER JOIN followers f ON p.account = f.follow_account
+				WHERE f.account = $1 AND ($2::TIMESTAMP IS NULL OR (f.created_at, f.follow_account) < ($2, $3))
+				ORDER BY f.created_at DESC, f.follow_account DESC
+				LIMIT $4`,
+		account, after, keys[0], limit)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+	return toProfilesPage(rows, limit), nil
+}
+
 func (blog *Blog) doFilterFollowing(account string, accountsToCheck []string) ([]*Profile, *rpc.Error) {
 	var profiles []*db.Profile
 
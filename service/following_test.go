@@ -8,6 +8,7 @@ import (
 	"gitlab.scorum.com/blog/api/broadcast/types"
 	"gitlab.scorum.com/blog/api/db"
 	"gitlab.scorum.com/blog/api/push"
+	"gitlab.scorum.com/blog/api/rpc"
 )
 
 func TestBlog_Following(t *testing.T) {
@@ -24,7 +25,7 @@ func TestBlog_Following(t *testing.T) {
 	handler.Notifier = notifier
 	notifier.EXPECT().NotifyStartedFollow(gomock.Any(), gomock.Any()).Times(3)
 
-	require.Nil(t, handler.Follow(&types.FollowOperation{
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{
 		Account: leonarda,
 		Follow:  kristie,
 	}))
@@ -40,7 +41,7 @@ func TestBlog_Following(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, meta.Account, leonarda)
 
-	require.Nil(t, handler.Follow(&types.FollowOperation{
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{
 		Account: sheldon,
 		Follow:  kristie,
 	}))
@@ -49,13 +50,13 @@ func TestBlog_Following(t *testing.T) {
 	require.Nil(t, err)
 	require.Len(t, followers, 2)
 
-	require.Nil(t, handler.Follow(&types.FollowOperation{
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{
 		Account: kristie,
 		Follow:  leonarda,
 	}))
 
 	handler.Config.MaxFollow = 3
-	require.NotNil(t, handler.Follow(&types.FollowOperation{
+	require.NotNil(t, apply(t, handler.Follow, &types.FollowOperation{
 		Account: kristie,
 		Follow:  leonarda,
 	}))
@@ -71,22 +72,22 @@ func TestBlog_Following(t *testing.T) {
 	require.Len(t, following, 1)
 	require.Equal(t, kristie, following[0].Account)
 
-	require.Nil(t, handler.Unfollow(&types.UnfollowOperation{
+	require.Nil(t, apply(t, handler.Unfollow, &types.UnfollowOperation{
 		Account:  leonarda,
 		Unfollow: kristie,
 	}))
 
-	require.Nil(t, handler.Unfollow(&types.UnfollowOperation{
+	require.Nil(t, apply(t, handler.Unfollow, &types.UnfollowOperation{
 		Account:  sheldon,
 		Unfollow: kristie,
 	}))
 
-	require.Nil(t, handler.Unfollow(&types.UnfollowOperation{
+	require.Nil(t, apply(t, handler.Unfollow, &types.UnfollowOperation{
 		Account:  kristie,
 		Unfollow: leonarda,
 	}))
 
-	require.Nil(t, handler.Unfollow(&types.UnfollowOperation{
+	require.Nil(t, apply(t, handler.Unfollow, &types.UnfollowOperation{
 		Account:  kristie,
 		Unfollow: sheldon,
 	}))
@@ -107,12 +108,12 @@ func TestBlog_FilterFollowing(t *testing.T) {
 		handler.Notifier = notifier
 		notifier.EXPECT().NotifyStartedFollow(gomock.Any(), gomock.Any()).Times(2)
 
-		require.Nil(t, handler.Follow(&types.FollowOperation{
+		require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{
 			Account: kristie,
 			Follow:  leonarda,
 		}))
 
-		require.Nil(t, handler.Follow(&types.FollowOperation{
+		require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{
 			Account: kristie,
 			Follow:  sheldon,
 		}))
@@ -147,12 +148,12 @@ func TestBlog_FilterFollowers(t *testing.T) {
 		handler.Notifier = notifier
 		notifier.EXPECT().NotifyStartedFollow(gomock.Any(), gomock.Any()).Times(2)
 
-		require.Nil(t, handler.Follow(&types.FollowOperation{
+		require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{
 			Account: kristie,
 			Follow:  leonarda,
 		}))
 
-		require.Nil(t, handler.Follow(&types.FollowOperation{
+		require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{
 			Account: sheldon,
 			Follow:  leonarda,
 		}))
@@ -228,3 +229,51 @@ func TestBlog_Following_ValidationTest(t *testing.T) {
 		require.Error(t, validate.Struct(cop))
 	})
 }
+
+func TestBlog_GetFollowersPage(t *testing.T) {
+	defer cleanUp(t)
+
+	registerAccount(t, leonarda)
+	registerAccount(t, kristie)
+	registerAccount(t, sheldon)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	notifier := push.NewMockNotifier(mockCtrl)
+	handler.Notifier = notifier
+	notifier.EXPECT().NotifyStartedFollow(gomock.Any(), gomock.Any()).Times(3)
+
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: kristie, Follow: leonarda}))
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: sheldon, Follow: leonarda}))
+
+	page, err := handler.doGetFollowersPage(leonarda, "", 1)
+	require.Nil(t, err)
+	require.Len(t, page.Items, 1)
+	require.Equal(t, sheldon, page.Items[0].Account)
+	require.NotEmpty(t, page.NextCursor)
+
+	// the follow made mid-scroll does not shift the next page
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: leonarda, Follow: kristie}))
+
+	page, err = handler.doGetFollowersPage(leonarda, page.NextCursor, 1)
+	require.Nil(t, err)
+	require.Len(t, page.Items, 1)
+	require.Equal(t, kristie, page.Items[0].Account)
+
+	page, err = handler.doGetFollowersPage(leonarda, page.NextCursor, 1)
+	require.Nil(t, err)
+	require.Empty(t, page.Items)
+	require.Empty(t, page.NextCursor)
+
+	following, err := handler.doGetFollowingPage(kristie, "", 10)
+	require.Nil(t, err)
+	require.Len(t, following.Items, 1)
+	require.Empty(t, following.NextCursor)
+
+	t.Run("invalid_cursor", func(t *testing.T) {
+		_, err := handler.doGetFollowersPage(leonarda, "not a cursor", 10)
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+	})
+}
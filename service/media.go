@@ -11,7 +11,9 @@ import (
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
+	"gitlab.scorum.com/blog/api/broadcast"
 	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/common"
 	"gitlab.scorum.com/blog/api/db"
 	"gitlab.scorum.com/blog/api/rpc"
 	"gitlab.scorum.com/blog/api/service/image"
@@ -41,10 +43,14 @@ var (
 	previewNotificationSizeSmall = 48
 )
 
-func (blog *Blog) UploadMedia(op types.Operation) *rpc.Error {
+func (blog *Blog) UploadMedia(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.UploadMediaOperation)
 
-	exists, err := blog.checkAccountExists(in.Account)
+	if err := blog.checkNotSuspended(tx.Tx, in.Account); err != nil {
+		return err
+	}
+
+	exists, err := blog.checkAccountExists(tx, in.Account)
 	if err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
 	}
@@ -59,7 +65,7 @@ func (blog *Blog) UploadMedia(op types.Operation) *rpc.Error {
 
 	mediaID := strings.ToLower(in.ID)
 
-	err = blog.DB.Read.Get(&exists, `SELECT EXISTS(SELECT * FROM media WHERE account = $1 AND id = $2)`, in.Account, mediaID)
+	err = tx.Get(&exists, `SELECT EXISTS(SELECT * FROM media WHERE account = $1 AND id = $2)`, in.Account, mediaID)
 	if err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
 	}
@@ -107,11 +113,11 @@ func (blog *Blog) UploadMedia(op types.Operation) *rpc.Error {
 	AddPreviewHighToImage(img)
 
 	// upload images
-	url, err := blog.uploadImage(in.Account, in.ID, img)
+	url, err := blog.uploadImage(tx, in.Account, in.ID, img)
 	if err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
 	}
-	_, err = blog.uploadThumbnails(in.Account, in.ID, img)
+	_, err = blog.uploadThumbnails(tx, in.Account, in.ID, img)
 	if err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
 	}
@@ -123,7 +129,7 @@ func (blog *Blog) UploadMedia(op types.Operation) *rpc.Error {
 	meta["height"] = originalSize.Y
 
 	// save to db
-	_, err = blog.DB.Write.NamedExec(
+	_, err = tx.NamedExec(
 		`INSERT INTO media (account, id, url, content_type, meta)
 					VALUES (:account, :id, :url, :content_type, :meta)`, &db.Media{
 			Account:     in.Account,
@@ -218,7 +224,7 @@ func AddPreviewHighToImage(img *image.Image) {
 	img.AddThumbNeat(previewHighPostfix, width, height, cropWidth, cropHeight)
 }
 
-func (blog *Blog) uploadThumbnails(account string, id string, image *image.Image) ([]string, error) {
+func (blog *Blog) uploadThumbnails(tx *broadcast.Tx, account string, id string, image *image.Image) ([]string, error) {
 	thumbs := image.Thumbs
 	urls := make([]string, len(thumbs))
 
@@ -229,7 +235,7 @@ func (blog *Blog) uploadThumbnails(account string, id string, image *image.Image
 			return nil, errors.Wrapf(err, "failed to encode %s thumb", thumb.Postfix)
 		}
 
-		url, err := blog.Blob.UploadMedia(account, fmt.Sprintf("%s_%s", id, thumb.Postfix), buffer.Bytes(), image.ContentType)
+		url, err := blog.uploadBlob(tx, account, fmt.Sprintf("%s_%s", id, thumb.Postfix), buffer.Bytes(), image.ContentType)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to upload %s thumb", thumb.Postfix)
 		}
@@ -294,14 +300,14 @@ func AddProfilePreviewToImage(img *image.Image) {
 	img.AddThumbNeat(previewPostfix, width, height, cropWidth, cropHeight)
 }
 
-func (blog *Blog) uploadImage(account string, id string, image *image.Image) (string, error) {
+func (blog *Blog) uploadImage(tx *broadcast.Tx, account string, id string, image *image.Image) (string, error) {
 	var buffer bytes.Buffer
 	if err := image.Encode(&buffer, image.Original); err != nil {
 		return "", errors.Wrap(err, "failed to encode original")
 	}
 
 	// upload original
-	url, err := blog.Blob.UploadMedia(account, id, buffer.Bytes(), image.ContentType)
+	url, err := blog.uploadBlob(tx, account, id, buffer.Bytes(), image.ContentType)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to upload original image")
 	}
@@ -309,6 +315,26 @@ func (blog *Blog) uploadImage(account string, id string, image *image.Image) (st
 	return url, nil
 }
 
+// uploadBlob uploads the content to the blob storage, the upload is skipped for the dry run transactions
+// and the uploaded blob is deleted if the transaction is rolled back
+func (blog *Blog) uploadBlob(tx *broadcast.Tx, account string, id string, content []byte, contentType common.ContentType) (string, error) {
+	if tx.IsDryRun() {
+		return blog.Blob.MediaURL(account, id), nil
+	}
+
+	url, err := blog.Blob.UploadMedia(account, id, content, contentType)
+	if err != nil {
+		return "", err
+	}
+
+	tx.OnRollback(func() {
+		if err := blog.Blob.DeleteMedia(account, id); err != nil {
+			log.Errorf("failed to delete %s media of the rolled back transaction: %s", url, err)
+		}
+	})
+	return url, nil
+}
+
 func (blog *Blog) GetMedia(ctx *rpc.Context) {
 	var account string
 	if err := ctx.Param(0, &account); err != nil {
@@ -77,8 +77,8 @@ func TestBlog_UploadMedia_PNG(t *testing.T) {
 		ContentType: common.ImagePngContentType,
 	}
 
-	require.Nil(t, handler.UploadMedia(op))
-	require.NotNil(t, handler.UploadMedia(op), "upload for the second time with the same ID, should fail")
+	require.Nil(t, apply(t, handler.UploadMedia, op))
+	require.NotNil(t, apply(t, handler.UploadMedia, op), "upload for the second time with the same ID, should fail")
 
 	// thumbs
 	exists, err := handler.Blob.DoesMediaExists(leonarda, fmt.Sprintf("%s_%d", op.ID, 96))
@@ -105,7 +105,7 @@ func TestBlog_UploadMedia_PNG800(t *testing.T) {
 		ContentType: common.ImagePngContentType,
 	}
 
-	require.Nil(t, handler.UploadMedia(op))
+	require.Nil(t, apply(t, handler.UploadMedia, op))
 
 	exists, err := handler.Blob.DoesMediaExists(leonarda, fmt.Sprintf("%s_%d", op.ID, 96))
 	require.NoError(t, err)
@@ -135,7 +135,7 @@ func TestBlog_UploadMedia_GIF(t *testing.T) {
 		ContentType: common.ImageGifContentType,
 	}
 
-	require.Nil(t, handler.UploadMedia(op))
+	require.Nil(t, apply(t, handler.UploadMedia, op))
 
 	exists, err := handler.Blob.DoesMediaExists(leonarda, fmt.Sprintf("%s_%d", op.ID, 384))
 	require.NoError(t, err)
@@ -156,7 +156,7 @@ func TestBlog_UploadMedia_JPEG(t *testing.T) {
 		ContentType: common.ImageJpegContentType,
 	}
 
-	require.Nil(t, handler.UploadMedia(op))
+	require.Nil(t, apply(t, handler.UploadMedia, op))
 }
 
 func TestBlog_UploadMedia_ValidationTest(t *testing.T) {
@@ -214,7 +214,7 @@ func TestBlog_UploadMedia_ValidationTest(t *testing.T) {
 
 		cop := op
 		cop.ContentType = "application/octet"
-		err := handler.UploadMedia(&cop)
+		err := apply(t, handler.UploadMedia, &cop)
 
 		require.NotNil(t, err)
 		require.Equal(t, err.Message, "invalid content_type")
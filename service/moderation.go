@@ -0,0 +1,203 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"gitlab.scorum.com/blog/api/broadcast"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
+	"gitlab.scorum.com/blog/api/utils/postgres"
+)
+
+// moderation decisions of the resolve_moderation_case_admin operation
+const (
+	decisionBlacklist     = "blacklist"
+	decisionDismiss       = "dismiss"
+	decisionSuspendAuthor = "suspend_author"
+)
+
+var moderationDecisions = map[string]db.ModerationStatus{
+	decisionBlacklist:     db.ModerationStatusBlacklisted,
+	decisionDismiss:       db.ModerationStatusDismissed,
+	decisionSuspendAuthor: db.ModerationStatusAuthorSuspended,
+}
+
+// ModerationQuery is the filter of the moderation_api.get_cases, empty fields are ignored
+type ModerationQuery struct {
+	Domain string `json:"domain"`
+	Status string `json:"status"`
+	// Before is the id of the last case of the previous page
+	Before int64 `json:"before"`
+	Limit  int   `json:"limit"`
+}
+
+func (blog *Blog) ResolveModerationCaseAdmin(tx *broadcast.Tx, op types.Operation) *rpc.Error {
+	in := op.(*types.ResolveModerationCaseAdminOperation)
+
+	if err := blog.checkPermission(blog.RolesStorage.InTx(tx.Tx), in.Account, db.RoleModerator, ""); err != nil {
+		return err
+	}
+
+	status, ok := moderationDecisions[in.Decision]
+	if !ok {
+		return NewError(rpc.InvalidParameterCode, "invalid decision")
+	}
+
+	moderation := blog.ModerationStorage.InTx(tx.Tx)
+	c, err := moderation.Get(in.CaseID)
+	if err != nil {
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+	if c == nil {
+		return NewError(rpc.ModerationCaseNotFoundCode, fmt.Sprintf("case %d not found", in.CaseID))
+	}
+
+	now := time.Now().UTC()
+	resolved, err := moderation.Resolve(db.ModerationDecision{
+		CaseID:    c.ID,
+		Decision:  status,
+		Moderator: in.Account,
+		Note:      in.Note,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+	if !resolved {
+		return NewError(rpc.ModerationCaseResolvedCode, fmt.Sprintf("case %d is already %s", c.ID, c.Status))
+	}
+
+	switch in.Decision {
+	case decisionBlacklist:
+		reason := db.BlacklistReason(c.Reason)
+		if !reason.IsValid() {
+			reason = db.BlacklistReasonOther
+		}
+		return blog.doAddToBlacklist(tx.Tx, db.BlacklistEntry{
+			Account:   c.Author,
+			Permlink:  c.Permlink,
+			Reason:    reason,
+			Note:      in.Note,
+			AddedBy:   sql.NullString{String: in.Account, Valid: true},
+			CreatedAt: pq.NullTime{Time: now, Valid: true},
+		})
+	case decisionSuspendAuthor:
+		suspension := db.Suspension{
+			Account:     c.Author,
+			Reason:      fmt.Sprintf("%s: %s/%s", c.Reason, c.Author, c.Permlink),
+			SuspendedBy: in.Account,
+			CreatedAt:   now,
+		}
+		if in.Duration > 0 {
+			suspension.ExpiresAt = pq.NullTime{Time: now.Add(time.Duration(in.Duration) * time.Second), Valid: true}
+		}
+		if err := blog.SuspensionsStorage.InTx(tx.Tx).Suspend(suspension); err != nil {
+			if foreignKeyError, _ := postgres.IsForeignKeyViolationError(err); foreignKeyError {
+				return NewError(rpc.ProfileNotFoundCode, fmt.Sprintf("%s not found", c.Author))
+			}
+			return WrapError(rpc.InternalErrorCode, err)
+		}
+	}
+
+	return nil
+}
+
+// openDownvotesCase opens the moderation case for the post once its downvotes with the configured reasons
+// reach the threshold, the case reason is the most frequent one of them
+func (blog *Blog) openDownvotesCase(tx *sqlx.Tx, author, permlink string) *rpc.Error {
+	config := blog.Config.Moderation
+	if config.DownvoteThreshold <= 0 || len(config.DownvoteReasons) == 0 {
+		return nil
+	}
+
+	counts, err := blog.DownvotesStorage.InTx(tx).CountByReasons(author, permlink, config.DownvoteReasons)
+	if err != nil {
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+
+	var (
+		total  int
+		reason db.DownvoteReason
+	)
+	for _, r := range config.DownvoteReasons {
+		total += counts[r]
+		if counts[r] > counts[reason] {
+			reason = r
+		}
+	}
+
+	if total < config.DownvoteThreshold {
+		return nil
+	}
+
+	var domain string
+	err = tx.Get(&domain, `SELECT COALESCE(domain::TEXT, '') FROM comments WHERE author = $1 AND permlink = $2`,
+		author, permlink)
+	if err != nil && err != sql.ErrNoRows {
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+
+	_, err = blog.ModerationStorage.InTx(tx).Open(db.ModerationCase{
+		Author:   author,
+		Permlink: permlink,
+		Domain:   domain,
+		Source:   db.ModerationSourceDownvotes,
+		Reason:   string(reason),
+		OpenedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+	return nil
+}
+
+// GetModerationCases returns the moderation cases starting from the latest one, moderators only
+func (blog *Blog) GetModerationCases(ctx *rpc.Context, account string, params []*json.RawMessage) {
+	if err := blog.checkPermission(blog.RolesStorage, account, db.RoleModerator, ""); err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	var query ModerationQuery
+	if err := getParam(params, 0, &query); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	cases, rpcErr := blog.doGetModerationCases(query)
+	if rpcErr != nil {
+		ctx.WriteError(rpcErr.Code, rpcErr.Message)
+		return
+	}
+
+	ctx.WriteResult(cases)
+}
+
+func (blog *Blog) doGetModerationCases(query ModerationQuery) ([]*ModerationCase, *rpc.Error) {
+	if query.Limit <= 0 || query.Limit > maxLargePageSize {
+		return nil, NewError(rpc.InvalidParameterCode, "invalid limit")
+	}
+
+	status := db.ModerationStatus(query.Status)
+	if status != "" && !status.IsValid() {
+		return nil, NewError(rpc.InvalidParameterCode, "invalid status")
+	}
+
+	cases, err := blog.ModerationStorage.Find(db.ModerationFilter{
+		Domain: query.Domain,
+		Status: status,
+		Before: query.Before,
+		Limit:  query.Limit,
+	})
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	return toAPIModerationCases(cases), nil
+}
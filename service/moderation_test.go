@@ -0,0 +1,173 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
+)
+
+func TestBlog_ModerationQueue(t *testing.T) {
+	defer cleanUp(t)
+
+	registerAccount(t, leonarda)
+	registerAccount(t, kristie)
+	registerAccount(t, sheldon)
+
+	downvote := func(account string, reason db.DownvoteReason) {
+		require.Nil(t, apply(t, handler.Downvote, &types.DownvoteOperation{
+			Account:  account,
+			Author:   kristie,
+			Permlink: "spam-post",
+			Reason:   string(reason),
+		}))
+	}
+
+	openCases := func() []*ModerationCase {
+		cases, err := handler.doGetModerationCases(ModerationQuery{Status: "open", Limit: 10})
+		require.Nil(t, err)
+		return cases
+	}
+
+	t.Run("below_threshold", func(t *testing.T) {
+		downvote(leonarda, db.DownvoteReasonSpam)
+		downvote(sheldon, db.DownvoteReasonLowQualityContent)
+		require.Empty(t, openCases())
+	})
+
+	var caseID int64
+	t.Run("opened", func(t *testing.T) {
+		downvote(sheldon, db.DownvoteReasonSpam)
+
+		cases := openCases()
+		require.Len(t, cases, 1)
+		require.Equal(t, kristie, cases[0].Author)
+		require.Equal(t, "spam-post", cases[0].Permlink)
+		require.Equal(t, string(db.ModerationSourceDownvotes), cases[0].Source)
+		require.Equal(t, string(db.DownvoteReasonSpam), cases[0].Reason)
+		caseID = cases[0].ID
+	})
+
+	resolve := &types.ResolveModerationCaseAdminOperation{
+		Account:  leonarda,
+		CaseID:   caseID,
+		Decision: "blacklist",
+		Note:     "ads",
+	}
+
+	t.Run("not_moderator", func(t *testing.T) {
+		cop := *resolve
+		cop.Account = sheldon
+		err := apply(t, handler.ResolveModerationCaseAdmin, &cop)
+		require.NotNil(t, err)
+		require.Equal(t, rpc.AccessDeniedCode, err.Code)
+	})
+
+	t.Run("invalid_decision", func(t *testing.T) {
+		cop := *resolve
+		cop.Decision = "ban"
+		err := apply(t, handler.ResolveModerationCaseAdmin, &cop)
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		cop := *resolve
+		cop.CaseID = caseID + 100
+		err := apply(t, handler.ResolveModerationCaseAdmin, &cop)
+		require.NotNil(t, err)
+		require.Equal(t, rpc.ModerationCaseNotFoundCode, err.Code)
+	})
+
+	t.Run("blacklisted", func(t *testing.T) {
+		require.Nil(t, apply(t, handler.ResolveModerationCaseAdmin, resolve))
+		require.Empty(t, openCases())
+
+		blacklisted, err := handler.checkIsBlacklisted(kristie, "spam-post")
+		require.Nil(t, err)
+		require.True(t, *blacklisted)
+
+		cases, err := handler.doGetModerationCases(ModerationQuery{Status: "blacklisted", Limit: 10})
+		require.Nil(t, err)
+		require.Len(t, cases, 1)
+		require.Equal(t, leonarda, cases[0].Moderator)
+		require.Equal(t, "ads", cases[0].Note)
+		require.NotEmpty(t, cases[0].DecidedAt)
+
+		err = apply(t, handler.ResolveModerationCaseAdmin, resolve)
+		require.NotNil(t, err)
+		require.Equal(t, rpc.ModerationCaseResolvedCode, err.Code)
+	})
+
+	t.Run("not_reopened", func(t *testing.T) {
+		downvote(kristie, db.DownvoteReasonPlagiarism)
+		require.Empty(t, openCases())
+	})
+
+	t.Run("author_suspended", func(t *testing.T) {
+		opened, err := handler.ModerationStorage.Open(db.ModerationCase{
+			Author:   kristie,
+			Permlink: "copied-post",
+			Domain:   "com",
+			Source:   db.ModerationSourcePlagiarism,
+			Reason:   string(db.DownvoteReasonPlagiarism),
+			OpenedAt: time.Now().UTC(),
+		})
+		require.NoError(t, err)
+		require.True(t, opened)
+
+		cases, rpcErr := handler.doGetModerationCases(ModerationQuery{Domain: "com", Limit: 10})
+		require.Nil(t, rpcErr)
+		require.Len(t, cases, 1)
+
+		require.Nil(t, apply(t, handler.ResolveModerationCaseAdmin, &types.ResolveModerationCaseAdminOperation{
+			Account:  leonarda,
+			CaseID:   cases[0].ID,
+			Decision: "suspend_author",
+			Duration: 3600,
+		}))
+
+		profile, rpcErr := handler.doGetProfile(kristie)
+		require.Nil(t, rpcErr)
+		require.NotNil(t, profile.Suspension)
+		require.NotEmpty(t, profile.Suspension.ExpiresAt)
+	})
+
+	t.Run("author_not_found", func(t *testing.T) {
+		opened, err := handler.ModerationStorage.Open(db.ModerationCase{
+			Author:   "unknown",
+			Permlink: "copied-post",
+			Domain:   "me",
+			Source:   db.ModerationSourcePlagiarism,
+			Reason:   string(db.DownvoteReasonPlagiarism),
+			OpenedAt: time.Now().UTC(),
+		})
+		require.NoError(t, err)
+		require.True(t, opened)
+
+		cases, rpcErr := handler.doGetModerationCases(ModerationQuery{Domain: "me", Limit: 10})
+		require.Nil(t, rpcErr)
+		require.Len(t, cases, 1)
+
+		rpcErr = apply(t, handler.ResolveModerationCaseAdmin, &types.ResolveModerationCaseAdminOperation{
+			Account:  leonarda,
+			CaseID:   cases[0].ID,
+			Decision: "suspend_author",
+		})
+		require.NotNil(t, rpcErr)
+		require.Equal(t, rpc.ProfileNotFoundCode, rpcErr.Code)
+	})
+
+	t.Run("invalid_query", func(t *testing.T) {
+		_, err := handler.doGetModerationCases(ModerationQuery{Limit: 0})
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+
+		_, err = handler.doGetModerationCases(ModerationQuery{Status: "closed", Limit: 10})
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+	})
+}
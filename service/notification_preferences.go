@@ -0,0 +1,118 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
+)
+
+const quietHoursLayout = "15:04"
+
+// updateNotificationPreferences upserts the preferences and the quiet hours of the operation if provided
+func (blog *Blog) updateNotificationPreferences(tx *sqlx.Tx, in *types.UpdateProfileSettingsOperation) *rpc.Error {
+	if len(in.NotificationPreferences) > len(db.NotificationTypes)*len(db.NotificationChannels) {
+		return NewError(rpc.InvalidParameterCode, "too many notification preferences")
+	}
+
+	preferences := make([]db.NotificationPreference, len(in.NotificationPreferences))
+	for idx, p := range in.NotificationPreferences {
+		preference := db.NotificationPreference{
+			Account: in.Account,
+			Type:    db.NotificationType(p.Type),
+			Channel: db.NotificationChannel(p.Channel),
+			Enabled: p.Enabled,
+		}
+		if !preference.Type.IsValid() {
+			return NewError(rpc.InvalidParameterCode, fmt.Sprintf("unknown notification type %s", p.Type))
+		}
+		if !preference.Channel.IsValid() {
+			return NewError(rpc.InvalidParameterCode, fmt.Sprintf("unknown notification channel %s", p.Channel))
+		}
+		preferences[idx] = preference
+	}
+
+	storage := blog.NotificationPreferencesStorage.InTx(tx)
+	if err := storage.Set(preferences); err != nil {
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+
+	if in.QuietHours == nil {
+		return nil
+	}
+
+	quietHours, rpcErr := toQuietHours(in.QuietHours)
+	if rpcErr != nil {
+		return rpcErr
+	}
+
+	if err := storage.SetQuietHours(in.Account, quietHours); err != nil {
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+	return nil
+}
+
+// toQuietHours validates the quiet hours of the operation, returns nil if the start and the end are empty
+func toQuietHours(in *types.QuietHours) (*db.QuietHours, *rpc.Error) {
+	if in.Start == "" && in.End == "" {
+		return nil, nil
+	}
+
+	start, err := time.Parse(quietHoursLayout, in.Start)
+	if err != nil {
+		return nil, NewError(rpc.InvalidParameterCode, "invalid quiet hours start")
+	}
+	end, err := time.Parse(quietHoursLayout, in.End)
+	if err != nil {
+		return nil, NewError(rpc.InvalidParameterCode, "invalid quiet hours end")
+	}
+
+	timeZone := in.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return nil, NewError(rpc.InvalidParameterCode, fmt.Sprintf("unknown time zone %s", in.TimeZone))
+	}
+
+	return &db.QuietHours{
+		Start:    int16(start.Hour()*60 + start.Minute()),
+		End:      int16(end.Hour()*60 + end.Minute()),
+		TimeZone: timeZone,
+	}, nil
+}
+
+// getNotificationPreferences returns the full matrix of the preferences of the account and its quiet hours
+func (blog *Blog) getNotificationPreferences(account string) ([]*NotificationPreference, *QuietHours, *rpc.Error) {
+	configured, err := blog.NotificationPreferencesStorage.Get(account)
+	if err != nil {
+		return nil, nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	enabled := make(map[string]bool, len(configured))
+	for _, p := range configured {
+		enabled[string(p.Type)+"/"+string(p.Channel)] = p.Enabled
+	}
+
+	preferences := make([]*NotificationPreference, 0, len(db.NotificationTypes)*len(db.NotificationChannels))
+	for _, t := range db.NotificationTypes {
+		for _, c := range db.NotificationChannels {
+			e, ok := enabled[string(t)+"/"+string(c)]
+			preferences = append(preferences, &NotificationPreference{
+				Type:    string(t),
+				Channel: string(c),
+				Enabled: !ok || e,
+			})
+		}
+	}
+
+	quietHours, err := blog.NotificationPreferencesStorage.GetQuietHours(account)
+	if err != nil {
+		return nil, nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	return preferences, toAPIQuietHours(quietHours), nil
+}
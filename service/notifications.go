@@ -3,6 +3,7 @@ package service
 import (
 	"encoding/json"
 
+	"gitlab.scorum.com/blog/api/broadcast"
 	"gitlab.scorum.com/blog/api/broadcast/types"
 	"gitlab.scorum.com/blog/api/rpc"
 )
@@ -20,30 +21,30 @@ func (blog *Blog) GetNotifications(ctx *rpc.Context, account string, _ []*json.R
 	ctx.WriteResult(toAPINotifications(notifications))
 }
 
-func (blog *Blog) MarkRead(op types.Operation) *rpc.Error {
+func (blog *Blog) MarkRead(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.MarkNotificationReadOperation)
 
-	if err := blog.NotificationStorage.MarkRead(in.Account, in.ID); err != nil {
+	if err := blog.NotificationStorage.InTx(tx.Tx).MarkRead(in.Account, in.ID); err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
 	}
 
 	return nil
 }
 
-func (blog *Blog) MarkReadAll(op types.Operation) *rpc.Error {
+func (blog *Blog) MarkReadAll(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.MarkAllNotificationsReadOperation)
 
-	if err := blog.NotificationStorage.MarkAllRead(in.Account); err != nil {
+	if err := blog.NotificationStorage.InTx(tx.Tx).MarkAllRead(in.Account); err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
 	}
 
 	return nil
 }
 
-func (blog *Blog) MarkSeenAll(op types.Operation) *rpc.Error {
+func (blog *Blog) MarkSeenAll(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.MarkAllNotificationsSeenOperation)
 
-	if err := blog.NotificationStorage.MarkAllSeen(in.Account); err != nil {
+	if err := blog.NotificationStorage.InTx(tx.Tx).MarkAllSeen(in.Account); err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
 	}
 
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
+)
+
+// ProfilesPage is the page of the cursor paginated profile lists, NextCursor is empty on the last page
+type ProfilesPage struct {
+	Items      []*Profile `json:"items"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// PostIDsPage is the page of the cursor paginated post lists, NextCursor is empty on the last page
+type PostIDsPage struct {
+	Items      []*PostID `json:"items"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// pageCursor is the time and the keys of the last item of the page, the items are ordered by them descending
+type pageCursor struct {
+	Time time.Time `json:"t"`
+	Keys []string  `json:"k"`
+}
+
+func (c pageCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodePageCursor decodes the cursor with the given number of keys, the empty cursor is the first page
+func decodePageCursor(s string, keys int) (*pageCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var c pageCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	if len(c.Keys) != keys {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	return &c, nil
+}
+
+// pageParams validates the limit and returns the cursor time and keys as the query args,
+// the time is null and the keys are empty on the first page
+func pageParams(cursor string, keys int, limit uint32) (pq.NullTime, []string, *rpc.Error) {
+	if limit == 0 || limit > maxLargePageSize {
+		return pq.NullTime{}, nil, NewError(rpc.InvalidParameterCode, "invalid limit")
+	}
+
+	c, err := decodePageCursor(cursor, keys)
+	if err != nil {
+		return pq.NullTime{}, nil, NewError(rpc.InvalidParameterCode, "invalid cursor")
+	}
+
+	if c == nil {
+		return pq.NullTime{}, make([]string, keys), nil
+	}
+	return pq.NullTime{Time: c.Time, Valid: true}, c.Keys, nil
+}
+
+// profileCursorRow is the profile with the time it has been listed at
+type profileCursorRow struct {
+	db.Profile
+	ListedAt time.Time `db:"listed_at"`
+}
+
+func toProfilesPage(rows []*profileCursorRow, limit uint32) *ProfilesPage {
+	page := ProfilesPage{Items: make([]*Profile, len(rows))}
+	for idx, row := range rows {
+		page.Items[idx] = toAPIProfile(&row.Profile)
+	}
+	if len(rows) == int(limit) {
+		last := rows[len(rows)-1]
+		page.NextCursor = pageCursor{Time: last.ListedAt, Keys: []string{last.Account}}.encode()
+	}
+	return &page
+}
+
+// readPageParams reads the cursor and the limit params of the public cursor paginated APIs
+func readPageParams(ctx *rpc.Context, at int) (cursor string, limit uint32, ok bool) {
+	if err := ctx.Param(at, &cursor); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return "", 0, false
+	}
+
+	if err := ctx.Param(at+1, &limit); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return "", 0, false
+	}
+
+	return cursor, limit, true
+}
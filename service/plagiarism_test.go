@@ -24,7 +24,7 @@ func TestCheckPostPlagiarism(t *testing.T) {
 	t.Skip()
 	defer cleanUp(t)
 
-	require.Nil(t, handler.Register(&types.RegisterOperation{leonarda}))
+	require.Nil(t, apply(t, handler.Register, &types.RegisterOperation{leonarda}))
 	ap := NewAntiPlagiarismService(textRUKey, db.NewPlagiarismStorage(dbWrite), db.NewCommentsStorage(dbWrite))
 	_, err := ap.CheckPost(
 		leonarda,
@@ -47,7 +47,7 @@ func TestGetPostPlagiarismCheckDetails(t *testing.T) {
 	t.Skip()
 	defer cleanUp(t)
 
-	require.Nil(t, handler.Register(&types.RegisterOperation{leonarda}))
+	require.Nil(t, apply(t, handler.Register, &types.RegisterOperation{leonarda}))
 	ap := NewAntiPlagiarismService(textRUKey, db.NewPlagiarismStorage(dbWrite), db.NewCommentsStorage(dbWrite))
 	_, err := ap.CheckPost(
 		leonarda,
@@ -79,7 +79,7 @@ func TestGetPostLink(t *testing.T) {
 	permlink := "test-123perm"
 	author := "abel"
 
-	require.Nil(t, handler.Register(&types.RegisterOperation{author}))
+	require.Nil(t, apply(t, handler.Register, &types.RegisterOperation{author}))
 	_, err = handler.DB.Write.Exec(`INSERT INTO comments(permlink, author, json_metadata, body, title, updated_at, created_at)
 		VALUES($1, $2, $3, $4, $5, $6, $7)`, permlink, author, string(metaBytes), "123", "ti", time.Now(), time.Now())
 	require.NoError(t, err)
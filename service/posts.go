@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"time"
 
 	"gitlab.scorum.com/blog/api/db"
 	"gitlab.scorum.com/blog/api/rpc"
@@ -78,6 +79,53 @@ func (blog *Blog) doGetDeletedPosts(from uint32, limit uint16) ([]*PostID, *rpc.
 	return toAPIPostIDs(entries), nil
 }
 
+// GetDeletedPostsPage is get_deleted_posts paginated by the cursor, the recently deleted posts go first
+func (blog *Blog) GetDeletedPostsPage(ctx *rpc.Context) {
+	cursor, limit, ok := readPageParams(ctx, 0)
+	if !ok {
+		return
+	}
+
+	page, err := blog.doGetDeletedPostsPage(cursor, limit)
+	if err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	ctx.WriteResult(page)
+}
+
+func (blog *Blog) doGetDeletedPostsPage(cursor string, limit uint32) (*PostIDsPage, *rpc.Error) {
+	after, keys, rpcErr := pageParams(cursor, 2, limit)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	var rows []*struct {
+		db.PostID
+		CreatedAt time.Time `db:"created_at"`
+	}
+	err := blog.DB.Read.Select(&rows,
+		`SELECT account, permlink, created_at FROM deleted_posts
+				WHERE $1::TIMESTAMP IS NULL OR (created_at, account, permlink) < ($1, $2, $3)
+				ORDER BY created_at DESC, account DESC, permlink DESC
+				LIMIT $4`, after, keys[0], keys[1], limit)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	page := PostIDsPage{Items: make([]*PostID, len(rows))}
+	for idx, row := range rows {
+		page.Items[idx] = &PostID{Account: row.Account, Permlink: row.Permlink}
+	}
+	if len(rows) == int(limit) {
+		last := rows[len(rows)-1]
+		page.NextCursor = pageCursor{Time: last.CreatedAt, Keys: []string{last.Account, last.Permlink}}.encode()
+	}
+
+	return &page, nil
+}
+
 func (blog *Blog) GetPostsFromNetwork(ctx *rpc.Context) {
 	var account string
 	if err := ctx.Param(0, &account); err != nil {
@@ -123,23 +171,61 @@ func (blog *Blog) GetPostsFromNetwork(ctx *rpc.Context) {
 }
 
 func (blog *Blog) doGetPostsFromNetwork(account string, domain Domain, from uint32, limit uint32) ([]*PostID, *rpc.Error) {
+	var subscribed bool
+	err := blog.DB.Read.Get(&subscribed, `SELECT EXISTS(SELECT * FROM topic_subscriptions WHERE account = $1 AND domain = $2)`,
+		account, string(domain))
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	// the posts of the subscribed categories and tags are looked up only if the account has any subscriptions,
+	// the tags are matched in lowercase
+	var topics string
+	if subscribed {
+		topics = `
+					UNION
+					SELECT c.author, c.permlink, c.created_at
+					FROM comments c
+					INNER JOIN topic_subscriptions s
+						ON s.account = $1 AND s.domain = c.domain AND s.kind = 'category'
+							AND c.json_metadata->'categories' @> jsonb_build_array(s.topic)
+					WHERE c.parent_author IS NULL AND c.domain = $2
+					UNION
+					SELECT c.author, c.permlink, c.created_at
+					FROM comments c
+					INNER JOIN topic_subscriptions s
+						ON s.account = $1 AND s.domain = c.domain AND s.kind = 'tag'
+							AND lower((c.json_metadata->'tags')::TEXT)::JSONB @> jsonb_build_array(s.topic)
+					WHERE c.parent_author IS NULL AND c.domain = $2`
+	}
+
 	var entries []*db.PostID
 
-	// Take followers' posts ordered by created date descending.
-	// Exclude both deleted and blacklisted posts
+	// Take posts of the followed authors, the subscribed categories and tags ordered by created date descending.
+	// Exclude own, deleted and blacklisted posts and posts of the suspended, muted and blocked accounts
 	// Return paged result
-	err := blog.DB.Read.Select(&entries,
-		`SELECT comments.author AS account, comments.permlink
-				FROM comments
-				INNER JOIN followers f ON comments.author = f.follow_account
+	err = blog.DB.Read.Select(&entries, fmt.Sprintf(
+		`WITH candidates AS (
+					SELECT c.author, c.permlink, c.created_at
+					FROM comments c
+					INNER JOIN followers f ON c.author = f.follow_account
+					WHERE f.account = $1 AND c.parent_author IS NULL AND c.domain = $2%s
+				)
+				SELECT comments.author AS account, comments.permlink
+				FROM candidates comments
 				WHERE
-					f.account = $1 AND comments.parent_author IS NULL AND comments.domain = $2
+					comments.author <> $1
 					AND NOT EXISTS (
-						SELECT * FROM blacklist WHERE comments.author = blacklist.account AND comments.permlink = blacklist.permlink)
+						SELECT * FROM blacklist WHERE comments.author = blacklist.account AND blacklist.permlink IN (comments.permlink, ''))
 					AND NOT EXISTS (
 						SELECT* FROM deleted_posts WHERE comments.author = deleted_posts.account AND comments.permlink = deleted_posts.permlink)
+					AND NOT EXISTS (
+						SELECT * FROM active_suspensions WHERE comments.author = active_suspensions.account)
+					AND NOT EXISTS (
+						SELECT * FROM account_lists l
+						WHERE l.account = $1 AND l.target = comments.author AND l.list IN ('muted', 'blocked'))
 				ORDER BY comments.created_at DESC
-				LIMIT $3 OFFSET $4`, account, string(domain), limit, from)
+				LIMIT $3 OFFSET $4`, topics), account, string(domain), limit, from)
 	if err != nil {
 		return nil, WrapError(rpc.InternalErrorCode, err)
 	}
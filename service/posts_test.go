@@ -19,9 +19,9 @@ import (
 func TestBlog_GetPostsFromNetwork(t *testing.T) {
 	defer cleanUp(t)
 
-	require.Nil(t, handler.Register(&types.RegisterOperation{leonarda}))
-	require.Nil(t, handler.Register(&types.RegisterOperation{kristie}))
-	require.Nil(t, handler.Register(&types.RegisterOperation{sheldon}))
+	require.Nil(t, apply(t, handler.Register, &types.RegisterOperation{leonarda}))
+	require.Nil(t, apply(t, handler.Register, &types.RegisterOperation{kristie}))
+	require.Nil(t, apply(t, handler.Register, &types.RegisterOperation{sheldon}))
 
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -30,12 +30,12 @@ func TestBlog_GetPostsFromNetwork(t *testing.T) {
 	handler.Notifier = notifier
 	notifier.EXPECT().NotifyStartedFollow(gomock.Any(), gomock.Any()).Times(2)
 
-	require.Nil(t, handler.Follow(&types.FollowOperation{
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{
 		Account: kristie,
 		Follow:  leonarda,
 	}))
 
-	require.Nil(t, handler.Follow(&types.FollowOperation{
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{
 		Account: kristie,
 		Follow:  sheldon,
 	}))
@@ -58,7 +58,7 @@ func TestBlog_GetPostsFromNetwork(t *testing.T) {
 	require.Empty(t, posts)
 
 	// blacklist
-	require.Nil(t, handler.AddToBlacklistAdmin(&types.AddToBlacklistAdminOperation{
+	require.Nil(t, apply(t, handler.AddToBlacklistAdmin, &types.AddToBlacklistAdminOperation{
 		Account:     leonarda,
 		BlogAccount: sheldon,
 		Permlink:    "post 3",
@@ -69,7 +69,7 @@ func TestBlog_GetPostsFromNetwork(t *testing.T) {
 	require.Len(t, posts, 4)
 
 	// unfollow
-	require.Nil(t, handler.Unfollow(&types.UnfollowOperation{
+	require.Nil(t, apply(t, handler.Unfollow, &types.UnfollowOperation{
 		Account:  kristie,
 		Unfollow: leonarda,
 	}))
@@ -78,7 +78,7 @@ func TestBlog_GetPostsFromNetwork(t *testing.T) {
 	require.Nil(t, err)
 	require.Len(t, posts, 2)
 
-	require.Nil(t, handler.Unfollow(&types.UnfollowOperation{
+	require.Nil(t, apply(t, handler.Unfollow, &types.UnfollowOperation{
 		Account:  kristie,
 		Unfollow: sheldon,
 	}))
@@ -89,6 +89,10 @@ func TestBlog_GetPostsFromNetwork(t *testing.T) {
 }
 
 func insertPost(t *testing.T, author, permlink string, domain Domain) {
+	insertPostWithMetadata(t, author, permlink, domain, common.JsonMetadata{})
+}
+
+func insertPostWithMetadata(t *testing.T, author, permlink string, domain Domain, metadata common.JsonMetadata) {
 	post := db.Comment{
 		Permlink:       permlink,
 		ParentPermlink: sql.NullString{Valid: true, String: "soccer"},
@@ -96,7 +100,7 @@ func insertPost(t *testing.T, author, permlink string, domain Domain) {
 		Body:           "body",
 		Title:          "title",
 		Domain:         sql.NullString{Valid: true, String: string(domain)},
-		JsonMetadata:   common.JsonMetadata{},
+		JsonMetadata:   metadata,
 		UpdatedAt:      time.Now(),
 		CreatedAt:      time.Now(),
 	}
@@ -111,3 +115,39 @@ func insertPost(t *testing.T, author, permlink string, domain Domain) {
 	require.NoError(t, err)
 
 }
+
+func TestBlog_GetDeletedPostsPage(t *testing.T) {
+	defer cleanUp(t)
+
+	registerAccount(t, leonarda)
+	registerAccount(t, sheldon)
+
+	deletedAt := time.Now().UTC()
+	for _, post := range []db.PostID{
+		{Account: leonarda, Permlink: "post 1"},
+		{Account: sheldon, Permlink: "post 1"},
+		{Account: sheldon, Permlink: "post 2"},
+	} {
+		_, err := dbWrite.Exec(`INSERT INTO deleted_posts(account, permlink, created_at) VALUES ($1, $2, $3)`,
+			post.Account, post.Permlink, deletedAt)
+		require.NoError(t, err)
+	}
+
+	var posts []*PostID
+	cursor := ""
+	for {
+		page, err := handler.doGetDeletedPostsPage(cursor, 2)
+		require.Nil(t, err)
+		posts = append(posts, page.Items...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	require.Equal(t, []*PostID{
+		{Account: sheldon, Permlink: "post 2"},
+		{Account: sheldon, Permlink: "post 1"},
+		{Account: leonarda, Permlink: "post 1"},
+	}, posts)
+}
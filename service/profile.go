@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"reflect"
 	"strconv"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
+	"gitlab.scorum.com/blog/api/broadcast"
 	"gitlab.scorum.com/blog/api/broadcast/types"
 	"gitlab.scorum.com/blog/api/db"
 	"gitlab.scorum.com/blog/api/rpc"
@@ -20,21 +24,9 @@ import (
 // Note blockchain monitor does registration as well, but because of blockchain consensus
 // it takes time to propagate data (wait for last irreversible block), therefore
 // this operation should be called via frontend as soon as new account created
-func (blog *Blog) Register(op types.Operation) (rpcErr *rpc.Error) {
+func (blog *Blog) Register(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.RegisterOperation)
 
-	tx, err := blog.DB.Write.Beginx()
-
-	defer func() {
-		if rpcErr != nil {
-			tx.Rollback()
-		}
-	}()
-
-	if err != nil {
-		return WrapError(rpc.InternalErrorCode, err)
-	}
-
 	if _, err := tx.Exec(
 		`INSERT INTO profiles(account, display_name)
                 VALUES($1, $2)
@@ -51,31 +43,23 @@ func (blog *Blog) Register(op types.Operation) (rpcErr *rpc.Error) {
 		return WrapError(rpc.InternalErrorCode, err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return WrapError(rpc.InternalErrorCode, err)
-	}
-
 	return nil
 }
 
-func (blog *Blog) UpdateProfile(op types.Operation) (rpcErr *rpc.Error) {
+func (blog *Blog) UpdateProfile(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.UpdateProfileOperation)
 
-	tx, err := blog.DB.Write.Beginx()
-
-	defer func() {
-		if rpcErr != nil {
-			tx.Rollback()
-		}
-	}()
-
-	if err != nil {
-		return WrapError(rpc.InternalErrorCode, err)
+	if err := blog.checkNotSuspended(tx.Tx, in.Account); err != nil {
+		return err
 	}
 
-	var profile db.Profile
-	err = tx.Get(&profile,
-		`SELECT account, display_name, location, bio, avatar_url, cover_url, created_at
+	var current struct {
+		db.Profile
+		db.ProfileExtra
+	}
+	err := tx.Get(&current,
+		`SELECT account, display_name, location, bio, avatar_url, cover_url, created_at,
+				website, social_links, language, pinned_post
 		FROM profiles WHERE account = $1 FOR UPDATE`,
 		in.Account)
 	if err != nil {
@@ -88,7 +72,7 @@ func (blog *Blog) UpdateProfile(op types.Operation) (rpcErr *rpc.Error) {
 	avatar := in.AvatarUrl
 	if avatar != "" {
 		// validate avatar url
-		media, err := blog.getMediaByUrl(in.Account, avatar)
+		media, err := blog.getMediaByUrl(tx, in.Account, avatar)
 		if err != nil {
 			if err == mediaNotFoundErr {
 				return NewError(rpc.MediaNotFoundCode, fmt.Sprintf("%s is not your media resource", avatar))
@@ -101,8 +85,8 @@ func (blog *Blog) UpdateProfile(op types.Operation) (rpcErr *rpc.Error) {
 		}
 
 		// create avatar images from origin
-		if avatar != profile.AvatarUrl {
-			err := blog.makeAndUploadAvatars(*media)
+		if avatar != current.AvatarUrl {
+			err := blog.makeAndUploadAvatars(tx, *media)
 			if err != nil {
 				return err
 			}
@@ -112,7 +96,7 @@ func (blog *Blog) UpdateProfile(op types.Operation) (rpcErr *rpc.Error) {
 	cover := in.CoverUrl
 	if cover != "" {
 		// validate cover url
-		media, err := blog.getMediaByUrl(in.Account, cover)
+		media, err := blog.getMediaByUrl(tx, in.Account, cover)
 		if err != nil {
 			if err == mediaNotFoundErr {
 				return NewError(rpc.MediaNotFoundCode, fmt.Sprintf("%s is not your media resource", cover))
@@ -125,13 +109,34 @@ func (blog *Blog) UpdateProfile(op types.Operation) (rpcErr *rpc.Error) {
 		}
 	}
 
-	profile = db.Profile{
+	extra := current.ProfileExtra
+	if in.Extra != nil {
+		var rpcErr *rpc.Error
+		if extra, rpcErr = blog.toProfileExtra(tx.Tx, in.Account, in.Extra); rpcErr != nil {
+			return rpcErr
+		}
+	}
+
+	profile := db.Profile{
 		Account:     in.Account,
 		DisplayName: in.DisplayName,
 		Location:    in.Location,
 		Bio:         in.Bio,
 		AvatarUrl:   avatar,
 		CoverUrl:    cover,
+		CreatedAt:   current.CreatedAt,
+	}
+
+	if profile == current.Profile && reflect.DeepEqual(extra, current.ProfileExtra) {
+		return nil
+	}
+
+	if err := blog.addProfileHistory(tx.Tx, db.ProfileHistoryEntry{
+		Profile:      current.Profile,
+		ProfileExtra: current.ProfileExtra,
+		ChangedAt:    time.Now().UTC(),
+	}); err != nil {
+		return err
 	}
 
 	_, err = tx.NamedExec(
@@ -141,16 +146,19 @@ func (blog *Blog) UpdateProfile(op types.Operation) (rpcErr *rpc.Error) {
 				  display_name= :display_name,
 				  bio = :bio,
 				  avatar_url = :avatar_url,
-				  cover_url = :cover_url
-				WHERE account = :account`, profile)
+				  cover_url = :cover_url,
+				  website = :website,
+				  social_links = :social_links,
+				  language = :language,
+				  pinned_post = :pinned_post
+				WHERE account = :account`, struct {
+			db.Profile
+			db.ProfileExtra
+		}{profile, extra})
 	if err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return WrapError(rpc.InternalErrorCode, err)
-	}
-
 	return nil
 }
 
@@ -243,7 +251,7 @@ func (blog *Blog) UnsubscribeEndpoint(w http.ResponseWriter, r *http.Request) {
 		EnableEmailUnseenNotifications: false,
 	}
 
-	updateErr := blog.doUpsertProfileSettings(settings)
+	updateErr := blog.doUpsertProfileSettings(blog.DB.Write, settings)
 	if updateErr != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Errorf("error while updating profile settings err:%s", updateErr)
@@ -253,7 +261,7 @@ func (blog *Blog) UnsubscribeEndpoint(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, redirect, http.StatusTemporaryRedirect)
 }
 
-func (blog *Blog) UpdateProfileSettings(op types.Operation) *rpc.Error {
+func (blog *Blog) UpdateProfileSettings(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.UpdateProfileSettingsOperation)
 
 	settings := &db.ProfileSettings{
@@ -261,18 +269,19 @@ func (blog *Blog) UpdateProfileSettings(op types.Operation) *rpc.Error {
 		EnableEmailUnseenNotifications: in.EnableEmailUnseenNotifications,
 	}
 
-	err := blog.doUpsertProfileSettings(settings)
+	err := blog.doUpsertProfileSettings(tx, settings)
 	if err != nil {
 		return WrapError(err.Code, err)
 	}
 
-	return nil
+	return blog.updateNotificationPreferences(tx.Tx, in)
 }
 
 func (blog *Blog) doGetProfile(account string) (*ExtendedProfile, *rpc.Error) {
 	var profile db.ExtendedProfile
 	err := blog.DB.Read.Get(&profile,
 		`SELECT account, display_name, location, bio, avatar_url, cover_url, created_at,
+				website, social_links, language, pinned_post,
 				(SELECT COUNT(*) FROM followers WHERE follow_account=$1) followers_count,
 				(SELECT COUNT(*) FROM followers WHERE account=$1) following_count
 				FROM profiles WHERE account = $1`, account)
@@ -283,7 +292,16 @@ func (blog *Blog) doGetProfile(account string) (*ExtendedProfile, *rpc.Error) {
 		return nil, WrapError(rpc.InternalErrorCode, err)
 	}
 
-	return toAPIExtendedProfile(&profile), nil
+	suspension, err := blog.SuspensionsStorage.Get(account)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	out := toAPIExtendedProfile(&profile)
+	if suspension != nil {
+		out.Suspension = toAPISuspension(suspension)
+	}
+	return out, nil
 }
 
 func (blog *Blog) doGetProfiles(accounts []string) ([]*Profile, *rpc.Error) {
@@ -320,11 +338,19 @@ func (blog *Blog) doGetProfileSettings(account string) (*ProfileSettings, *rpc.E
 		return nil, WrapError(rpc.InternalErrorCode, err)
 	}
 
-	return toAPIProfileSettings(&profileSettings), nil
+	out := toAPIProfileSettings(&profileSettings)
+
+	var rpcErr *rpc.Error
+	out.NotificationPreferences, out.QuietHours, rpcErr = blog.getNotificationPreferences(account)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	return out, nil
 }
 
-func (blog *Blog) doUpsertProfileSettings(settings *db.ProfileSettings) *rpc.Error {
-	_, err := blog.DB.Write.NamedExec(`
+func (blog *Blog) doUpsertProfileSettings(ext sqlx.Ext, settings *db.ProfileSettings) *rpc.Error {
+	_, err := sqlx.NamedExec(ext, `
 													 INSERT
 													 INTO profile_settings (account, enable_email_unseen_notifications)
 													 VALUES (:account, :enable_email_unseen_notifications)
@@ -340,10 +366,14 @@ func (blog *Blog) doUpsertProfileSettings(settings *db.ProfileSettings) *rpc.Err
 	return nil
 }
 
-func (blog *Blog) SetAccountTrustedAdmin(op types.Operation) *rpc.Error {
+func (blog *Blog) SetAccountTrustedAdmin(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.SetAccountTrustedAdminOperation)
 
-	_, err := blog.DB.Write.Exec(`UPDATE profiles SET is_trusted = $2 WHERE account = $1`, in.BlogAccount, in.IsTrusted)
+	if err := blog.checkPermission(blog.RolesStorage.InTx(tx.Tx), in.Account, db.RoleTrustManager, ""); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(`UPDATE profiles SET is_trusted = $2 WHERE account = $1`, in.BlogAccount, in.IsTrusted)
 
 	if err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
@@ -418,7 +448,43 @@ func (blog *Blog) doGetTrusted(from uint32, limit uint16) ([]*Profile, *rpc.Erro
 	return toAPIProfiles(profiles), nil
 }
 
-func (blog *Blog) makeAndUploadAvatars(media db.Media) *rpc.Error {
+// GetTrustedPage is get_trusted paginated by the cursor, the latest registered accounts go first
+func (blog *Blog) GetTrustedPage(ctx *rpc.Context) {
+	cursor, limit, ok := readPageParams(ctx, 0)
+	if !ok {
+		return
+	}
+
+	page, err := blog.doGetTrustedPage(cursor, limit)
+	if err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	ctx.WriteResult(page)
+}
+
+func (blog *Blog) doGetTrustedPage(cursor string, limit uint32) (*ProfilesPage, *rpc.Error) {
+	after, keys, rpcErr := pageParams(cursor, 1, limit)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	var rows []*profileCursorRow
+	err := blog.DB.Read.Select(&rows,
+		`SELECT account, display_name, location, bio, avatar_url, cover_url, created_at, created_at AS listed_at
+			    FROM profiles
+				WHERE is_trusted = TRUE AND ($1::TIMESTAMP IS NULL OR (created_at, account) < ($1, $2))
+				ORDER BY created_at DESC, account DESC
+				LIMIT $3`, after, keys[0], limit)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	return toProfilesPage(rows, limit), nil
+}
+
+func (blog *Blog) makeAndUploadAvatars(tx *broadcast.Tx, media db.Media) *rpc.Error {
 	file, err := downloadFile(media.Url)
 
 	if err != nil {
@@ -435,7 +501,7 @@ func (blog *Blog) makeAndUploadAvatars(media db.Media) *rpc.Error {
 		img.AddThumb(strconv.Itoa(threshold), threshold, threshold)
 	}
 
-	_, err = blog.uploadThumbnails(media.Account, media.ID, img)
+	_, err = blog.uploadThumbnails(tx, media.Account, media.ID, img)
 	if err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
 	}
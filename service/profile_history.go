@@ -0,0 +1,166 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
+)
+
+// languageRegexp matches ISO 639-1 language codes with the optional region, e.g. en or pt-BR
+var languageRegexp = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+
+// socialNetworks are the hosts allowed for the links of each social network
+var socialNetworks = map[string][]string{
+	"twitter":   {"twitter.com", "x.com"},
+	"telegram":  {"t.me", "telegram.me"},
+	"youtube":   {"youtube.com", "youtu.be"},
+	"facebook":  {"facebook.com", "fb.com"},
+	"instagram": {"instagram.com"},
+}
+
+// ProfileHistoryQuery is the filter of the account_api.get_profile_history
+type ProfileHistoryQuery struct {
+	Account string `json:"account"`
+	// Before is the id of the last entry of the previous page
+	Before int64 `json:"before"`
+	Limit  int   `json:"limit"`
+}
+
+// parseHTTPURL parses the absolute http or https url
+func parseHTTPURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("%s is not an http url", raw)
+	}
+	return u, nil
+}
+
+func isSocialNetworkHost(network, host string) bool {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	host = strings.TrimPrefix(host, "m.")
+	for _, h := range socialNetworks[network] {
+		if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+// toProfileExtra validates the extra profile fields of the operation
+func (blog *Blog) toProfileExtra(tx *sqlx.Tx, account string, in *types.ProfileExtra) (db.ProfileExtra, *rpc.Error) {
+	extra := db.ProfileExtra{
+		Website:    in.Website,
+		Links:      make(db.SocialLinks),
+		Language:   in.Language,
+		PinnedPost: in.PinnedPost,
+	}
+
+	if extra.Website != "" {
+		if _, err := parseHTTPURL(extra.Website); err != nil {
+			return extra, NewError(rpc.InvalidParameterCode, "invalid website")
+		}
+	}
+
+	links := [][2]string{
+		{"twitter", in.Twitter},
+		{"telegram", in.Telegram},
+		{"youtube", in.YouTube},
+		{"facebook", in.Facebook},
+		{"instagram", in.Instagram},
+	}
+	for _, l := range links {
+		network, link := l[0], l[1]
+		if link == "" {
+			continue
+		}
+		u, err := parseHTTPURL(link)
+		if err != nil || !isSocialNetworkHost(network, u.Hostname()) {
+			return extra, NewError(rpc.InvalidParameterCode, fmt.Sprintf("invalid %s link", network))
+		}
+		extra.Links[network] = link
+	}
+
+	if extra.Language != "" && !languageRegexp.MatchString(extra.Language) {
+		return extra, NewError(rpc.InvalidParameterCode, "invalid language")
+	}
+
+	if extra.PinnedPost != "" {
+		var exists bool
+		err := tx.Get(&exists, `SELECT EXISTS(SELECT * FROM comments WHERE author = $1 AND permlink = $2)`,
+			account, extra.PinnedPost)
+		if err != nil {
+			return extra, WrapError(rpc.InternalErrorCode, err)
+		}
+		if !exists {
+			return extra, NewError(rpc.PostNotFoundCode, fmt.Sprintf("%s/%s not found", account, extra.PinnedPost))
+		}
+	}
+
+	return extra, nil
+}
+
+func (blog *Blog) addProfileHistory(tx *sqlx.Tx, entry db.ProfileHistoryEntry) *rpc.Error {
+	_, err := tx.NamedExec(`
+		INSERT INTO profile_history (account, display_name, location, bio, avatar_url, cover_url,
+		                             website, social_links, language, pinned_post, changed_at)
+		VALUES (:account, :display_name, :location, :bio, :avatar_url, :cover_url,
+		        :website, :social_links, :language, :pinned_post, :changed_at)`,
+		entry)
+	if err != nil {
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+	return nil
+}
+
+// GetProfileHistory returns the replaced versions of the profile starting from the latest one, moderators only
+func (blog *Blog) GetProfileHistory(ctx *rpc.Context, account string, params []*json.RawMessage) {
+	if err := blog.checkPermission(blog.RolesStorage, account, db.RoleModerator, ""); err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	var query ProfileHistoryQuery
+	if err := getParam(params, 0, &query); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	entries, err := blog.doGetProfileHistory(query)
+	if err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	ctx.WriteResult(entries)
+}
+
+func (blog *Blog) doGetProfileHistory(query ProfileHistoryQuery) ([]*ProfileHistoryEntry, *rpc.Error) {
+	if query.Limit <= 0 || query.Limit > maxLargePageSize {
+		return nil, NewError(rpc.InvalidParameterCode, "invalid limit")
+	}
+
+	var entries []*db.ProfileHistoryEntry
+	err := blog.DB.Read.Select(&entries, `
+		SELECT id, account, display_name, location, bio, avatar_url, cover_url,
+		       website, social_links, language, pinned_post, changed_at
+		FROM profile_history
+		WHERE account = $1 AND ($2::BIGINT = 0 OR id < $2)
+		ORDER BY id DESC
+		LIMIT $3`,
+		query.Account, query.Before, query.Limit)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	return toAPIProfileHistoryEntries(entries), nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
+	. "gitlab.scorum.com/blog/core/domain"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ProfileSearchResult is the page of the account_api.search_profiles,
+// NextCursor is empty on the last page
+type ProfileSearchResult struct {
+	Items      []*ExtendedProfile `json:"items"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// searchCursor is the rank and the account of the last profile of the page
+type searchCursor struct {
+	Rank    string
+	Account string
+}
+
+func (c searchCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.Rank + "," + c.Account))
+}
+
+func decodeSearchCursor(s string) (*searchCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.SplitN(string(b), ",", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+
+	// the rank is compared as NUMERIC, it is validated here so the query does not fail on the cast
+	rank, err := decimal.NewFromString(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	return &searchCursor{Rank: rank.String(), Account: parts[1]}, nil
+}
+
+func (blog *Blog) SearchProfiles(ctx *rpc.Context) {
+	var query string
+	if err := ctx.Param(0, &query); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	var domain string
+	if err := ctx.Param(1, &domain); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	var cursor string
+	if err := ctx.Param(2, &cursor); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	var limit uint32
+	if err := ctx.Param(3, &limit); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	result, err := blog.doSearchProfiles(query, domain, cursor, limit)
+	if err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	ctx.WriteResult(result)
+}
+
+// doSearchProfiles finds the profiles by the account, display name, bio and location.
+// The profiles are ranked by the match quality boosted by the followers count, the suspended accounts are skipped,
+// non empty domain limits the search to the authors who posted to the domain
+func (blog *Blog) doSearchProfiles(query, domain, cursor string, limit uint32) (*ProfileSearchResult, *rpc.Error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, NewError(rpc.InvalidParameterCode, "query is empty")
+	}
+
+	if limit == 0 || limit > maxLargePageSize {
+		return nil, NewError(rpc.InvalidParameterCode, "invalid limit")
+	}
+
+	if domain != "" && !IsValidDomain(domain) {
+		return nil, NewError(rpc.InvalidParameterCode, fmt.Sprintf("%s is not a valid domain", domain))
+	}
+
+	after, err := decodeSearchCursor(cursor)
+	if err != nil {
+		return nil, NewError(rpc.InvalidParameterCode, "invalid cursor")
+	}
+
+	var afterRank sql.NullString
+	var afterAccount string
+	if after != nil {
+		afterRank = sql.NullString{String: after.Rank, Valid: true}
+		afterAccount = after.Account
+	}
+
+	var profiles []*struct {
+		db.ExtendedProfile
+		Rank string `db:"rank"`
+	}
+	err = blog.DB.Read.Select(&profiles, `
+		WITH matches AS (
+			SELECT p.account, p.display_name, p.location, p.bio, p.avatar_url, p.cover_url, p.created_at,
+				p.website, p.social_links, p.language, p.pinned_post,
+				GREATEST(
+					similarity(CAST(p.account AS TEXT), $1),
+					similarity(p.display_name, $1),
+					CASE WHEN CAST(p.account AS TEXT) ILIKE $2 OR p.display_name ILIKE $2 THEN 1 ELSE 0 END
+				) + ts_rank(to_tsvector('simple', p.bio || ' ' || p.location), plainto_tsquery('simple', $1)) AS score,
+				(SELECT COUNT(*) FROM followers WHERE follow_account = p.account) followers_count,
+				(SELECT COUNT(*) FROM followers WHERE account = p.account) following_count
+			FROM profiles p
+			WHERE (CAST(p.account AS TEXT) % $1 OR p.display_name % $1
+					OR CAST(p.account AS TEXT) ILIKE $2 OR p.display_name ILIKE $2
+					OR to_tsvector('simple', p.bio || ' ' || p.location) @@ plainto_tsquery('simple', $1))
+				AND NOT EXISTS(SELECT * FROM active_suspensions s WHERE s.account = p.account)
+				AND ($3::TEXT = '' OR EXISTS(SELECT * FROM comments c WHERE c.author = p.account AND CAST(c.domain AS TEXT) = $3))
+		), ranked AS (
+			SELECT m.*, ROUND(CAST(m.score * (1 + LN(1 + m.followers_count) / 10) AS NUMERIC), 6) AS rank
+			FROM matches m
+		)
+		SELECT account, display_name, location, bio, avatar_url, cover_url, created_at,
+			website, social_links, language, pinned_post,
+			followers_count, following_count, CAST(rank AS TEXT) AS rank
+		FROM ranked
+		WHERE $4::NUMERIC IS NULL OR (rank, account) < ($4::NUMERIC, $5)
+		ORDER BY rank DESC, account DESC
+		LIMIT $6`,
+		query, likeEscaper.Replace(query)+"%", domain, afterRank, afterAccount, limit)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	result := ProfileSearchResult{Items: make([]*ExtendedProfile, len(profiles))}
+	for idx, p := range profiles {
+		result.Items[idx] = toAPIExtendedProfile(&p.ExtendedProfile)
+	}
+	if len(profiles) == int(limit) {
+		last := profiles[len(profiles)-1]
+		result.NextCursor = searchCursor{Rank: last.Rank, Account: last.Account}.encode()
+	}
+
+	return &result, nil
+}
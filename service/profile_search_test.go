@@ -0,0 +1,117 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/rpc"
+	. "gitlab.scorum.com/blog/core/domain"
+)
+
+func TestDecodeSearchCursor(t *testing.T) {
+	cursor, err := decodeSearchCursor(searchCursor{Rank: "1.250000", Account: "sheldon"}.encode())
+	require.NoError(t, err)
+	require.Equal(t, &searchCursor{Rank: "1.25", Account: "sheldon"}, cursor)
+
+	for _, rank := range []string{"", "high", "NaN", "1.2; DROP TABLE profiles"} {
+		_, err := decodeSearchCursor(searchCursor{Rank: rank, Account: "sheldon"}.encode())
+		require.Error(t, err, rank)
+	}
+}
+
+func TestBlog_SearchProfiles(t *testing.T) {
+	defer cleanUp(t)
+
+	registerAccount(t, leonarda)
+	registerAccount(t, kristie)
+	registerAccount(t, sheldon)
+
+	_, err := dbWrite.Exec(`UPDATE profiles SET bio = 'football fan', location = 'Kyiv' WHERE account = $1`, kristie)
+	require.NoError(t, err)
+	_, err = dbWrite.Exec(`UPDATE profiles SET bio = 'football blogger' WHERE account = $1`, sheldon)
+	require.NoError(t, err)
+
+	accounts := func(result *ProfileSearchResult) []string {
+		out := make([]string, len(result.Items))
+		for idx, p := range result.Items {
+			out[idx] = p.Account
+		}
+		return out
+	}
+
+	t.Run("account_prefix", func(t *testing.T) {
+		result, err := handler.doSearchProfiles("kris", "", "", 10)
+		require.Nil(t, err)
+		require.Equal(t, []string{kristie}, accounts(result))
+		require.Empty(t, result.NextCursor)
+	})
+
+	t.Run("bio_and_location", func(t *testing.T) {
+		result, err := handler.doSearchProfiles("kyiv", "", "", 10)
+		require.Nil(t, err)
+		require.Equal(t, []string{kristie}, accounts(result))
+	})
+
+	t.Run("ranked_by_followers", func(t *testing.T) {
+		require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: leonarda, Follow: sheldon}))
+
+		result, err := handler.doSearchProfiles("football", "", "", 10)
+		require.Nil(t, err)
+		require.Equal(t, []string{sheldon, kristie}, accounts(result))
+		require.EqualValues(t, 1, result.Items[0].FollowersCount)
+	})
+
+	t.Run("cursor", func(t *testing.T) {
+		first, err := handler.doSearchProfiles("football", "", "", 1)
+		require.Nil(t, err)
+		require.Equal(t, []string{sheldon}, accounts(first))
+		require.NotEmpty(t, first.NextCursor)
+
+		second, err := handler.doSearchProfiles("football", "", first.NextCursor, 1)
+		require.Nil(t, err)
+		require.Equal(t, []string{kristie}, accounts(second))
+
+		_, err = handler.doSearchProfiles("football", "", "not a cursor", 1)
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+
+		_, err = handler.doSearchProfiles("football", "", searchCursor{Rank: "high", Account: sheldon}.encode(), 1)
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+	})
+
+	t.Run("domain", func(t *testing.T) {
+		insertPost(t, kristie, "match-review", Domain("com"))
+
+		result, err := handler.doSearchProfiles("football", "com", "", 10)
+		require.Nil(t, err)
+		require.Equal(t, []string{kristie}, accounts(result))
+
+		_, err = handler.doSearchProfiles("football", "unknown", "", 10)
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+	})
+
+	t.Run("suspended", func(t *testing.T) {
+		require.Nil(t, apply(t, handler.SuspendAccountAdmin, &types.SuspendAccountAdminOperation{
+			Account:     leonarda,
+			BlogAccount: sheldon,
+			Reason:      "spam",
+		}))
+
+		result, err := handler.doSearchProfiles("football", "", "", 10)
+		require.Nil(t, err)
+		require.Equal(t, []string{kristie}, accounts(result))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := handler.doSearchProfiles(" ", "", "", 10)
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+
+		_, err = handler.doSearchProfiles("football", "", "", 0)
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+	})
+}
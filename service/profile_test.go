@@ -12,6 +12,8 @@ import (
 	"gitlab.scorum.com/blog/api/common"
 	"gitlab.scorum.com/blog/api/db"
 	"gitlab.scorum.com/blog/api/push"
+	"gitlab.scorum.com/blog/api/rpc"
+	. "gitlab.scorum.com/blog/core/domain"
 )
 
 func TestBlog_UpdateProfile(t *testing.T) {
@@ -20,7 +22,7 @@ func TestBlog_UpdateProfile(t *testing.T) {
 	registerAccount(t, leonarda)
 
 	// avatar
-	err := handler.UploadMedia(&types.UploadMediaOperation{
+	err := apply(t, handler.UploadMedia, &types.UploadMediaOperation{
 		Account:     leonarda,
 		Media:       base64PNG1200x700,
 		ID:          "avatar",
@@ -32,7 +34,7 @@ func TestBlog_UpdateProfile(t *testing.T) {
 	require.Nil(t, err)
 
 	// cover
-	err = handler.UploadMedia(&types.UploadMediaOperation{
+	err = apply(t, handler.UploadMedia, &types.UploadMediaOperation{
 		Account:     leonarda,
 		Media:       base64PNG1200x700,
 		ID:          "cover",
@@ -53,7 +55,7 @@ func TestBlog_UpdateProfile(t *testing.T) {
 		CoverUrl:    cover.Url,
 	}
 
-	require.Nil(t, handler.UpdateProfile(upo))
+	require.Nil(t, apply(t, handler.UpdateProfile, upo))
 
 	// assert
 	profile, err := handler.doGetProfile(leonarda)
@@ -130,7 +132,7 @@ func TestBlog_UpdateProfile_ValidationTest(t *testing.T) {
 	t.Run("media_content_type_validation", func(t *testing.T) {
 		registerAccount(t, leonarda)
 
-		err := handler.UploadMedia(&types.UploadMediaOperation{
+		err := apply(t, handler.UploadMedia, &types.UploadMediaOperation{
 			Account:     leonarda,
 			Media:       base64Gif400x300,
 			ID:          "avatar",
@@ -145,18 +147,124 @@ func TestBlog_UpdateProfile_ValidationTest(t *testing.T) {
 			cop := op
 			cop.AvatarUrl = avatar.Url
 			require.NoError(t, validate.Struct(cop))
-			require.NotNil(t, handler.UpdateProfile(&cop))
+			require.NotNil(t, apply(t, handler.UpdateProfile, &cop))
 		})
 
 		t.Run("invalid_cover_content_type", func(t *testing.T) {
 			cop := op
 			cop.CoverUrl = avatar.Url
 			require.NoError(t, validate.Struct(cop))
-			require.NotNil(t, handler.UpdateProfile(&cop))
+			require.NotNil(t, apply(t, handler.UpdateProfile, &cop))
 		})
 	})
 }
 
+func TestBlog_UpdateProfile_Extra(t *testing.T) {
+	defer cleanUp(t)
+
+	registerAccount(t, leonarda)
+	registerAccount(t, kristie)
+	insertPost(t, leonarda, "pinned", Domain("com"))
+
+	upo := &types.UpdateProfileOperation{
+		Account:     leonarda,
+		DisplayName: "zebra",
+		Bio:         "bio",
+		Extra: &types.ProfileExtra{
+			Version:    1,
+			Website:    "https://scorum.com",
+			Twitter:    "https://twitter.com/leonarda",
+			Telegram:   "https://t.me/leonarda",
+			Language:   "pt-BR",
+			PinnedPost: "pinned",
+		},
+	}
+
+	t.Run("validation", func(t *testing.T) {
+		require.NoError(t, validate.Struct(upo))
+
+		cop := *upo
+		extra := *upo.Extra
+		extra.Version = 2
+		cop.Extra = &extra
+		require.Error(t, validate.Struct(cop))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, modify := range map[string]func(extra *types.ProfileExtra){
+			"website":  func(extra *types.ProfileExtra) { extra.Website = "ftp://scorum.com" },
+			"twitter":  func(extra *types.ProfileExtra) { extra.Twitter = "https://t.me/leonarda" },
+			"language": func(extra *types.ProfileExtra) { extra.Language = "english" },
+		} {
+			t.Run(name, func(t *testing.T) {
+				cop := *upo
+				extra := *upo.Extra
+				modify(&extra)
+				cop.Extra = &extra
+
+				err := apply(t, handler.UpdateProfile, &cop)
+				require.NotNil(t, err)
+				require.Equal(t, rpc.InvalidParameterCode, err.Code)
+			})
+		}
+
+		cop := *upo
+		extra := *upo.Extra
+		extra.PinnedPost = "missing"
+		cop.Extra = &extra
+		err := apply(t, handler.UpdateProfile, &cop)
+		require.NotNil(t, err)
+		require.Equal(t, rpc.PostNotFoundCode, err.Code)
+	})
+
+	t.Run("updated", func(t *testing.T) {
+		require.Nil(t, apply(t, handler.UpdateProfile, upo))
+
+		profile, err := handler.doGetProfile(leonarda)
+		require.Nil(t, err)
+		require.Equal(t, "https://scorum.com", profile.Website)
+		require.Equal(t, map[string]string{
+			"twitter":  "https://twitter.com/leonarda",
+			"telegram": "https://t.me/leonarda",
+		}, profile.Links)
+		require.Equal(t, "pt-BR", profile.Language)
+		require.Equal(t, "pinned", profile.PinnedPost)
+	})
+
+	t.Run("extra_omitted", func(t *testing.T) {
+		cop := *upo
+		cop.Extra = nil
+		cop.Bio = "new bio"
+		require.Nil(t, apply(t, handler.UpdateProfile, &cop))
+
+		profile, err := handler.doGetProfile(leonarda)
+		require.Nil(t, err)
+		require.Equal(t, "new bio", profile.Bio)
+		require.Equal(t, "https://scorum.com", profile.Website)
+	})
+
+	t.Run("history", func(t *testing.T) {
+		// unchanged profile is not recorded
+		cop := *upo
+		cop.Extra = nil
+		cop.Bio = "new bio"
+		require.Nil(t, apply(t, handler.UpdateProfile, &cop))
+
+		entries, err := handler.doGetProfileHistory(ProfileHistoryQuery{Account: leonarda, Limit: 10})
+		require.Nil(t, err)
+		require.Len(t, entries, 2)
+		require.Equal(t, "bio", entries[0].Bio)
+		require.Equal(t, "https://scorum.com", entries[0].Website)
+		require.Equal(t, leonarda, entries[1].DisplayName)
+		require.Empty(t, entries[1].Website)
+
+		entries, err = handler.doGetProfileHistory(ProfileHistoryQuery{Account: leonarda, Before: entries[0].ID, Limit: 10})
+		require.Nil(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, leonarda, entries[0].DisplayName)
+	})
+}
+
 func TestGetProfile(t *testing.T) {
 	defer cleanUp(t)
 
@@ -170,7 +278,7 @@ func TestGetProfile(t *testing.T) {
 		AvatarUrl:   "",
 		CoverUrl:    "",
 	}
-	require.Nil(t, handler.UpdateProfile(upo))
+	require.Nil(t, apply(t, handler.UpdateProfile, upo))
 
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -180,12 +288,12 @@ func TestGetProfile(t *testing.T) {
 	handler.Notifier = notifier
 
 	// follow to update counters
-	require.Nil(t, handler.Follow(&types.FollowOperation{
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{
 		Account: leonarda,
 		Follow:  kristie,
 	}))
 
-	require.Nil(t, handler.Follow(&types.FollowOperation{
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{
 		Account: kristie,
 		Follow:  leonarda,
 	}))
@@ -279,7 +387,7 @@ func TestUpdateProfileSettings(t *testing.T) {
 		EnableEmailUnseenNotifications: false,
 	}
 
-	err = handler.doUpsertProfileSettings(&newSettings)
+	err = handler.doUpsertProfileSettings(dbWrite, &newSettings)
 	require.Nil(t, err)
 
 	settings, err = handler.doGetProfileSettings(leonarda)
@@ -288,6 +396,113 @@ func TestUpdateProfileSettings(t *testing.T) {
 	require.False(t, settings.EnableEmailUnseenNotifications)
 }
 
+func TestUpdateProfileSettings_NotificationPreferences(t *testing.T) {
+	defer cleanUp(t)
+
+	registerAccount(t, leonarda)
+	addProfileSettings(t, leonarda)
+
+	settings, err := handler.doGetProfileSettings(leonarda)
+	require.Nil(t, err)
+	require.Len(t, settings.NotificationPreferences, len(db.NotificationTypes)*len(db.NotificationChannels))
+	for _, p := range settings.NotificationPreferences {
+		require.True(t, p.Enabled)
+	}
+	require.Nil(t, settings.QuietHours)
+
+	enabled := func(settings *ProfileSettings, nt db.NotificationType, channel db.NotificationChannel) bool {
+		for _, p := range settings.NotificationPreferences {
+			if p.Type == string(nt) && p.Channel == string(channel) {
+				return p.Enabled
+			}
+		}
+		t.Fatalf("%s %s preference not found", nt, channel)
+		return false
+	}
+
+	require.Nil(t, apply(t, handler.UpdateProfileSettings, &types.UpdateProfileSettingsOperation{
+		Account:                        leonarda,
+		EnableEmailUnseenNotifications: true,
+		NotificationPreferences: []types.NotificationPreference{
+			{Type: string(db.PostVotedNotificationType), Channel: string(db.PushNotificationChannel)},
+			{Type: string(db.PostFlaggedNotificationType), Channel: string(db.InAppNotificationChannel)},
+		},
+		QuietHours: &types.QuietHours{Start: "22:30", End: "07:00", TimeZone: "Europe/Kiev"},
+	}))
+
+	settings, err = handler.doGetProfileSettings(leonarda)
+	require.Nil(t, err)
+	require.False(t, enabled(settings, db.PostVotedNotificationType, db.PushNotificationChannel))
+	require.False(t, enabled(settings, db.PostFlaggedNotificationType, db.InAppNotificationChannel))
+	require.True(t, enabled(settings, db.PostRepliedNotificationType, db.PushNotificationChannel))
+	require.Equal(t, &QuietHours{Start: "22:30", End: "07:00", TimeZone: "Europe/Kiev"}, settings.QuietHours)
+
+	t.Run("legacy_client_keeps_preferences", func(t *testing.T) {
+		require.Nil(t, apply(t, handler.UpdateProfileSettings, &types.UpdateProfileSettingsOperation{
+			Account:                        leonarda,
+			EnableEmailUnseenNotifications: false,
+		}))
+
+		settings, err := handler.doGetProfileSettings(leonarda)
+		require.Nil(t, err)
+		require.False(t, settings.EnableEmailUnseenNotifications)
+		require.False(t, enabled(settings, db.PostVotedNotificationType, db.PushNotificationChannel))
+		require.NotNil(t, settings.QuietHours)
+	})
+
+	t.Run("remove_quiet_hours", func(t *testing.T) {
+		require.Nil(t, apply(t, handler.UpdateProfileSettings, &types.UpdateProfileSettingsOperation{
+			Account:    leonarda,
+			QuietHours: &types.QuietHours{},
+		}))
+
+		settings, err := handler.doGetProfileSettings(leonarda)
+		require.Nil(t, err)
+		require.Nil(t, settings.QuietHours)
+	})
+
+	t.Run("every_preference", func(t *testing.T) {
+		var preferences []types.NotificationPreference
+		for _, nt := range db.NotificationTypes {
+			for _, channel := range db.NotificationChannels {
+				preferences = append(preferences, types.NotificationPreference{Type: string(nt), Channel: string(channel)})
+			}
+		}
+		require.Nil(t, apply(t, handler.UpdateProfileSettings, &types.UpdateProfileSettingsOperation{
+			Account:                 leonarda,
+			NotificationPreferences: preferences,
+		}))
+
+		settings, err := handler.doGetProfileSettings(leonarda)
+		require.Nil(t, err)
+		for _, nt := range db.NotificationTypes {
+			for _, channel := range db.NotificationChannels {
+				require.False(t, enabled(settings, nt, channel))
+			}
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tooMany := make([]types.NotificationPreference, len(db.NotificationTypes)*len(db.NotificationChannels)+1)
+		for idx := range tooMany {
+			tooMany[idx] = types.NotificationPreference{Type: "post_voted", Channel: "push"}
+		}
+
+		ops := []*types.UpdateProfileSettingsOperation{
+			{Account: leonarda, NotificationPreferences: tooMany},
+			{Account: leonarda, NotificationPreferences: []types.NotificationPreference{{Type: "unknown", Channel: "push"}}},
+			{Account: leonarda, NotificationPreferences: []types.NotificationPreference{{Type: "post_voted", Channel: "sms"}}},
+			{Account: leonarda, QuietHours: &types.QuietHours{Start: "25:00", End: "07:00"}},
+			{Account: leonarda, QuietHours: &types.QuietHours{Start: "22:00", End: "07:00", TimeZone: "Mars/Olympus"}},
+		}
+		for _, op := range ops {
+			rpcErr := apply(t, handler.UpdateProfileSettings, op)
+			require.NotNil(t, rpcErr)
+			require.Equal(t, rpc.InvalidParameterCode, rpcErr.Code)
+		}
+	})
+}
+
 func TestBlog_SetAccountTrustedAdmin(t *testing.T) {
 	defer cleanUp(t)
 	registerAccount(t, leonarda)
@@ -310,7 +525,7 @@ func TestBlog_IsAccountTrusted(t *testing.T) {
 }
 
 func setTrusted(t *testing.T, account string) {
-	require.Nil(t, handler.SetAccountTrustedAdmin(&types.SetAccountTrustedAdminOperation{
+	require.Nil(t, apply(t, handler.SetAccountTrustedAdmin, &types.SetAccountTrustedAdminOperation{
 		Account:     account,
 		BlogAccount: account,
 		IsTrusted:   true,
@@ -335,7 +550,7 @@ func TestBlog_GetTrusted(t *testing.T) {
 
 func TestUnsubscribeEndpoint(t *testing.T) {
 	defer cleanUp(t)
-	require.Nil(t, handler.Register(&types.RegisterOperation{"cali4888"}))
+	require.Nil(t, apply(t, handler.Register, &types.RegisterOperation{"cali4888"}))
 
 	r := httptest.NewRequest(
 		"POST",
@@ -1,14 +1,15 @@
 package service
 
 import (
+	"gitlab.scorum.com/blog/api/broadcast"
 	"gitlab.scorum.com/blog/api/broadcast/types"
 	"gitlab.scorum.com/blog/api/rpc"
 )
 
-func (blog *Blog) RegisterPushToken(op types.Operation) *rpc.Error {
+func (blog *Blog) RegisterPushToken(tx *broadcast.Tx, op types.Operation) *rpc.Error {
 	in := op.(*types.RegisterPushTokenOperation)
 
-	err := blog.PushRegistrationStorage.Add(in.Account, in.Token)
+	err := blog.PushRegistrationStorage.InTx(tx.Tx).Add(in.Account, in.Token)
 	if err != nil {
 		return WrapError(rpc.InternalErrorCode, err)
 	}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gitlab.scorum.com/blog/api/broadcast"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
+	"gitlab.scorum.com/blog/api/utils/postgres"
+)
+
+// checkPermission returns the access denied error unless the account is granted the role for the domain
+// or the superadmin role, the admin account of the config is always the superadmin
+func (blog *Blog) checkPermission(roles *db.RolesStorage, account string, role db.Role, domain string) *rpc.Error {
+	if account == blog.Config.Admin {
+		return nil
+	}
+
+	if !role.IsScoped() {
+		domain = ""
+	}
+
+	ok, err := roles.HasRole(account, role, domain)
+	if err != nil {
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+
+	if !ok {
+		return NewError(rpc.AccessDeniedCode, "access denied")
+	}
+	return nil
+}
+
+// validateRole checks that the domain is set only for the scoped roles
+func validateRole(role db.Role, domain string) *rpc.Error {
+	if !role.IsValid() {
+		return NewError(rpc.InvalidParameterCode, fmt.Sprintf("role %s is invalid", role))
+	}
+
+	if role.IsScoped() && domain == "" {
+		return NewError(rpc.InvalidParameterCode, fmt.Sprintf("domain is required for the %s role", role))
+	}
+
+	if !role.IsScoped() && domain != "" {
+		return NewError(rpc.InvalidParameterCode, fmt.Sprintf("%s role is not scoped by domain", role))
+	}
+	return nil
+}
+
+func (blog *Blog) GrantRole(tx *broadcast.Tx, op types.Operation) *rpc.Error {
+	in := op.(*types.GrantRoleOperation)
+
+	roles := blog.RolesStorage.InTx(tx.Tx)
+	if err := blog.checkPermission(roles, in.Account, db.RoleSuperadmin, ""); err != nil {
+		return err
+	}
+
+	role := db.Role(in.Role)
+	if err := validateRole(role, in.Domain); err != nil {
+		return err
+	}
+
+	err := roles.Grant(db.RoleGrant{
+		Account:   in.BlogAccount,
+		Role:      role,
+		Domain:    in.Domain,
+		GrantedBy: in.Account,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		if isErr, _ := postgres.IsForeignKeyViolationError(err); isErr {
+			return NewError(rpc.ProfileNotFoundCode, fmt.Sprintf("%s not found", in.BlogAccount))
+		}
+		if isInvalidDomainValueErr(err) {
+			return NewError(rpc.InvalidParameterCode, "domain is invalid")
+		}
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+
+	return nil
+}
+
+func (blog *Blog) RevokeRole(tx *broadcast.Tx, op types.Operation) *rpc.Error {
+	in := op.(*types.RevokeRoleOperation)
+
+	roles := blog.RolesStorage.InTx(tx.Tx)
+	if err := blog.checkPermission(roles, in.Account, db.RoleSuperadmin, ""); err != nil {
+		return err
+	}
+
+	role := db.Role(in.Role)
+	if err := validateRole(role, in.Domain); err != nil {
+		return err
+	}
+
+	ok, err := roles.Revoke(in.BlogAccount, role, in.Domain)
+	if err != nil {
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+
+	if !ok {
+		return NewError(rpc.RoleNotFoundCode, "role not found")
+	}
+	return nil
+}
+
+// GetRoles returns roles granted to the account, roles of all the accounts if the account is empty, superadmin only
+func (blog *Blog) GetRoles(ctx *rpc.Context, signer string, params []*json.RawMessage) {
+	if err := blog.checkPermission(blog.RolesStorage, signer, db.RoleSuperadmin, ""); err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	var account string
+	if err := getParam(params, 0, &account); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	grants, err := blog.RolesStorage.GetRoles(account)
+	if err != nil {
+		ctx.WriteError(rpc.InternalErrorCode, err.Error())
+		return
+	}
+
+	ctx.WriteResult(toAPIRoleGrants(grants))
+}
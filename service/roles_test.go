@@ -0,0 +1,124 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
+)
+
+func TestBlog_GrantRole(t *testing.T) {
+	defer cleanUp(t)
+
+	registerAccount(t, leonarda)
+	registerAccount(t, kristie)
+	registerAccount(t, sheldon)
+
+	grant := func(account, blogAccount string, role db.Role, domain string) *rpc.Error {
+		return apply(t, handler.GrantRole, &types.GrantRoleOperation{
+			Account:     account,
+			BlogAccount: blogAccount,
+			Role:        string(role),
+			Domain:      domain,
+		})
+	}
+
+	addCategory := func(account, domain string) *rpc.Error {
+		return apply(t, handler.AddCategoryAdmin, &types.AddCategoryAdminOperation{
+			Account:         account,
+			Domain:          domain,
+			Label:           "soccer",
+			LocalizationKey: domain + ".soccer",
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		err := grant(leonarda, kristie, "owner", "")
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+
+		err = grant(leonarda, kristie, db.RoleCategoryEditor, "")
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+
+		err = grant(leonarda, kristie, db.RoleModerator, "me")
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+
+		err = grant(leonarda, kristie, db.RoleCategoryEditor, "not_domain")
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+	})
+
+	t.Run("category_editor", func(t *testing.T) {
+		err := addCategory(kristie, "me")
+		require.NotNil(t, err)
+		require.Equal(t, rpc.AccessDeniedCode, err.Code)
+
+		require.Nil(t, grant(leonarda, kristie, db.RoleCategoryEditor, "me"))
+		require.Nil(t, addCategory(kristie, "me"))
+
+		err = addCategory(kristie, "com")
+		require.NotNil(t, err)
+		require.Equal(t, rpc.AccessDeniedCode, err.Code)
+	})
+
+	t.Run("not_superadmin", func(t *testing.T) {
+		err := grant(kristie, sheldon, db.RoleModerator, "")
+		require.NotNil(t, err)
+		require.Equal(t, rpc.AccessDeniedCode, err.Code)
+
+		require.Nil(t, grant(leonarda, sheldon, db.RoleSuperadmin, ""))
+		require.Nil(t, grant(sheldon, kristie, db.RoleModerator, ""))
+		require.Nil(t, addCategory(sheldon, "com"))
+	})
+
+	t.Run("get_roles", func(t *testing.T) {
+		grants, err := handler.RolesStorage.GetRoles(kristie)
+		require.NoError(t, err)
+		require.Len(t, grants, 2)
+		require.Equal(t, db.RoleCategoryEditor, grants[0].Role)
+		require.Equal(t, "me", grants[0].Domain)
+		require.Equal(t, leonarda, grants[0].GrantedBy)
+		require.Equal(t, db.RoleModerator, grants[1].Role)
+
+		grants, err = handler.RolesStorage.GetRoles("")
+		require.NoError(t, err)
+		require.Len(t, grants, 3)
+	})
+}
+
+func TestBlog_RevokeRole(t *testing.T) {
+	defer cleanUp(t)
+
+	registerAccount(t, leonarda)
+	registerAccount(t, kristie)
+
+	op := &types.RevokeRoleOperation{
+		Account:     leonarda,
+		BlogAccount: kristie,
+		Role:        string(db.RoleTrustManager),
+	}
+
+	err := apply(t, handler.RevokeRole, op)
+	require.NotNil(t, err)
+	require.Equal(t, rpc.RoleNotFoundCode, err.Code)
+
+	require.Nil(t, apply(t, handler.GrantRole, &types.GrantRoleOperation{
+		Account:     leonarda,
+		BlogAccount: kristie,
+		Role:        string(db.RoleTrustManager),
+	}))
+	setTrusted(t, kristie)
+
+	require.Nil(t, apply(t, handler.RevokeRole, op))
+
+	err = apply(t, handler.SetAccountTrustedAdmin, &types.SetAccountTrustedAdminOperation{
+		Account:     kristie,
+		BlogAccount: kristie,
+	})
+	require.NotNil(t, err)
+	require.Equal(t, rpc.AccessDeniedCode, err.Code)
+}
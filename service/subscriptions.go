@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+	"gitlab.scorum.com/blog/api/rpc"
+)
+
+const notificationSubject = "notification"
+
+// notificationInserted is a payload of the db.NotificationsChannel
+type notificationInserted struct {
+	ID      uuid.UUID `json:"id"`
+	Account string    `json:"account"`
+}
+
+func (blog *Blog) SubscribeNotifications(ctx *rpc.Context, account string, _ []*json.RawMessage) {
+	if ctx.Session == nil {
+		ctx.WriteError(rpc.InvalidRequestCode, "subscription is available over websocket only")
+		return
+	}
+
+	blog.Subscriptions.Subscribe(account, ctx.Session)
+	ctx.WriteResult(nil)
+}
+
+func (blog *Blog) UnsubscribeNotifications(ctx *rpc.Context, account string, _ []*json.RawMessage) {
+	if ctx.Session == nil {
+		ctx.WriteError(rpc.InvalidRequestCode, "subscription is available over websocket only")
+		return
+	}
+
+	blog.Subscriptions.Unsubscribe(account, ctx.Session)
+	ctx.WriteResult(nil)
+}
+
+// ListenNotifications pushes notifications inserted by any API instance or the blockchain monitor
+// to the subscribed websocket sessions. The listener should listen to the db.NotificationsChannel
+func (blog *Blog) ListenNotifications(ctx context.Context, listener *pq.Listener) {
+	for {
+		select {
+		case <-ctx.Done():
+			listener.Close()
+			return
+		case n := <-listener.Notify:
+			if n == nil {
+				// connection has been re-established, notifications sent in between are lost
+				log.Warn("notifications listener reconnected")
+				continue
+			}
+			blog.pushNotification(n.Extra)
+		case <-time.After(time.Minute):
+			go listener.Ping()
+		}
+	}
+}
+
+func (blog *Blog) pushNotification(payload string) {
+	var inserted notificationInserted
+	if err := json.Unmarshal([]byte(payload), &inserted); err != nil {
+		log.WithError(err).Errorf("failed to unmarshal notification payload: %s", payload)
+		return
+	}
+
+	if !blog.Subscriptions.HasSubscribers(inserted.Account) {
+		return
+	}
+
+	notification, err := blog.NotificationStorage.Get(inserted.ID)
+	if err != nil {
+		log.WithError(err).WithField("id", inserted.ID).Error("failed to get notification")
+		return
+	}
+
+	blog.Subscriptions.Publish(inserted.Account, notificationSubject, toAPINotification(*notification))
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gitlab.scorum.com/blog/api/rpc"
+	. "gitlab.scorum.com/blog/core/domain"
+)
+
+// suggestionsRefreshBatch is the number of the accounts read at once by the suggestions refresh
+const suggestionsRefreshBatch = 100
+
+func (blog *Blog) GetSuggestions(ctx *rpc.Context) {
+	var account string
+	if err := ctx.Param(0, &account); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	var domain string
+	if err := ctx.Param(1, &domain); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	var limit uint32
+	if err := ctx.Param(2, &limit); err != nil {
+		ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+		return
+	}
+
+	suggestions, err := blog.doGetSuggestions(account, domain, limit)
+	if err != nil {
+		ctx.WriteError(err.Code, err.Message)
+		return
+	}
+
+	ctx.WriteResult(suggestions)
+}
+
+// doGetSuggestions returns the precomputed suggestions, the trusted accounts are suggested
+// to the accounts registered after the last refresh
+func (blog *Blog) doGetSuggestions(account, domain string, limit uint32) ([]*FollowSuggestion, *rpc.Error) {
+	if limit == 0 || limit > maxLargePageSize {
+		return nil, NewError(rpc.InvalidParameterCode, "invalid limit")
+	}
+
+	if !IsValidDomain(domain) {
+		return nil, NewError(rpc.InvalidParameterCode, fmt.Sprintf("%s is not a valid domain", domain))
+	}
+
+	computed, err := blog.SuggestionsStorage.Computed(account)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	get := blog.SuggestionsStorage.Get
+	if !computed {
+		get = blog.SuggestionsStorage.GetTrusted
+	}
+
+	suggestions, err := get(account, domain, limit)
+	if err != nil {
+		return nil, WrapError(rpc.InternalErrorCode, err)
+	}
+
+	return toAPIFollowSuggestions(suggestions), nil
+}
+
+// RefreshSuggestions recomputes the follow suggestions of every account, each account in its own transaction
+func (blog *Blog) RefreshSuggestions() error {
+	started := time.Now()
+	refreshed := 0
+
+	after := ""
+	for {
+		accounts, err := blog.SuggestionsStorage.Accounts(after, suggestionsRefreshBatch)
+		if err != nil {
+			return err
+		}
+		if len(accounts) == 0 {
+			break
+		}
+
+		for _, account := range accounts {
+			if err := blog.refreshAccountSuggestions(account); err != nil {
+				log.WithError(err).WithField("account", account).Error("failed to refresh follow suggestions")
+				continue
+			}
+			refreshed++
+		}
+		after = accounts[len(accounts)-1]
+	}
+
+	log.WithField("accounts", refreshed).
+		WithField("duration", time.Since(started)).
+		Info("follow suggestions refreshed")
+	return nil
+}
+
+func (blog *Blog) refreshAccountSuggestions(account string) error {
+	tx, err := blog.DB.Write.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = blog.SuggestionsStorage.InTx(tx).Refresh(account, blog.Config.Suggestions.Limit, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
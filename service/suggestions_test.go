@@ -0,0 +1,84 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/push"
+	. "gitlab.scorum.com/blog/core/domain"
+)
+
+func TestBlog_GetSuggestions(t *testing.T) {
+	defer cleanUp(t)
+
+	const penny = "penny"
+
+	registerAccount(t, leonarda)
+	registerAccount(t, kristie)
+	registerAccount(t, sheldon)
+	registerAccount(t, penny)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	notifier := push.NewMockNotifier(mockCtrl)
+	handler.Notifier = notifier
+	notifier.EXPECT().NotifyStartedFollow(gomock.Any(), gomock.Any()).Times(3)
+
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: leonarda, Follow: kristie}))
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: kristie, Follow: sheldon}))
+
+	insertPost(t, kristie, "post 1", DomainCom)
+	insertPost(t, sheldon, "post 1", DomainCom)
+	insertPost(t, penny, "post 1", DomainCom)
+	setTrusted(t, penny)
+
+	handler.Config.Suggestions.Limit = 10
+
+	t.Run("not_computed", func(t *testing.T) {
+		suggestions, err := handler.doGetSuggestions(leonarda, string(DomainCom), 10)
+		require.Nil(t, err)
+		require.Len(t, suggestions, 1)
+		require.Equal(t, penny, suggestions[0].Account)
+		require.Equal(t, []string{"trusted"}, suggestions[0].Reasons)
+	})
+
+	t.Run("not_computed_account_lists", func(t *testing.T) {
+		// muted by the account
+		require.Nil(t, apply(t, handler.Mute, &types.MuteOperation{Account: leonarda, Mute: penny}))
+		suggestions, err := handler.doGetSuggestions(leonarda, string(DomainCom), 10)
+		require.Nil(t, err)
+		require.Empty(t, suggestions)
+		require.Nil(t, apply(t, handler.Unmute, &types.UnmuteOperation{Account: leonarda, Unmute: penny}))
+
+		// blocking the account
+		require.Nil(t, apply(t, handler.Block, &types.BlockOperation{Account: penny, Block: leonarda}))
+		suggestions, err = handler.doGetSuggestions(leonarda, string(DomainCom), 10)
+		require.Nil(t, err)
+		require.Empty(t, suggestions)
+		require.Nil(t, apply(t, handler.Unblock, &types.UnblockOperation{Account: penny, Unblock: leonarda}))
+	})
+
+	require.NoError(t, handler.RefreshSuggestions())
+
+	suggestions, err := handler.doGetSuggestions(leonarda, string(DomainCom), 10)
+	require.Nil(t, err)
+	require.Len(t, suggestions, 2)
+	require.Equal(t, sheldon, suggestions[0].Account)
+	require.Equal(t, []string{"friends"}, suggestions[0].Reasons)
+	require.Equal(t, penny, suggestions[1].Account)
+
+	suggestions, err = handler.doGetSuggestions(leonarda, string(DomainMe), 10)
+	require.Nil(t, err)
+	require.Empty(t, suggestions)
+
+	// the accounts followed since the refresh are left out
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: leonarda, Follow: sheldon}))
+
+	suggestions, err = handler.doGetSuggestions(leonarda, string(DomainCom), 10)
+	require.Nil(t, err)
+	require.Len(t, suggestions, 1)
+	require.Equal(t, penny, suggestions[0].Account)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"gitlab.scorum.com/blog/api/broadcast"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
+	"gitlab.scorum.com/blog/api/utils/postgres"
+)
+
+func (blog *Blog) SuspendAccountAdmin(tx *broadcast.Tx, op types.Operation) *rpc.Error {
+	in := op.(*types.SuspendAccountAdminOperation)
+
+	if err := blog.checkPermission(blog.RolesStorage.InTx(tx.Tx), in.Account, db.RoleModerator, ""); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	suspension := db.Suspension{
+		Account:     in.BlogAccount,
+		Reason:      in.Reason,
+		SuspendedBy: in.Account,
+		CreatedAt:   now,
+	}
+	if in.Duration > 0 {
+		suspension.ExpiresAt = pq.NullTime{Time: now.Add(time.Duration(in.Duration) * time.Second), Valid: true}
+	}
+
+	if err := blog.SuspensionsStorage.InTx(tx.Tx).Suspend(suspension); err != nil {
+		if isErr, _ := postgres.IsForeignKeyViolationError(err); isErr {
+			return NewError(rpc.ProfileNotFoundCode, fmt.Sprintf("%s not found", in.BlogAccount))
+		}
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+
+	return nil
+}
+
+func (blog *Blog) LiftSuspensionAdmin(tx *broadcast.Tx, op types.Operation) *rpc.Error {
+	in := op.(*types.LiftSuspensionAdminOperation)
+
+	if err := blog.checkPermission(blog.RolesStorage.InTx(tx.Tx), in.Account, db.RoleModerator, ""); err != nil {
+		return err
+	}
+
+	lifted, err := blog.SuspensionsStorage.InTx(tx.Tx).Lift(in.BlogAccount)
+	if err != nil {
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+
+	if !lifted {
+		return NewError(rpc.SuspensionNotFoundCode, fmt.Sprintf("%s is not suspended", in.BlogAccount))
+	}
+	return nil
+}
+
+// checkNotSuspended returns the account suspended error if the account has an active suspension
+func (blog *Blog) checkNotSuspended(tx *sqlx.Tx, account string) *rpc.Error {
+	suspension, err := blog.SuspensionsStorage.InTx(tx).Get(account)
+	if err != nil {
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+
+	if suspension == nil {
+		return nil
+	}
+
+	message := fmt.Sprintf("%s is suspended: %s", account, suspension.Reason)
+	if suspension.ExpiresAt.Valid {
+		message = fmt.Sprintf("%s is suspended until %s: %s",
+			account, suspension.ExpiresAt.Time.Format(TimeLayout), suspension.Reason)
+	}
+	return NewError(rpc.AccountSuspendedCode, message)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
+)
+
+func TestBlog_SuspendAccountAdmin(t *testing.T) {
+	defer cleanUp(t)
+
+	registerAccount(t, leonarda)
+	registerAccount(t, kristie)
+	registerAccount(t, sheldon)
+
+	follow := &types.FollowOperation{Account: kristie, Follow: sheldon}
+	suspend := &types.SuspendAccountAdminOperation{
+		Account:     leonarda,
+		BlogAccount: kristie,
+		Reason:      "spam",
+		Duration:    3600,
+	}
+	lift := &types.LiftSuspensionAdminOperation{Account: leonarda, BlogAccount: kristie}
+
+	t.Run("not_moderator", func(t *testing.T) {
+		cop := *suspend
+		cop.Account = sheldon
+		err := apply(t, handler.SuspendAccountAdmin, &cop)
+		require.NotNil(t, err)
+		require.Equal(t, rpc.AccessDeniedCode, err.Code)
+	})
+
+	t.Run("suspended", func(t *testing.T) {
+		require.Nil(t, apply(t, handler.SuspendAccountAdmin, suspend))
+
+		err := apply(t, handler.Follow, follow)
+		require.NotNil(t, err)
+		require.Equal(t, rpc.AccountSuspendedCode, err.Code)
+
+		profile, err := handler.doGetProfile(kristie)
+		require.Nil(t, err)
+		require.NotNil(t, profile.Suspension)
+		require.Equal(t, "spam", profile.Suspension.Reason)
+		require.Equal(t, leonarda, profile.Suspension.SuspendedBy)
+		require.NotEmpty(t, profile.Suspension.ExpiresAt)
+	})
+
+	t.Run("lifted", func(t *testing.T) {
+		require.Nil(t, apply(t, handler.LiftSuspensionAdmin, lift))
+		require.Nil(t, apply(t, handler.Follow, follow))
+
+		profile, err := handler.doGetProfile(kristie)
+		require.Nil(t, err)
+		require.Nil(t, profile.Suspension)
+
+		err = apply(t, handler.LiftSuspensionAdmin, lift)
+		require.NotNil(t, err)
+		require.Equal(t, rpc.SuspensionNotFoundCode, err.Code)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		require.NoError(t, handler.SuspensionsStorage.Suspend(db.Suspension{
+			Account:     kristie,
+			Reason:      "spam",
+			ExpiresAt:   pq.NullTime{Time: time.Now().UTC().Add(-time.Minute), Valid: true},
+			SuspendedBy: leonarda,
+			CreatedAt:   time.Now().UTC().Add(-time.Hour),
+		}))
+
+		require.Nil(t, apply(t, handler.UpsertDraft, &types.UpsertDraftOperation{
+			Account: kristie,
+			ID:      "id",
+			Title:   "title",
+		}))
+
+		err := apply(t, handler.LiftSuspensionAdmin, lift)
+		require.NotNil(t, err)
+		require.Equal(t, rpc.SuspensionNotFoundCode, err.Code)
+	})
+}
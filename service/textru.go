@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"gitlab.scorum.com/blog/api/common"
+	"gitlab.scorum.com/blog/api/metrics"
 )
 
 const (
@@ -17,6 +18,12 @@ const (
 	retryDelay         = time.Minute
 )
 
+// text.ru api methods of the metrics
+const (
+	textRuSubmitMethod = "submit"
+	textRuResultMethod = "result"
+)
+
 var (
 	errTextRuTextIsTooShort = errors.New("checking text is too short")
 )
@@ -85,6 +92,15 @@ type TextRUClient struct {
 	key string
 }
 
+// postForm posts the form to the text.ru api observing the call time
+func (a *TextRUClient) postForm(method string, form url.Values) (*http.Response, error) {
+	defer func(start time.Time) {
+		metrics.TextRuRequestDuration.WithLabelValues(method).Observe(metrics.Since(start))
+	}(time.Now())
+
+	return a.PostForm(textRUCheckPostUrl, form)
+}
+
 func (a *TextRUClient) submitPostForCheck(text, domainToIgnore string) (string, error) {
 	form := url.Values{}
 	form.Set("text", text)
@@ -97,13 +113,15 @@ func (a *TextRUClient) submitPostForCheck(text, domainToIgnore string) (string,
 	var r startPlagiarismCheckResponse
 	err := common.TryDo(func(attempt int) (retry bool, err error) {
 		retry = true
-		resp, err := a.PostForm(textRUCheckPostUrl, form)
+		resp, err := a.postForm(textRuSubmitMethod, form)
 		if err != nil || resp.StatusCode != http.StatusOK {
+			metrics.TextRuFailures.WithLabelValues(textRuSubmitMethod).Inc()
 			time.Sleep(retryDelay)
 			return
 		}
 
 		if err = json.NewDecoder(resp.Body).Decode(&r); err != nil { // text.ru can return html in case of error instead of json, so we have to retry it
+			metrics.TextRuFailures.WithLabelValues(textRuSubmitMethod).Inc()
 			time.Sleep(retryDelay)
 			return
 		}
@@ -127,25 +145,29 @@ func (a *TextRUClient) checkResults(uid string) (string, error) {
 	var result plagiarismCheckResultResponse
 	err := common.TryDo(func(attempt int) (retry bool, err error) {
 		retry = true
-		resp, err := a.PostForm(textRUCheckPostUrl, form)
+		resp, err := a.postForm(textRuResultMethod, form)
 		if err != nil {
+			metrics.TextRuFailures.WithLabelValues(textRuResultMethod).Inc()
 			time.Sleep(retryDelay)
 			return
 		}
 
 		if resp.StatusCode != http.StatusOK {
 			err = errors.New("status code not OK")
+			metrics.TextRuFailures.WithLabelValues(textRuResultMethod).Inc()
 			time.Sleep(retryDelay)
 			return
 		}
 
 		var temp plagiarismCheckResultResponse
 		if err = json.NewDecoder(resp.Body).Decode(&temp); err != nil {
+			metrics.TextRuFailures.WithLabelValues(textRuResultMethod).Inc()
 			time.Sleep(retryDelay)
 			return
 		}
 
 		if err = temp.checkForErr(); err != nil {
+			metrics.TextRuFailures.WithLabelValues(textRuResultMethod).Inc()
 			time.Sleep(retryDelay)
 			return
 		}
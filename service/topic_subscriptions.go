@@ -0,0 +1,101 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"gitlab.scorum.com/blog/api/broadcast"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/db"
+	"gitlab.scorum.com/blog/api/rpc"
+	. "gitlab.scorum.com/blog/core/domain"
+)
+
+// tagRegexp matches the lowercase tags of the posts json metadata
+var tagRegexp = regexp.MustCompile(`^[\p{Ll}\p{Lo}\p{N}][\p{Ll}\p{Lo}\p{N}_-]*$`)
+
+func (blog *Blog) Subscribe(tx *broadcast.Tx, op types.Operation) *rpc.Error {
+	in := op.(*types.SubscribeOperation)
+
+	kind, topic, err := blog.toTopic(tx.Tx, in.Domain, in.Kind, in.Topic)
+	if err != nil {
+		return err
+	}
+
+	if in.Notify && kind != db.CategoryTopic {
+		return NewError(rpc.InvalidParameterCode, "notifications are available for the categories only")
+	}
+
+	rerr := blog.TopicSubscriptionsStorage.InTx(tx.Tx).Subscribe(db.TopicSubscription{
+		Account:   in.Account,
+		Domain:    in.Domain,
+		Kind:      kind,
+		Topic:     topic,
+		Notify:    in.Notify,
+		CreatedAt: time.Now().UTC(),
+	})
+	if rerr != nil {
+		return WrapError(rpc.InternalErrorCode, rerr)
+	}
+	return nil
+}
+
+func (blog *Blog) Unsubscribe(tx *broadcast.Tx, op types.Operation) *rpc.Error {
+	in := op.(*types.UnsubscribeOperation)
+
+	kind := db.TopicKind(in.Kind)
+	topic := in.Topic
+	if kind == db.TagTopic {
+		topic = strings.ToLower(topic)
+	}
+
+	err := blog.TopicSubscriptionsStorage.InTx(tx.Tx).Unsubscribe(in.Account, in.Domain, kind, topic)
+	if err != nil {
+		return WrapError(rpc.InternalErrorCode, err)
+	}
+	return nil
+}
+
+// toTopic validates the topic, the category must exist in the domain, the tag is lowercased
+func (blog *Blog) toTopic(tx *sqlx.Tx, domain, kind, topic string) (db.TopicKind, string, *rpc.Error) {
+	if !IsValidDomain(domain) {
+		return "", "", NewError(rpc.InvalidParameterCode, fmt.Sprintf("%s is not a valid domain", domain))
+	}
+
+	switch db.TopicKind(kind) {
+	case db.CategoryTopic:
+		var exists bool
+		err := tx.Get(&exists, `SELECT EXISTS(SELECT * FROM categories WHERE domain = $1 AND localization_key = $2)`,
+			domain, topic)
+		if err != nil {
+			return "", "", WrapError(rpc.InternalErrorCode, err)
+		}
+		if !exists {
+			return "", "", NewError(rpc.CategoryNotFoundCode, fmt.Sprintf("%s not found", topic))
+		}
+		return db.CategoryTopic, topic, nil
+	case db.TagTopic:
+		topic = strings.ToLower(topic)
+		if !tagRegexp.MatchString(topic) {
+			return "", "", NewError(rpc.InvalidParameterCode, fmt.Sprintf("%s is not a valid tag", topic))
+		}
+		return db.TagTopic, topic, nil
+	default:
+		return "", "", NewError(rpc.InvalidParameterCode, fmt.Sprintf("%s is not a valid kind", kind))
+	}
+}
+
+// GetSubscriptions returns the categories and the tags the signer subscribed to
+func (blog *Blog) GetSubscriptions(ctx *rpc.Context, account string, _ []*json.RawMessage) {
+	subscriptions, err := blog.TopicSubscriptionsStorage.Get(account)
+	if err != nil {
+		ctx.WriteError(rpc.InternalErrorCode, err.Error())
+		return
+	}
+
+	ctx.WriteResult(toAPITopicSubscriptions(subscriptions))
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"gitlab.scorum.com/blog/api/broadcast/types"
+	"gitlab.scorum.com/blog/api/common"
+	"gitlab.scorum.com/blog/api/push"
+	"gitlab.scorum.com/blog/api/rpc"
+	. "gitlab.scorum.com/blog/core/domain"
+)
+
+func TestBlog_TopicSubscriptions(t *testing.T) {
+	defer cleanUp(t)
+
+	registerAccount(t, leonarda)
+	registerAccount(t, kristie)
+	registerAccount(t, sheldon)
+
+	require.Nil(t, apply(t, handler.AddCategoryAdmin, &types.AddCategoryAdminOperation{
+		Account:         leonarda,
+		Domain:          string(DomainCom),
+		Label:           "football",
+		LocalizationKey: "categories-football",
+	}))
+
+	t.Run("validation", func(t *testing.T) {
+		err := apply(t, handler.Subscribe, &types.SubscribeOperation{
+			Account: kristie, Domain: string(DomainCom), Kind: "category", Topic: "categories-chess"})
+		require.NotNil(t, err)
+		require.Equal(t, rpc.CategoryNotFoundCode, err.Code)
+
+		err = apply(t, handler.Subscribe, &types.SubscribeOperation{
+			Account: kristie, Domain: string(DomainCom), Kind: "author", Topic: sheldon})
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+
+		err = apply(t, handler.Subscribe, &types.SubscribeOperation{
+			Account: kristie, Domain: string(DomainCom), Kind: "tag", Topic: "two words"})
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+
+		err = apply(t, handler.Subscribe, &types.SubscribeOperation{
+			Account: kristie, Domain: string(DomainCom), Kind: "tag", Topic: "chess", Notify: true})
+		require.NotNil(t, err)
+		require.Equal(t, rpc.InvalidParameterCode, err.Code)
+	})
+
+	require.Nil(t, apply(t, handler.Subscribe, &types.SubscribeOperation{
+		Account: kristie, Domain: string(DomainCom), Kind: "category", Topic: "categories-football", Notify: true}))
+	require.Nil(t, apply(t, handler.Subscribe, &types.SubscribeOperation{
+		Account: kristie, Domain: string(DomainCom), Kind: "tag", Topic: "Chess"}))
+
+	subscriptions, err := handler.TopicSubscriptionsStorage.Get(kristie)
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 2)
+
+	football := common.JsonMetadata{Categories: []string{"categories-football"}, Tags: []string{"goal"}}
+	chess := common.JsonMetadata{Categories: []string{"categories-other"}, Tags: []string{"CHESS"}}
+	both := common.JsonMetadata{Categories: []string{"categories-football"}, Tags: []string{"chess"}}
+
+	insertPostWithMetadata(t, leonarda, "football", DomainCom, football)
+	insertPostWithMetadata(t, leonarda, "chess", DomainCom, chess)
+	insertPostWithMetadata(t, sheldon, "both", DomainCom, both)
+	insertPostWithMetadata(t, sheldon, "football-me", DomainMe, football)
+	insertPost(t, sheldon, "other", DomainCom)
+	insertPostWithMetadata(t, kristie, "own", DomainCom, football)
+
+	posts, rerr := handler.doGetPostsFromNetwork(kristie, DomainCom, 0, 100)
+	require.Nil(t, rerr)
+	require.Len(t, posts, 3)
+
+	// the followed author posts are not duplicated
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	notifier := push.NewMockNotifier(mockCtrl)
+	handler.Notifier = notifier
+	notifier.EXPECT().NotifyStartedFollow(sheldon, kristie)
+
+	require.Nil(t, apply(t, handler.Follow, &types.FollowOperation{Account: kristie, Follow: sheldon}))
+
+	posts, rerr = handler.doGetPostsFromNetwork(kristie, DomainCom, 0, 100)
+	require.Nil(t, rerr)
+	require.Len(t, posts, 4)
+
+	require.Nil(t, apply(t, handler.Unsubscribe, &types.UnsubscribeOperation{
+		Account: kristie, Domain: string(DomainCom), Kind: "tag", Topic: "CHESS"}))
+	require.Nil(t, apply(t, handler.Unsubscribe, &types.UnsubscribeOperation{
+		Account: kristie, Domain: string(DomainCom), Kind: "category", Topic: "categories-football"}))
+
+	posts, rerr = handler.doGetPostsFromNetwork(kristie, DomainCom, 0, 100)
+	require.Nil(t, rerr)
+	require.Len(t, posts, 2)
+}
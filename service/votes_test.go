@@ -12,8 +12,8 @@ func TestGetPostVotes(t *testing.T) {
 	testPermlink := "footballsocool"
 	testAccount := "man"
 
-	require.Nil(t, handler.Register(&types.RegisterOperation{testAuthor}))
-	require.Nil(t, handler.Register(&types.RegisterOperation{testAccount}))
+	require.Nil(t, apply(t, handler.Register, &types.RegisterOperation{testAuthor}))
+	require.Nil(t, apply(t, handler.Register, &types.RegisterOperation{testAccount}))
 
 	vote := db.Vote{
 		Account:    testAccount,
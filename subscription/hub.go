@@ -0,0 +1,106 @@
+package subscription
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"gitlab.scorum.com/blog/api/rpc"
+)
+
+// Hub keeps websocket sessions subscribed to the account events
+type Hub struct {
+	mu       sync.RWMutex
+	sessions map[string]map[*rpc.Session]struct{}
+	// accounts are the accounts each session is subscribed to,
+	// a session is kept here until it is closed so its close callback is registered once
+	accounts map[*rpc.Session]map[string]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		sessions: make(map[string]map[*rpc.Session]struct{}),
+		accounts: make(map[*rpc.Session]map[string]struct{}),
+	}
+}
+
+// Subscribe subscribes the session to the account events until the session is closed
+func (h *Hub) Subscribe(account string, session *rpc.Session) {
+	h.mu.Lock()
+	sessions, ok := h.sessions[account]
+	if !ok {
+		sessions = make(map[*rpc.Session]struct{})
+		h.sessions[account] = sessions
+	}
+	sessions[session] = struct{}{}
+
+	accounts, watched := h.accounts[session]
+	if !watched {
+		accounts = make(map[string]struct{})
+		h.accounts[session] = accounts
+	}
+	accounts[account] = struct{}{}
+	h.mu.Unlock()
+
+	if !watched {
+		session.OnClose(func() {
+			h.removeSession(session)
+		})
+	}
+}
+
+// Unsubscribe removes the session from the account subscribers
+func (h *Hub) Unsubscribe(account string, session *rpc.Session) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.unsubscribe(account, session)
+	delete(h.accounts[session], account)
+}
+
+// removeSession unsubscribes the closed session from all the accounts
+func (h *Hub) removeSession(session *rpc.Session) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for account := range h.accounts[session] {
+		h.unsubscribe(account, session)
+	}
+	delete(h.accounts, session)
+}
+
+func (h *Hub) unsubscribe(account string, session *rpc.Session) {
+	sessions, ok := h.sessions[account]
+	if !ok {
+		return
+	}
+
+	delete(sessions, session)
+	if len(sessions) == 0 {
+		delete(h.sessions, account)
+	}
+}
+
+// HasSubscribers checks whether any session is subscribed to the account events
+func (h *Hub) HasSubscribers(account string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.sessions[account]) > 0
+}
+
+// Publish pushes the data to every session subscribed to the account events
+func (h *Hub) Publish(account, subject string, data interface{}) {
+	h.mu.RLock()
+	sessions := make([]*rpc.Session, 0, len(h.sessions[account]))
+	for session := range h.sessions[account] {
+		sessions = append(sessions, session)
+	}
+	h.mu.RUnlock()
+
+	for _, session := range sessions {
+		if err := session.Notify(subject, data); err != nil {
+			log.WithError(err).WithField("account", account).
+				Debug("subscription: failed to notify session")
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package subscription
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.scorum.com/blog/api/rpc"
+	"golang.org/x/net/websocket"
+)
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+
+	router := rpc.NewRouter(nil, nil, 1000, 1)
+	router.Register(rpc.Route{"api", "subscribe"}, func(ctx *rpc.Context) {
+		var account string
+		if err := ctx.Param(0, &account); err != nil {
+			ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+			return
+		}
+		hub.Subscribe(account, ctx.Session)
+		ctx.WriteResult(nil)
+	})
+	router.Register(rpc.Route{"api", "unsubscribe"}, func(ctx *rpc.Context) {
+		var account string
+		if err := ctx.Param(0, &account); err != nil {
+			ctx.WriteError(rpc.InvalidParameterCode, err.Error())
+			return
+		}
+		hub.Unsubscribe(account, ctx.Session)
+		ctx.WriteResult(nil)
+	})
+
+	server := httptest.NewServer(router.HandleWebSocket())
+	defer server.Close()
+
+	conn, err := websocket.Dial(strings.Replace(server.URL, "http", "ws", 1), "", server.URL)
+	require.NoError(t, err)
+
+	require.NoError(t, websocket.Message.Send(conn,
+		`{"id": 1, "method": "call", "params": ["api", "subscribe", ["leonarda"]]}`))
+
+	var resp map[string]interface{}
+	require.NoError(t, websocket.JSON.Receive(conn, &resp))
+	require.Nil(t, resp["error"])
+
+	require.True(t, hub.HasSubscribers("leonarda"))
+	require.False(t, hub.HasSubscribers("kristie"))
+
+	t.Run("publish", func(t *testing.T) {
+		hub.Publish("leonarda", "notification", "data")
+
+		var notice rpc.Notice
+		require.NoError(t, websocket.JSON.Receive(conn, &notice))
+		require.Equal(t, []interface{}{"notification", "data"}, notice.Params)
+	})
+
+	t.Run("resubscribe", func(t *testing.T) {
+		for _, method := range []string{"unsubscribe", "subscribe", "unsubscribe", "subscribe"} {
+			require.NoError(t, websocket.Message.Send(conn,
+				`{"id": 2, "method": "call", "params": ["api", "`+method+`", ["leonarda"]]}`))
+			require.NoError(t, websocket.JSON.Receive(conn, &resp))
+			require.Nil(t, resp["error"])
+		}
+
+		require.True(t, hub.HasSubscribers("leonarda"))
+		require.Len(t, hub.accounts, 1)
+	})
+
+	t.Run("unsubscribe_on_close", func(t *testing.T) {
+		require.NoError(t, conn.Close())
+
+		// session is closed by the server asynchronously
+		for i := 0; i < 100 && hub.HasSubscribers("leonarda"); i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		require.False(t, hub.HasSubscribers("leonarda"))
+
+		hub.mu.RLock()
+		defer hub.mu.RUnlock()
+		require.Empty(t, hub.accounts)
+	})
+}
@@ -22,12 +22,8 @@ func init() {
 
 // VerifyAny checks whether the given signatures is signed with any of the given public keys
 func VerifyAny(pubKeys [][]byte, signature string, digest []byte) (bool, error) {
-	if len(signature) != 130 {
-		return false, nil
-	}
-
-	pubKeyFound, err := extractPublicKeys(signature, digest)
-	if err != nil {
+	pubKeyFound, err := RecoverPublicKey(signature, digest)
+	if err != nil || pubKeyFound == nil {
 		return false, err
 	}
 
@@ -39,6 +35,20 @@ func VerifyAny(pubKeys [][]byte, signature string, digest []byte) (bool, error)
 	return false, nil
 }
 
+// RecoverPublicKey returns the compressed public key the signature is signed with,
+// nil is returned for the invalid signature
+func RecoverPublicKey(signature string, digest []byte) ([]byte, error) {
+	if len(signature) != 130 {
+		return nil, nil
+	}
+
+	pubKey, err := extractPublicKeys(signature, digest)
+	if err != nil || len(pubKey) == 0 {
+		return nil, err
+	}
+	return pubKey, nil
+}
+
 func extractPublicKeys(signature string, digest []byte) ([]byte, error) {
 	cDigest := C.CBytes(digest)
 	defer C.free(cDigest)